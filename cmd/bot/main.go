@@ -2,14 +2,20 @@ package main
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // Time zones for /tz even where the system has no tzdata
 
 	"telegram-bot-assistente/config"
 	"telegram-bot-assistente/internal/handlers"
+	"telegram-bot-assistente/internal/limiter"
+	"telegram-bot-assistente/internal/llm"
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/reminder"
 	"telegram-bot-assistente/internal/repository"
 
 	"gopkg.in/telebot.v3"
@@ -28,8 +34,37 @@ func main() {
 	}
 	defer db.Close()
 
-	// Create task repository
+	// Create repositories
 	taskRepo := repository.NewTaskRepository(db)
+	discussionRepo := repository.NewDiscussionRepository(db)
+	userRepo := repository.NewUserRepository(db)
+	reminderRepo := repository.NewReminderRepository(db)
+	limitStore := limiter.NewSQLStore(db, cfg.QuotaPolicy)
+
+	// Configured administrators get the admin quota tier; those who have
+	// not written to the bot yet get it on their first message
+	for _, adminID := range cfg.AdminIDs {
+		if _, err := userRepo.GetUser(context.Background(), int(adminID)); errors.Is(err, repository.ErrUserNotFound) {
+			continue
+		} else if err != nil {
+			log.Fatalf("Failed to load admin %d: %v", adminID, err)
+		}
+		if _, err := limitStore.SetTier(context.Background(), adminID, models.TierAdmin); err != nil {
+			log.Fatalf("Failed to set admin tier for user %d: %v", adminID, err)
+		}
+	}
+
+	// Create LLM client
+	llmClient, err := llm.NewMiniMaxClient(llm.MiniMaxConfig{
+		APIKey:     cfg.MiniMaxAPIKey,
+		BaseURL:    cfg.MiniMaxBaseURL,
+		Model:      cfg.MiniMaxModel,
+		Timeout:    cfg.LLMTimeout,
+		MaxRetries: cfg.LLMMaxRetries,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create LLM client: %v", err)
+	}
 
 	bot, err := telebot.NewBot(telebot.Settings{
 		Token:  cfg.TelegramBotToken,
@@ -41,11 +76,18 @@ func main() {
 
 	log.Printf("Authorized as @%s", bot.Me.Username)
 
-	setupHandlers(bot, taskRepo)
+	setupHandlers(bot, db, taskRepo, discussionRepo, userRepo, reminderRepo, llmClient, limitStore, cfg)
 
-	_, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	scheduler := reminder.NewScheduler(reminderRepo, bot,
+		reminder.WithInterval(cfg.ReminderInterval),
+		reminder.WithMarkup(handlers.SnoozeKeyboard),
+		reminder.WithTrashPurge(taskRepo, cfg.TrashRetention),
+	)
+	scheduler.Start(ctx)
+
 	go func() {
 		log.Println("Bot started and ready...")
 		bot.Start()
@@ -53,13 +95,22 @@ func main() {
 
 	waitForShutdown(func() {
 		cancel()
+		scheduler.Stop()
 		bot.Stop()
 	})
 	log.Println("Bot stopped")
 }
 
-func setupHandlers(bot *telebot.Bot, taskRepo repository.TaskRepository) {
-	h := handlers.NewHandlers(taskRepo)
+func setupHandlers(bot *telebot.Bot, db *repository.Database, taskRepo repository.TaskRepository, discussionRepo repository.DiscussionRepository, userRepo repository.UserRepository, reminderRepo repository.ReminderRepository, llmClient llm.Client, limits limiter.Limiter, cfg *config.Config) {
+	h := handlers.NewHandlers(taskRepo, discussionRepo,
+		handlers.WithLLMClient(llmClient),
+		handlers.WithLimiter(limits),
+		handlers.WithStats(db),
+		handlers.WithAdmins(cfg.AdminIDs...),
+		handlers.WithUserRepository(userRepo),
+		handlers.WithReminderSettings(reminderRepo),
+		handlers.WithTrashRetention(cfg.TrashRetention),
+	)
 	h.RegisterRoutes(bot)
 }
 
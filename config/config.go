@@ -3,6 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
 
 	"github.com/joho/godotenv"
 )
@@ -10,6 +15,14 @@ import (
 type Config struct {
 	TelegramBotToken string
 	MiniMaxAPIKey    string
+	MiniMaxBaseURL   string
+	MiniMaxModel     string
+	LLMTimeout       time.Duration
+	LLMMaxRetries    int
+	QuotaPolicy      models.QuotaPolicy
+	AdminIDs         []int64
+	ReminderInterval time.Duration
+	TrashRetention   time.Duration // How long deleted tasks stay in the trash before they are purged
 	DatabaseURL      string
 	LogLevel         string
 	ServerPort       string
@@ -18,9 +31,47 @@ type Config struct {
 func Load() (*Config, error) {
 	_ = godotenv.Load()
 
+	llmTimeout, err := getEnvDuration("LLM_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	llmMaxRetries, err := getEnvInt("LLM_MAX_RETRIES", 2)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	quotaPolicy, err := loadQuotaPolicy()
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	adminIDs, err := getEnvIDs("ADMIN_IDS")
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	reminderInterval, err := getEnvDuration("REMINDER_INTERVAL", time.Minute)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	trashRetentionDays, err := getEnvInt("TRASH_RETENTION_DAYS", 30)
+	if err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+
 	config := &Config{
 		TelegramBotToken: getEnv("TELEGRAM_BOT_TOKEN", ""),
 		MiniMaxAPIKey:    getEnv("MINIMAX_API_KEY", ""),
+		MiniMaxBaseURL:   getEnv("MINIMAX_BASE_URL", ""),
+		MiniMaxModel:     getEnv("MINIMAX_MODEL", ""),
+		LLMTimeout:       llmTimeout,
+		LLMMaxRetries:    llmMaxRetries,
+		QuotaPolicy:      quotaPolicy,
+		AdminIDs:         adminIDs,
+		ReminderInterval: reminderInterval,
+		TrashRetention:   time.Duration(trashRetentionDays) * 24 * time.Hour,
 		DatabaseURL:      getEnv("DATABASE_URL", "./bot.db"),
 		LogLevel:         getEnv("LOG_LEVEL", "info"),
 		ServerPort:       getEnv("SERVER_PORT", "8080"),
@@ -40,6 +91,98 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	return parsed, nil
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be a duration like 30s: %w", key, err)
+	}
+	return parsed, nil
+}
+
+// getEnvIDs parses a comma-separated list of Telegram user IDs
+func getEnvIDs(key string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("%s must be a comma-separated list of user IDs, got %q", key, part)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// loadQuotaPolicy reads the LLM quota policy. QUOTA_PERIOD, QUOTA_LIMIT and
+// QUOTA_WINDOW set the default rule, QUOTA_<TIER>_PERIOD, QUOTA_<TIER>_LIMIT and
+// QUOTA_<TIER>_WINDOW override it for the free, premium and admin tiers.
+// A negative limit means unlimited.
+func loadQuotaPolicy() (models.QuotaPolicy, error) {
+	policy := models.DefaultQuotaPolicy()
+
+	defaultRule, err := loadQuotaRule("QUOTA", policy.Default)
+	if err != nil {
+		return policy, err
+	}
+	policy.Default = defaultRule
+
+	for _, tier := range []string{models.TierFree, models.TierPremium, models.TierAdmin} {
+		base := defaultRule
+		if builtin, ok := policy.Tiers[tier]; ok {
+			// Built-in tier rules only change the limit, the period follows the default rule
+			base.Limit = builtin.Limit
+		}
+
+		rule, err := loadQuotaRule("QUOTA_"+strings.ToUpper(tier), base)
+		if err != nil {
+			return policy, err
+		}
+		policy.Tiers[tier] = rule
+	}
+
+	return policy, nil
+}
+
+func loadQuotaRule(prefix string, base models.QuotaRule) (models.QuotaRule, error) {
+	limit, err := getEnvInt(prefix+"_LIMIT", base.Limit)
+	if err != nil {
+		return base, err
+	}
+
+	window, err := getEnvDuration(prefix+"_WINDOW", base.Window)
+	if err != nil {
+		return base, err
+	}
+
+	return models.QuotaRule{
+		Period: strings.ToLower(getEnv(prefix+"_PERIOD", base.Period)),
+		Limit:  limit,
+		Window: window,
+	}, nil
+}
+
 func validateConfig(config *Config) error {
 	if config.TelegramBotToken == "" {
 		return fmt.Errorf("TELEGRAM_BOT_TOKEN is required")
@@ -53,6 +196,26 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
 
+	if config.LLMTimeout <= 0 {
+		return fmt.Errorf("LLM_TIMEOUT must be positive")
+	}
+
+	if config.LLMMaxRetries < 0 {
+		return fmt.Errorf("LLM_MAX_RETRIES cannot be negative")
+	}
+
+	if config.ReminderInterval <= 0 {
+		return fmt.Errorf("REMINDER_INTERVAL must be positive")
+	}
+
+	if config.TrashRetention <= 0 {
+		return fmt.Errorf("TRASH_RETENTION_DAYS must be positive")
+	}
+
+	if err := config.QuotaPolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid quota policy: %w", err)
+	}
+
 	return nil
 }
 
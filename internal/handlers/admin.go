@@ -0,0 +1,275 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+
+	"gopkg.in/telebot.v3"
+)
+
+// StatsProvider предоставляет статистику использования бота
+type StatsProvider interface {
+	GetStats() (map[string]int, error)
+}
+
+// WithAdmins задает Telegram ID администраторов бота
+func WithAdmins(ids ...int64) Option {
+	return func(h *Handlers) {
+		for _, id := range ids {
+			h.admins[id] = true
+		}
+	}
+}
+
+// WithStats подключает источник статистики для /admin stats
+func WithStats(stats StatsProvider) Option {
+	return func(h *Handlers) {
+		h.stats = stats
+	}
+}
+
+const adminUsage = `🛡 Команды администратора:
+/admin premium <user_id|@username> on|off - выдать или снять премиум
+/admin quota <user_id|@username> reset - сбросить лимит запросов к ИИ
+/admin stats - статистика бота
+/admin user <user_id|@username> - информация о пользователе`
+
+// isAdmin проверяет, является ли пользователь администратором
+func (h *Handlers) isAdmin(userID int64) bool {
+	return h.admins[userID]
+}
+
+// handleAdmin обрабатывает команду /admin
+func (h *Handlers) handleAdmin(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		if !h.isAdmin(userID) {
+			h.logUserAction(userID, "admin_denied", c.Text())
+			return c.Send("🚫 Команда доступна только администраторам")
+		}
+
+		args := strings.Fields(c.Message().Payload)
+		if len(args) == 0 {
+			return c.Send(adminUsage)
+		}
+
+		switch args[0] {
+		case "premium":
+			return h.handleAdminPremium(c, userID, args[1:])
+		case "quota":
+			return h.handleAdminQuota(c, userID, args[1:])
+		case "stats":
+			return h.handleAdminStats(c, userID)
+		case "user":
+			return h.handleAdminUser(c, userID, args[1:])
+		default:
+			return c.Send("❓ Неизвестная команда\n\n" + adminUsage)
+		}
+	})
+}
+
+// handleAdminPremium выдает или снимает премиум-тариф
+func (h *Handlers) handleAdminPremium(c telebot.Context, adminID int64, args []string) error {
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") {
+		return c.Send("❌ Использование: /admin premium <user_id|@username> on|off")
+	}
+
+	target, err := h.resolveAdminUser(requestContext(c), args[0])
+	if err != nil {
+		return h.sendAdminUserError(c, adminID, args[0], err)
+	}
+	targetID := int64(target.ID)
+
+	if h.limiter == nil {
+		return c.Send("ℹ️ Лимиты запросов к ИИ не настроены")
+	}
+
+	// Тариф администраторов из конфигурации задается конфигурацией, а не командой
+	if h.isAdmin(targetID) {
+		return c.Send(fmt.Sprintf("ℹ️ Пользователь %d - администратор, его тариф не меняется", targetID))
+	}
+
+	tier := models.TierFree
+	if args[1] == "on" {
+		tier = models.TierPremium
+	}
+
+	if _, err := h.limiter.SetTier(requestContext(c), targetID, tier); err != nil {
+		h.logAdminAction(adminID, "premium_error", fmt.Sprintf("User: %d, Error: %v", targetID, err))
+		return c.Send("❌ Не удалось изменить тариф. Попробуйте позже.")
+	}
+
+	h.logAdminAction(adminID, "premium", fmt.Sprintf("User: %d, Tier: %s", targetID, tier))
+
+	if tier == models.TierPremium {
+		return c.Send(fmt.Sprintf("⭐ Пользователю %d выдан премиум", targetID))
+	}
+	return c.Send(fmt.Sprintf("✅ Премиум пользователя %d отключен", targetID))
+}
+
+// handleAdminQuota сбрасывает счетчик запросов пользователя
+func (h *Handlers) handleAdminQuota(c telebot.Context, adminID int64, args []string) error {
+	if len(args) != 2 || args[1] != "reset" {
+		return c.Send("❌ Использование: /admin quota <user_id|@username> reset")
+	}
+
+	target, err := h.resolveAdminUser(requestContext(c), args[0])
+	if err != nil {
+		return h.sendAdminUserError(c, adminID, args[0], err)
+	}
+	targetID := int64(target.ID)
+
+	if h.limiter == nil {
+		return c.Send("ℹ️ Лимиты запросов к ИИ не настроены")
+	}
+
+	limit, err := h.limiter.Reset(requestContext(c), targetID)
+	if err != nil {
+		h.logAdminAction(adminID, "quota_reset_error", fmt.Sprintf("User: %d, Error: %v", targetID, err))
+		return c.Send("❌ Не удалось сбросить лимит. Попробуйте позже.")
+	}
+
+	h.logAdminAction(adminID, "quota_reset", fmt.Sprintf("User: %d", targetID))
+
+	lines := append([]string{fmt.Sprintf("🔄 Лимит пользователя %d сброшен", targetID), ""},
+		formatQuota(limit, h.limiter.Policy(), h.userLocation(requestContext(c), adminID))...)
+	return c.Send(strings.Join(lines, "\n"))
+}
+
+// handleAdminStats показывает статистику бота
+func (h *Handlers) handleAdminStats(c telebot.Context, adminID int64) error {
+	if h.stats == nil {
+		return c.Send("ℹ️ Статистика недоступна")
+	}
+
+	stats, err := h.stats.GetStats()
+	if err != nil {
+		h.logAdminAction(adminID, "stats_error", fmt.Sprintf("Error: %v", err))
+		return c.Send("❌ Не удалось загрузить статистику. Попробуйте позже.")
+	}
+
+	h.logAdminAction(adminID, "stats", "")
+
+	return c.Send(strings.Join([]string{
+		"📈 Статистика бота",
+		"",
+		fmt.Sprintf("📝 Задач: %d", stats["tasks"]),
+		fmt.Sprintf("🟢 Активных задач: %d", stats["active_tasks"]),
+		fmt.Sprintf("💬 Обсуждений: %d", stats["discussions"]),
+		fmt.Sprintf("👥 Пользователей: %d", stats["users"]),
+	}, "\n"))
+}
+
+// handleAdminUser показывает информацию о пользователе
+func (h *Handlers) handleAdminUser(c telebot.Context, adminID int64, args []string) error {
+	ctx := requestContext(c)
+
+	if len(args) != 1 {
+		return c.Send("❌ Использование: /admin user <user_id|@username>")
+	}
+
+	target, err := h.resolveAdminUser(requestContext(c), args[0])
+	if err != nil {
+		return h.sendAdminUserError(c, adminID, args[0], err)
+	}
+	targetID := int64(target.ID)
+
+	tasks, err := h.repository.GetTasksByUser(ctx, int(targetID))
+	if err != nil {
+		h.logAdminAction(adminID, "user_error", fmt.Sprintf("User: %d, Error: %v", targetID, err))
+		return c.Send("❌ Не удалось загрузить данные пользователя. Попробуйте позже.")
+	}
+
+	counts := make(map[string]int)
+	for _, task := range tasks {
+		counts[task.Status]++
+	}
+
+	title := fmt.Sprintf("👤 Пользователь %d", targetID)
+	if name := target.GetDisplayName(); name != "" {
+		title += " - " + name
+	}
+
+	lines := []string{
+		title,
+		"",
+		fmt.Sprintf("📝 Задач: %d (активных %d, выполненных %d, отложенных %d)",
+			len(tasks), counts[models.StatusActive], counts[models.StatusDone], counts[models.StatusPostponed]),
+	}
+
+	if h.limiter != nil {
+		limit, err := h.limiter.Status(requestContext(c), targetID)
+		if err != nil {
+			h.logAdminAction(adminID, "user_error", fmt.Sprintf("User: %d, Error: %v", targetID, err))
+			return c.Send("❌ Не удалось загрузить лимиты пользователя. Попробуйте позже.")
+		}
+		lines = append(lines, "")
+		lines = append(lines, formatQuota(limit, h.limiter.Policy(), h.userLocation(requestContext(c), adminID))...)
+	}
+
+	h.logAdminAction(adminID, "user", fmt.Sprintf("User: %d", targetID))
+
+	return c.Send(strings.Join(lines, "\n"))
+}
+
+// resolveAdminUser находит пользователя по Telegram ID или @username.
+// Без реестра пользователей принимаются только числовые ID.
+func (h *Handlers) resolveAdminUser(ctx context.Context, arg string) (*models.User, error) {
+	if strings.HasPrefix(arg, "@") {
+		if h.users == nil {
+			return nil, fmt.Errorf("%w: %s", repository.ErrUserNotFound, arg)
+		}
+		return h.users.GetUserByUsername(ctx, arg)
+	}
+
+	id, err := parseAdminUserID(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.users == nil {
+		return &models.User{ID: int(id)}, nil
+	}
+	return h.users.GetUser(ctx, int(id))
+}
+
+// sendAdminUserError сообщает администратору, почему пользователь не найден
+func (h *Handlers) sendAdminUserError(c telebot.Context, adminID int64, arg string, err error) error {
+	switch {
+	case errors.Is(err, repository.ErrUserNotFound):
+		return c.Send(fmt.Sprintf("❌ Пользователь %s не найден. Он должен хотя бы раз написать боту.", arg))
+	case errors.Is(err, errInvalidAdminUserID):
+		return c.Send(fmt.Sprintf("❌ %s", err.Error()))
+	default:
+		h.logAdminAction(adminID, "user_lookup_error", fmt.Sprintf("User: %s, Error: %v", arg, err))
+		return c.Send("❌ Не удалось найти пользователя. Попробуйте позже.")
+	}
+}
+
+// errInvalidAdminUserID возвращается для аргумента, который не является Telegram ID
+var errInvalidAdminUserID = errors.New("некорректный ID пользователя")
+
+// parseAdminUserID разбирает Telegram ID пользователя из аргумента команды
+func parseAdminUserID(arg string) (int64, error) {
+	id, err := strconv.ParseInt(arg, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("%w: %s", errInvalidAdminUserID, arg)
+	}
+	return id, nil
+}
+
+// logAdminAction логирует действие администратора
+func (h *Handlers) logAdminAction(adminID int64, action string, details string) {
+	log.Printf("Admin %d: %s - %s", adminID, action, details)
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockStats returns fixed statistics
+type mockStats struct {
+	stats map[string]int
+	err   error
+}
+
+func (m *mockStats) GetStats() (map[string]int, error) {
+	return m.stats, m.err
+}
+
+func TestHandleAdmin(t *testing.T) {
+	const adminID = 100
+
+	setup := func() (*Handlers, *mockTaskRepository, *mockLimiter) {
+		repo := newMockTaskRepository()
+		limits := newMockLimiter()
+		h := NewHandlers(repo, &mockDiscussionRepository{},
+			WithLimiter(limits),
+			WithAdmins(adminID),
+			WithStats(&mockStats{stats: map[string]int{"tasks": 7, "active_tasks": 4, "discussions": 2, "users": 3}}),
+		)
+		return h, repo, limits
+	}
+
+	t.Run("non-admin is rejected", func(t *testing.T) {
+		h, _, limits := setup()
+
+		c := newCommandContext(1, "/admin premium 1 on", "premium 1 on")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "только администраторам")
+		assert.Empty(t, limits.limits)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		h, _, _ := setup()
+
+		c := newCommandContext(adminID, "/admin", "")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "/admin premium")
+	})
+
+	t.Run("premium on and off", func(t *testing.T) {
+		h, _, limits := setup()
+
+		c := newCommandContext(adminID, "/admin premium 42 on", "premium 42 on")
+		require.NoError(t, h.handleAdmin(c))
+		assert.Contains(t, c.lastSent(), "выдан премиум")
+		assert.Equal(t, models.TierPremium, limits.get(42).Tier)
+
+		c = newCommandContext(adminID, "/admin premium 42 off", "premium 42 off")
+		require.NoError(t, h.handleAdmin(c))
+		assert.Contains(t, c.lastSent(), "отключен")
+		assert.Equal(t, models.TierFree, limits.get(42).Tier)
+	})
+
+	t.Run("premium keeps the tier of configured admins", func(t *testing.T) {
+		h, _, limits := setup()
+		limits.get(adminID).Tier = models.TierAdmin
+
+		for _, payload := range []string{"premium 100 off", "premium 100 on"} {
+			c := newCommandContext(adminID, "/admin "+payload, payload)
+			require.NoError(t, h.handleAdmin(c))
+			assert.Contains(t, c.lastSent(), "администратор, его тариф не меняется", payload)
+			assert.Equal(t, models.TierAdmin, limits.get(adminID).Tier, payload)
+		}
+	})
+
+	t.Run("premium with invalid arguments", func(t *testing.T) {
+		h, _, _ := setup()
+
+		c := newCommandContext(adminID, "/admin premium abc on", "premium abc on")
+		require.NoError(t, h.handleAdmin(c))
+		assert.Contains(t, c.lastSent(), "некорректный ID")
+
+		c = newCommandContext(adminID, "/admin premium 42 maybe", "premium 42 maybe")
+		require.NoError(t, h.handleAdmin(c))
+		assert.Contains(t, c.lastSent(), "Использование")
+	})
+
+	t.Run("quota reset", func(t *testing.T) {
+		h, _, limits := setup()
+		limits.get(42).RequestsCount = 10
+
+		c := newCommandContext(adminID, "/admin quota 42 reset", "quota 42 reset")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "Лимит пользователя 42 сброшен")
+		assert.Equal(t, 0, limits.get(42).RequestsCount)
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		h, _, _ := setup()
+
+		c := newCommandContext(adminID, "/admin stats", "stats")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "📝 Задач: 7")
+		assert.Contains(t, c.lastSent(), "🟢 Активных задач: 4")
+		assert.Contains(t, c.lastSent(), "👥 Пользователей: 3")
+	})
+
+	t.Run("stats error", func(t *testing.T) {
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{},
+			WithAdmins(adminID),
+			WithStats(&mockStats{err: errors.New("db is down")}),
+		)
+
+		c := newCommandContext(adminID, "/admin stats", "stats")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "Не удалось загрузить статистику")
+	})
+
+	t.Run("user info", func(t *testing.T) {
+		h, repo, limits := setup()
+		addMockTasks(t, repo, 42, 2, models.StatusActive)
+		addMockTasks(t, repo, 42, 1, models.StatusDone)
+		limits.get(42).RequestsCount = 4
+
+		c := newCommandContext(adminID, "/admin user 42", "user 42")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "👤 Пользователь 42")
+		assert.Contains(t, c.lastSent(), "Задач: 3 (активных 2, выполненных 1, отложенных 0)")
+		assert.Contains(t, c.lastSent(), "Использовано: 4 из 10")
+	})
+
+	t.Run("unknown subcommand", func(t *testing.T) {
+		h, _, _ := setup()
+
+		c := newCommandContext(adminID, "/admin drop", "drop")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "Неизвестная команда")
+	})
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Действия inline-кнопок
+const (
+	callbackList     = "list"
+	callbackUndoDone = "undo"
+	callbackAttach   = "attach"
+	callbackRewrite  = "rewrite"
+	callbackDate     = "date"
+	callbackSnooze   = "snooze"
+	callbackTrash    = "trash"
+)
+
+// callbackSeparator разделяет действие и аргументы в данных кнопки
+const callbackSeparator = "|"
+
+// callbackData формирует данные inline-кнопки из действия и аргументов
+func callbackData(action string, args ...string) string {
+	return strings.Join(append([]string{action}, args...), callbackSeparator)
+}
+
+// parseCallbackData разбирает данные inline-кнопки на действие и аргументы
+func parseCallbackData(data string) (string, []string) {
+	data = strings.TrimSpace(data)
+	if data == "" {
+		return "", nil
+	}
+
+	parts := strings.Split(data, callbackSeparator)
+	return parts[0], parts[1:]
+}
+
+// inlineButton создает inline-кнопку с данными для handleCallback
+func inlineButton(text, action string, args ...string) telebot.InlineButton {
+	return telebot.InlineButton{
+		Text: text,
+		Data: callbackData(action, args...),
+	}
+}
+
+// inlineKeyboard создает разметку из рядов inline-кнопок, пропуская пустые ряды
+func inlineKeyboard(rows ...[]telebot.InlineButton) *telebot.ReplyMarkup {
+	keyboard := make([][]telebot.InlineButton, 0, len(rows))
+	for _, row := range rows {
+		if len(row) > 0 {
+			keyboard = append(keyboard, row)
+		}
+	}
+
+	return &telebot.ReplyMarkup{InlineKeyboard: keyboard}
+}
+
+// editCallbackMessage редактирует сообщение с нажатой кнопкой, игнорируя отсутствие изменений
+func editCallbackMessage(c telebot.Context, what interface{}, opts ...interface{}) error {
+	err := c.Edit(what, opts...)
+	if errors.Is(err, telebot.ErrSameMessageContent) || errors.Is(err, telebot.ErrMessageNotModified) {
+		return nil
+	}
+	return err
+}
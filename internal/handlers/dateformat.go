@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// dateChoiceTTL - время, в течение которого можно выбрать дату из предложенных
+const dateChoiceTTL = 10 * time.Minute
+
+const dateOrderUsage = `Использование:
+/dateformat - текущий формат дат
+/dateformat dmy - день.месяц.год (03/04 - 3 апреля)
+/dateformat mdy - месяц/день/год (03/04 - 4 марта)
+/dateformat ymd - год-месяц-день, для остальных дат бот переспросит
+/dateformat - - не выбран: 03.04 - 3 апреля, а при 03/04 бот переспросит`
+
+// pendingDate - команда со сроком, который можно понять по-разному
+type pendingDate struct {
+	userID  int64
+	command string
+	field   string // Поле команды с датой: «срок» или «до»
+	options []time.Time
+	hasTime bool
+	// retry повторяет команду с уточненным сроком
+	retry func(c telebot.Context, userID int64, text string) error
+}
+
+// askDate предлагает выбрать одну из возможных дат вместо того, чтобы угадывать.
+// Выбранная дата подставляется в поле field команды.
+func (h *Handlers) askDate(c telebot.Context, userID int64, command, field string, retry func(telebot.Context, int64, string) error, ambiguous *utils.AmbiguousDateError) error {
+	key := h.dates.Put(pendingDate{
+		userID:  userID,
+		command: command,
+		field:   field,
+		options: ambiguous.Options,
+		hasTime: ambiguous.HasTime,
+		retry:   retry,
+	})
+
+	rows := make([][]telebot.InlineButton, 0, len(ambiguous.Options)+1)
+	for i, option := range ambiguous.Options {
+		rows = append(rows, []telebot.InlineButton{
+			inlineButton(utils.FormatDateWords(option, ambiguous.HasTime), callbackDate, key, strconv.Itoa(i)),
+		})
+	}
+	rows = append(rows, []telebot.InlineButton{
+		inlineButton("✖️ Отмена", callbackDate, key, "-"),
+	})
+
+	h.logUserAction(userID, "ambiguous_date", ambiguous.Error())
+
+	text := fmt.Sprintf("🤔 Дату «%s» можно понять по-разному. Что вы имели в виду?", ambiguous.Input)
+	if strings.Contains(ambiguous.Input, "/") || strings.Contains(ambiguous.Input, "-") {
+		text += "\n\nЧтобы бот не переспрашивал, выберите формат дат: /dateformat dmy или /dateformat mdy"
+	}
+
+	return c.Send(text, inlineKeyboard(rows...))
+}
+
+// handleDateCallback повторяет команду с выбранной датой
+func (h *Handlers) handleDateCallback(c telebot.Context, args []string) error {
+	if len(args) != 2 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	userID := h.getUserID(c)
+	owner, ok := h.dates.Get(args[0])
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "⌛ Время выбора истекло, отправьте команду еще раз"})
+	}
+	if owner.userID != userID {
+		return c.Respond(&telebot.CallbackResponse{Text: "🚫 Это не ваша команда"})
+	}
+
+	index := -1
+	if args[1] != "-" {
+		var err error
+		index, err = strconv.Atoi(args[1])
+		if err != nil || index < 0 || index >= len(owner.options) {
+			return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+		}
+	}
+
+	// Команду выполняет только тот обработчик, который забрал запись
+	pending, ok := h.dates.Take(args[0])
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "⌛ Время выбора истекло, отправьте команду еще раз"})
+	}
+
+	if index < 0 {
+		if err := editCallbackMessage(c, "✖️ Команда отменена"); err != nil {
+			return err
+		}
+		return c.Respond()
+	}
+
+	option := pending.options[index]
+	if err := editCallbackMessage(c, "📅 Дата: "+utils.FormatDateWords(option, pending.hasTime)); err != nil {
+		return err
+	}
+	if err := c.Respond(); err != nil {
+		return err
+	}
+
+	// Дата в формате ISO читается однозначно при любом формате дат пользователя
+	date := option.Format("2006-01-02")
+	if pending.hasTime {
+		date = option.Format("2006-01-02T15:04")
+	}
+
+	return pending.retry(c, userID, utils.SetField(pending.command, pending.field, date))
+}
+
+// handleDateFormat обрабатывает команду /dateformat
+func (h *Handlers) handleDateFormat(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		if h.users == nil {
+			return c.Send("ℹ️ Формат дат не настраивается")
+		}
+
+		args := strings.Fields(c.Message().Payload)
+		if len(args) == 0 {
+			return c.Send(formatDateOrder(h.userSettings(requestContext(c), userID).DateOrder) + "\n\n" + dateOrderUsage)
+		}
+
+		order := strings.ToLower(args[0])
+		if order == "-" {
+			order = ""
+		} else if !models.IsValidDateOrder(order) {
+			return c.Send(fmt.Sprintf("❌ Неизвестный формат дат «%s»\n\n%s", args[0], dateOrderUsage))
+		}
+
+		if err := h.users.SetDateOrder(requestContext(c), int(userID), order); err != nil {
+			h.logUserAction(userID, "dateformat_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось сохранить формат дат. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "dateformat", args[0])
+		return c.Send("✅ Формат дат сохранен\n\n" + formatDateOrder(order))
+	})
+}
+
+// formatDateOrder описывает, как читаются числовые даты пользователя
+func formatDateOrder(order string) string {
+	switch order {
+	case models.DateOrderDMY:
+		return "📅 Формат дат: день.месяц.год, 03/04/2025 - 3 апреля"
+	case models.DateOrderMDY:
+		return "📅 Формат дат: месяц/день/год, 03/04/2025 - 4 марта"
+	case models.DateOrderYMD:
+		return "📅 Формат дат: год-месяц-день, 2025-04-03 - 3 апреля"
+	default:
+		return "📅 Формат дат не выбран: 03.04.2025 - 3 апреля, а если дату вида 03/04/2025 можно понять по-разному, бот переспросит"
+	}
+}
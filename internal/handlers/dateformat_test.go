@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAmbiguousDate(t *testing.T) {
+	setup := func() (*Handlers, *mockTaskRepository, *mockUserRepository) {
+		repo := newMockTaskRepository()
+		users := newMockUserRepository(&models.User{ID: 1, FirstName: "Alice"})
+		return NewHandlers(repo, &mockDiscussionRepository{}, WithUserRepository(users)), repo, users
+	}
+
+	t.Run("add asks which date was meant", func(t *testing.T) {
+		h, repo, _ := setup()
+
+		c := newCommandContext(1, "/add Report срок: 03/04/2025", "")
+		require.NoError(t, h.handleAdd(c))
+
+		assert.Empty(t, repo.tasks)
+		assert.Contains(t, c.lastSent(), "можно понять по-разному")
+		assert.Contains(t, c.lastSent(), "/dateformat")
+
+		keyboard := c.lastMarkup().InlineKeyboard
+		require.Len(t, keyboard, 3)
+		assert.Equal(t, "чт, 3 апреля 2025", keyboard[0][0].Text)
+		assert.Equal(t, "вт, 4 марта 2025", keyboard[1][0].Text)
+
+		cb := newCallbackContext(1, keyboard[1][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		require.Len(t, repo.tasks, 1)
+		task := repo.tasks[1]
+		assert.Equal(t, "Report", task.OriginalDescription)
+		assert.Equal(t, time.Date(2025, 3, 4, 23, 59, 59, 0, time.Local), task.Deadline)
+		assert.False(t, task.DeadlineHasTime)
+		assert.Contains(t, cb.edited[0], "4 марта 2025")
+		assert.Contains(t, cb.lastSent(), "Срок: 04.03.2025")
+
+		// The choice is used once
+		cb = newCallbackContext(1, keyboard[0][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+		assert.Len(t, repo.tasks, 1)
+		assert.Contains(t, cb.responses[0].Text, "истекло")
+	})
+
+	t.Run("edit keeps the time and other fields", func(t *testing.T) {
+		h, repo, _ := setup()
+		repo.tasks[1] = &models.Task{ID: 1, UserID: 1, OriginalDescription: "Report", Status: models.StatusActive}
+		repo.nextID = 2
+
+		c := newCommandContext(1, "/edit 1 срок: 03/04/2025 15:30 статус: postponed", "")
+		require.NoError(t, h.handleEdit(c))
+
+		keyboard := c.lastMarkup().InlineKeyboard
+		assert.Equal(t, "чт, 3 апреля 2025 в 15:30", keyboard[0][0].Text)
+
+		cb := newCallbackContext(1, keyboard[0][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		task := repo.tasks[1]
+		assert.Equal(t, time.Date(2025, 4, 3, 15, 30, 0, 0, time.Local), task.Deadline)
+		assert.True(t, task.DeadlineHasTime)
+		assert.Equal(t, models.StatusPostponed, task.Status)
+	})
+
+	t.Run("other user cannot choose", func(t *testing.T) {
+		h, repo, _ := setup()
+
+		c := newCommandContext(1, "/add Report срок: 03/04/2025", "")
+		require.NoError(t, h.handleAdd(c))
+
+		cb := newCallbackContext(2, c.lastMarkup().InlineKeyboard[0][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Empty(t, repo.tasks)
+		assert.Contains(t, cb.responses[0].Text, "не ваша")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		h, repo, _ := setup()
+
+		c := newCommandContext(1, "/add Report срок: 03/04/2025", "")
+		require.NoError(t, h.handleAdd(c))
+
+		keyboard := c.lastMarkup().InlineKeyboard
+		cb := newCallbackContext(1, keyboard[len(keyboard)-1][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Empty(t, repo.tasks)
+		assert.Contains(t, cb.edited[0], "отменена")
+	})
+
+	t.Run("the chosen date order is applied without asking", func(t *testing.T) {
+		h, repo, users := setup()
+		users.users[1].DateOrder = models.DateOrderMDY
+
+		c := newCommandContext(1, "/add Report срок: 03/04/2025", "")
+		require.NoError(t, h.handleAdd(c))
+
+		require.Len(t, repo.tasks, 1)
+		assert.Equal(t, time.Date(2025, 3, 4, 23, 59, 59, 0, time.Local), repo.tasks[1].Deadline)
+	})
+}
+
+func TestHandleDateFormat(t *testing.T) {
+	users := newMockUserRepository(&models.User{ID: 1, FirstName: "Alice"})
+	h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithUserRepository(users))
+
+	c := newCommandContext(1, "/dateformat", "")
+	require.NoError(t, h.handleDateFormat(c))
+	assert.Contains(t, c.lastSent(), "Формат дат не выбран")
+	assert.Contains(t, c.lastSent(), "Использование")
+
+	c = newCommandContext(1, "/dateformat MDY", "MDY")
+	require.NoError(t, h.handleDateFormat(c))
+	assert.Contains(t, c.lastSent(), "Формат дат сохранен")
+	assert.Contains(t, c.lastSent(), "4 марта")
+	assert.Equal(t, models.DateOrderMDY, users.users[1].DateOrder)
+
+	c = newCommandContext(1, "/dateformat dd.mm", "dd.mm")
+	require.NoError(t, h.handleDateFormat(c))
+	assert.Contains(t, c.lastSent(), "Неизвестный формат дат «dd.mm»")
+	assert.Equal(t, models.DateOrderMDY, users.users[1].DateOrder)
+
+	c = newCommandContext(1, "/dateformat -", "-")
+	require.NoError(t, h.handleDateFormat(c))
+	assert.Empty(t, users.users[1].DateOrder)
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// forwardTTL - время, в течение которого можно выбрать задачу для пересланного сообщения
+const forwardTTL = 10 * time.Minute
+
+// maxAttachButtons - максимальное количество задач в клавиатуре выбора
+const maxAttachButtons = 10
+
+// pendingForward - пересланное сообщение, ожидающее выбора задачи
+type pendingForward struct {
+	userID     int64
+	discussion models.Discussion
+}
+
+// handleForward привязывает сообщение к задаче.
+// Если сообщение - ответ на сообщение бота о задаче, привязка выполняется сразу,
+// иначе пользователю предлагается выбрать одну из активных задач.
+func (h *Handlers) handleForward(c telebot.Context) error {
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+	if userID == 0 {
+		return c.Send("❌ Не удалось определить пользователя")
+	}
+
+	msg := c.Message()
+	discussion := newDiscussion(msg)
+	if strings.TrimSpace(discussion.Text) == "" {
+		return c.Send("❌ Можно привязать только сообщения с текстом или подписью")
+	}
+
+	if taskID, ok := h.repliedTaskID(msg); ok {
+		discussion.TaskID = taskID
+		return c.Send(h.attachDiscussion(ctx, userID, &discussion))
+	}
+
+	tasks, err := h.repository.GetActiveTasks(ctx, int(userID))
+	if err != nil {
+		h.logUserAction(userID, "attach_discussion_error", fmt.Sprintf("Database error: %v", err))
+		return c.Send("❌ Не удалось загрузить задачи. Попробуйте позже.")
+	}
+
+	if len(tasks) == 0 {
+		return c.Send("📭 У вас нет активных задач для привязки сообщения. Создайте задачу через /add")
+	}
+
+	key := h.forwards.Put(pendingForward{userID: userID, discussion: discussion})
+
+	rows := make([][]telebot.InlineButton, 0, maxAttachButtons+1)
+	for i, task := range tasks {
+		if i == maxAttachButtons {
+			break
+		}
+		label := utils.TruncateText(fmt.Sprintf("%d. %s", task.ID, task.GetDescription()), 40)
+		rows = append(rows, []telebot.InlineButton{
+			inlineButton(label, callbackAttach, key, strconv.Itoa(task.ID)),
+		})
+	}
+	rows = append(rows, []telebot.InlineButton{
+		inlineButton("✖️ Отмена", callbackAttach, key, "0"),
+	})
+
+	text := "💬 К какой задаче привязать сообщение?"
+	if len(tasks) > maxAttachButtons {
+		text += fmt.Sprintf("\n\nПоказаны первые %d задач. Чтобы выбрать другую, ответьте на сообщение бота об этой задаче.", maxAttachButtons)
+	}
+
+	return c.Send(text, inlineKeyboard(rows...))
+}
+
+// handleAttachCallback привязывает пересланное сообщение к выбранной задаче
+func (h *Handlers) handleAttachCallback(c telebot.Context, args []string) error {
+	if len(args) != 2 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	taskID, err := strconv.Atoi(args[1])
+	if err != nil || taskID < 0 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+	owner, ok := h.forwards.Get(args[0])
+	if ok && owner.userID != userID {
+		return c.Respond(&telebot.CallbackResponse{Text: "🚫 Это не ваше сообщение"})
+	}
+
+	// Привязывает только тот обработчик, который забрал запись
+	pending, ok := h.forwards.Take(args[0])
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "⌛ Время выбора истекло, перешлите сообщение еще раз"})
+	}
+
+	text := "✖️ Сообщение не привязано"
+	if taskID != 0 {
+		discussion := pending.discussion
+		discussion.TaskID = taskID
+		text = h.attachDiscussion(ctx, userID, &discussion)
+	}
+
+	if err := editCallbackMessage(c, text); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// handleThread обрабатывает команду /thread
+func (h *Handlers) handleThread(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		args := utils.SplitCommandArgs(c.Message().Payload)
+		if err := h.validateCommand(args, 1); err != nil {
+			return c.Send("❌ Укажите ID задачи. Пример: /thread 3")
+		}
+
+		taskID, err := utils.ParseTaskID(args[0])
+		if err != nil {
+			return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s", err.Error()))
+		}
+
+		task, err := h.getUserTask(ctx, userID, taskID)
+		if err != nil {
+			return c.Send(taskAccessError(taskID, err))
+		}
+
+		discussions, err := h.discussions.GetDiscussionsByTask(ctx, task.ID)
+		if err != nil {
+			h.logUserAction(userID, "thread_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить обсуждение. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "thread", fmt.Sprintf("Task ID: %d, Messages: %d", task.ID, len(discussions)))
+
+		header := fmt.Sprintf("💬 Обсуждение задачи %d: %s", task.ID, task.GetDescription())
+		if len(discussions) == 0 {
+			return c.Send(header + "\n\nСообщений пока нет. Перешлите сообщение боту, чтобы привязать его к задаче.")
+		}
+
+		loc := h.userLocation(ctx, userID)
+		blocks := []string{header}
+		for i, discussion := range discussions {
+			blocks = append(blocks, fmt.Sprintf("%d. [%s]\n%s",
+				i+1, discussion.Timestamp.In(loc).Format("02.01.2006 15:04"), discussion.Text))
+		}
+
+		for _, message := range utils.SplitMessage(blocks, utils.MaxMessageLength) {
+			if err := c.Send(message); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// attachDiscussion сохраняет обсуждение после проверки владельца задачи
+// и возвращает сообщение для пользователя
+func (h *Handlers) attachDiscussion(ctx context.Context, userID int64, discussion *models.Discussion) string {
+	task, err := h.getUserTask(ctx, userID, discussion.TaskID)
+	if err != nil {
+		return taskAccessError(discussion.TaskID, err)
+	}
+
+	if err := h.discussions.AddDiscussion(ctx, discussion); err != nil {
+		h.logUserAction(userID, "attach_discussion_error", fmt.Sprintf("Task ID: %d, Database error: %v", task.ID, err))
+		return "❌ Не удалось привязать сообщение. Попробуйте позже."
+	}
+
+	h.logUserAction(userID, "attach_discussion", fmt.Sprintf("Task ID: %d, Message ID: %d", task.ID, discussion.MessageID))
+	return fmt.Sprintf("💬 Сообщение привязано к задаче %d. Посмотреть обсуждение: /thread %d", task.ID, task.ID)
+}
+
+// newDiscussion создает обсуждение из сообщения Telegram, указывая автора пересланного сообщения
+func newDiscussion(msg *telebot.Message) models.Discussion {
+	text := msg.Text
+	if text == "" {
+		text = msg.Caption
+	}
+
+	if author := forwardAuthor(msg); author != "" && text != "" {
+		text = author + ": " + text
+	}
+
+	timestamp := msg.Time()
+	if msg.OriginalUnixtime != 0 {
+		timestamp = time.Unix(int64(msg.OriginalUnixtime), 0)
+	}
+
+	return models.Discussion{
+		MessageID: msg.ID,
+		Text:      text,
+		Timestamp: timestamp,
+	}
+}
+
+// forwardAuthor возвращает имя автора пересланного сообщения
+func forwardAuthor(msg *telebot.Message) string {
+	switch {
+	case msg.OriginalSender != nil:
+		user := models.User{
+			FirstName: msg.OriginalSender.FirstName,
+			LastName:  msg.OriginalSender.LastName,
+			Username:  msg.OriginalSender.Username,
+		}
+		return user.GetDisplayName()
+	case msg.OriginalChat != nil:
+		return msg.OriginalChat.Title
+	default:
+		return msg.OriginalSenderName
+	}
+}
+
+// isForwarded проверяет, что сообщение переслано, включая пользователей со скрытым профилем
+func isForwarded(msg *telebot.Message) bool {
+	return msg.IsForwarded() || msg.OriginalSenderName != "" || msg.OriginalUnixtime != 0
+}
+
+// repliedTaskID возвращает ID задачи из сообщения этого бота о задаче, на которое
+// ответил пользователь. Ответы на другие сообщения и на сообщения других ботов не привязываются.
+func (h *Handlers) repliedTaskID(msg *telebot.Message) (int, bool) {
+	reply := msg.ReplyTo
+	if reply == nil || reply.Sender == nil || h.botID == 0 || reply.Sender.ID != h.botID {
+		return 0, false
+	}
+
+	return utils.ExtractTaskID(reply.Text)
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/telebot.v3"
+)
+
+func newForwardContext(userID int64, messageID int, text string) *fakeContext {
+	c := newCommandContext(userID, text, "")
+	c.message.ID = messageID
+	c.message.Unixtime = time.Now().Unix()
+	c.message.OriginalSender = &telebot.User{ID: 99, FirstName: "Alice", Username: "alice"}
+	c.message.OriginalUnixtime = int(time.Date(2025, 7, 15, 14, 30, 0, 0, time.Local).Unix())
+	return c
+}
+
+func TestHandleMessageForward(t *testing.T) {
+	setup := func(t *testing.T) (*Handlers, *mockTaskRepository, *mockDiscussionRepository) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 2, models.StatusActive)
+		addMockTasks(t, repo, 2, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+		h.botID = 1000
+		return h, repo, h.discussions.(*mockDiscussionRepository)
+	}
+
+	t.Run("forward offers active tasks", func(t *testing.T) {
+		h, _, discussions := setup(t)
+
+		c := newForwardContext(1, 50, "Let's meet tomorrow")
+		require.NoError(t, h.handleMessage(c))
+
+		assert.Contains(t, c.lastSent(), "К какой задаче")
+		keyboard := c.lastMarkup().InlineKeyboard
+		require.Len(t, keyboard, 3) // две задачи и отмена
+		assert.True(t, strings.HasPrefix(keyboard[0][0].Text, "1. Task active 1"))
+		assert.Empty(t, discussions.discussions)
+
+		// Выбор задачи привязывает сообщение
+		cb := newCallbackContext(1, keyboard[1][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		require.Len(t, discussions.discussions, 1)
+		discussion := discussions.discussions[0]
+		assert.Equal(t, 2, discussion.TaskID)
+		assert.Equal(t, 50, discussion.MessageID)
+		assert.Equal(t, "@alice: Let's meet tomorrow", discussion.Text)
+		assert.Equal(t, 14, discussion.Timestamp.Hour())
+		assert.Contains(t, cb.edited[0], "привязано к задаче 2")
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		h, _, discussions := setup(t)
+
+		c := newForwardContext(1, 50, "Let's meet tomorrow")
+		require.NoError(t, h.handleMessage(c))
+		keyboard := c.lastMarkup().InlineKeyboard
+
+		cb := newCallbackContext(1, keyboard[len(keyboard)-1][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Empty(t, discussions.discussions)
+		assert.Contains(t, cb.edited[0], "не привязано")
+	})
+
+	t.Run("other user cannot pick the task", func(t *testing.T) {
+		h, _, discussions := setup(t)
+
+		c := newForwardContext(1, 50, "Let's meet tomorrow")
+		require.NoError(t, h.handleMessage(c))
+
+		cb := newCallbackContext(2, c.lastMarkup().InlineKeyboard[0][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Empty(t, discussions.discussions)
+		assert.Contains(t, cb.responses[0].Text, "не ваше")
+	})
+
+	t.Run("reply to a bot task message attaches directly", func(t *testing.T) {
+		h, _, discussions := setup(t)
+
+		c := newForwardContext(1, 51, "Forwarded note")
+		c.message.ReplyTo = &telebot.Message{
+			Sender: &telebot.User{ID: 1000, IsBot: true},
+			Text:   "✅ Задача добавлена!\n\n📝 ID: 1\n📄 Описание: Task active 1",
+		}
+		require.NoError(t, h.handleMessage(c))
+
+		require.Len(t, discussions.discussions, 1)
+		assert.Equal(t, 1, discussions.discussions[0].TaskID)
+		assert.Contains(t, c.lastSent(), "привязано к задаче 1")
+	})
+
+	t.Run("reply to a task of another user", func(t *testing.T) {
+		h, _, discussions := setup(t)
+
+		c := newCommandContext(1, "Some note", "")
+		c.message.ReplyTo = &telebot.Message{
+			Sender: &telebot.User{ID: 1000, IsBot: true},
+			Text:   "📝 ID: 3",
+		}
+		require.NoError(t, h.handleMessage(c))
+
+		assert.Empty(t, discussions.discussions)
+		assert.Contains(t, c.lastSent(), "принадлежит другому пользователю")
+	})
+
+	t.Run("forward with many tasks", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, maxAttachButtons+1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newForwardContext(1, 50, "Let's meet tomorrow")
+		require.NoError(t, h.handleMessage(c))
+
+		assert.Len(t, c.lastMarkup().InlineKeyboard, maxAttachButtons+1)
+		assert.Contains(t, c.lastSent(), "ответьте на сообщение бота об этой задаче")
+	})
+
+	t.Run("replies to other messages are not attached", func(t *testing.T) {
+		h, _, discussions := setup(t)
+
+		replies := map[string]*telebot.Message{
+			"another bot":          {Sender: &telebot.User{ID: 2000, IsBot: true}, Text: "📝 ID: 1"},
+			"list of this bot":     {Sender: &telebot.User{ID: 1000, IsBot: true}, Text: "📋 Активные задачи: 1\n\n⬜ 1. Task active 1 (ID: 1)"},
+			"message of this user": {Sender: &telebot.User{ID: 1}, Text: "📝 ID: 1"},
+		}
+		for name, reply := range replies {
+			c := newCommandContext(1, "Some note", "")
+			c.message.ReplyTo = reply
+			require.NoError(t, h.handleMessage(c))
+
+			assert.Empty(t, discussions.discussions, name)
+			assert.Contains(t, c.lastSent(), "/help", name)
+		}
+	})
+
+	t.Run("forward without active tasks", func(t *testing.T) {
+		h := newTestHandlers(newMockTaskRepository())
+
+		c := newForwardContext(1, 50, "Let's meet tomorrow")
+		require.NoError(t, h.handleMessage(c))
+
+		assert.Contains(t, c.lastSent(), "нет активных задач")
+	})
+
+	t.Run("plain message", func(t *testing.T) {
+		h, _, _ := setup(t)
+
+		c := newCommandContext(1, "Hello", "")
+		require.NoError(t, h.handleMessage(c))
+
+		assert.Contains(t, c.lastSent(), "/help")
+	})
+}
+
+func TestHandleThread(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTasks(t, repo, 1, 1, models.StatusActive)
+	h := newTestHandlers(repo)
+	discussions := h.discussions.(*mockDiscussionRepository)
+
+	t.Run("empty thread", func(t *testing.T) {
+		c := newCommandContext(1, "/thread 1", "1")
+		require.NoError(t, h.handleThread(c))
+		assert.Contains(t, c.lastSent(), "Сообщений пока нет")
+	})
+
+	t.Run("messages in order", func(t *testing.T) {
+		require.NoError(t, discussions.AddDiscussion(t.Context(), &models.Discussion{TaskID: 1, MessageID: 1, Text: "First"}))
+		require.NoError(t, discussions.AddDiscussion(t.Context(), &models.Discussion{TaskID: 1, MessageID: 2, Text: "Second"}))
+
+		c := newCommandContext(1, "/thread 1", "1")
+		require.NoError(t, h.handleThread(c))
+
+		thread := c.lastSent()
+		assert.Contains(t, thread, "💬 Обсуждение задачи 1: Task active 1")
+		assert.Less(t, strings.Index(thread, "1. ["), strings.Index(thread, "2. ["))
+		assert.Less(t, strings.Index(thread, "First"), strings.Index(thread, "Second"))
+	})
+
+	t.Run("long thread is split", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			require.NoError(t, discussions.AddDiscussion(t.Context(), &models.Discussion{
+				TaskID: 1, MessageID: 10 + i, Text: strings.Repeat("x", 2000),
+			}))
+		}
+
+		c := newCommandContext(1, "/thread 1", "1")
+		require.NoError(t, h.handleThread(c))
+		assert.Greater(t, len(c.sent), 1)
+	})
+
+	t.Run("task of another user", func(t *testing.T) {
+		c := newCommandContext(2, "/thread 1", "1")
+		require.NoError(t, h.handleThread(c))
+		assert.Contains(t, c.lastSent(), "принадлежит другому пользователю")
+	})
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// undoTTL - время, в течение которого можно отменить /done
+const undoTTL = 5 * time.Minute
+
+// doneUndo хранит предыдущие статусы задач для отмены /done
+type doneUndo struct {
+	userID   int64
+	previous []taskStatus
+}
+
+// taskStatus - статус задачи до изменения
+type taskStatus struct {
+	taskID int
+	status string
+	nextID int // Следующий повтор, созданный при выполнении задачи
+}
+
+// doneResult - итог выполнения /done по всем ID
+type doneResult struct {
+	completed   []int
+	alreadyDone []int
+	notFound    []int
+	notOwned    []int
+	failed      []int
+	next        []*models.Task // Следующие повторы выполненных задач
+	seriesEnded []int
+}
+
+// handleDone обрабатывает команду /done
+func (h *Handlers) handleDone(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		ids, err := utils.ParseTaskIDs(utils.SplitCommandArgs(c.Message().Payload))
+		if err != nil {
+			h.logUserAction(userID, "done_task_error", fmt.Sprintf("Parse error: %v", err))
+			return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s\n\nПримеры: /done 3, /done 3 5 7, /done 3-9", err.Error()))
+		}
+
+		result := doneResult{}
+		undo := doneUndo{userID: userID}
+		loc := h.userLocation(ctx, userID)
+
+		for _, id := range ids {
+			task, err := h.getUserTask(ctx, userID, id)
+			switch {
+			case errors.Is(err, repository.ErrTaskNotFound):
+				result.notFound = append(result.notFound, id)
+				continue
+			case errors.Is(err, errTaskNotOwned):
+				result.notOwned = append(result.notOwned, id)
+				continue
+			case err != nil:
+				h.logUserAction(userID, "done_task_error", fmt.Sprintf("Task ID: %d, Database error: %v", id, err))
+				result.failed = append(result.failed, id)
+				continue
+			}
+
+			if task.IsDone() {
+				result.alreadyDone = append(result.alreadyDone, id)
+				continue
+			}
+
+			before := *task
+			next, err := h.repository.CompleteTask(ctx, task)
+			if err != nil {
+				h.logUserAction(userID, "done_task_error", fmt.Sprintf("Task ID: %d, Database error: %v", id, err))
+				result.failed = append(result.failed, id)
+				continue
+			}
+			h.recordChanges(ctx, userID, &before, task)
+
+			result.completed = append(result.completed, id)
+			previous := taskStatus{taskID: id, status: before.Status}
+			switch {
+			case next != nil:
+				result.next = append(result.next, next)
+				previous.nextID = next.ID
+			case task.IsRecurring():
+				result.seriesEnded = append(result.seriesEnded, id)
+			}
+			undo.previous = append(undo.previous, previous)
+		}
+
+		h.logUserAction(userID, "done_task", fmt.Sprintf("Completed: %v", result.completed))
+
+		if len(result.completed) == 0 {
+			return c.Send(result.summary(loc))
+		}
+
+		key := h.undo.Put(undo)
+		markup := inlineKeyboard([]telebot.InlineButton{
+			inlineButton("↩️ Отменить", callbackUndoDone, key),
+		})
+
+		return c.Send(result.summary(loc), markup)
+	})
+}
+
+// handleUndoDoneCallback восстанавливает статусы задач, отмеченных через /done
+func (h *Handlers) handleUndoDoneCallback(c telebot.Context, args []string) error {
+	if len(args) != 1 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+	owner, ok := h.undo.Get(args[0])
+	if ok && owner.userID != userID {
+		return c.Respond(&telebot.CallbackResponse{Text: "🚫 Это не ваше действие"})
+	}
+
+	// Отменяет только тот обработчик, который забрал запись: повторные нажатия ее уже не найдут
+	undo, ok := h.undo.Take(args[0])
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "⌛ Время для отмены истекло"})
+	}
+
+	var restored []int
+	for _, previous := range undo.previous {
+		ok, err := h.undoCompletion(ctx, userID, previous)
+		if err != nil {
+			h.logUserAction(userID, "undo_done_error", fmt.Sprintf("Task ID: %d, Error: %v", previous.taskID, err))
+			continue
+		}
+		if ok {
+			restored = append(restored, previous.taskID)
+		}
+	}
+
+	h.logUserAction(userID, "undo_done", fmt.Sprintf("Restored: %v", restored))
+
+	text := "↩️ Отмена не потребовалась: задачи уже изменены"
+	if len(restored) > 0 {
+		text = fmt.Sprintf("↩️ Отменено. Статус восстановлен для задач: %s", formatIDs(restored))
+	}
+
+	if err := editCallbackMessage(c, text); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// undoCompletion возвращает задаче статус до /done и удаляет созданный ею повтор в одной
+// транзакции. Возвращает false, если задачу уже изменили после /done.
+func (h *Handlers) undoCompletion(ctx context.Context, userID int64, previous taskStatus) (bool, error) {
+	task, err := h.getUserTask(ctx, userID, previous.taskID)
+	if err != nil {
+		return false, err
+	}
+
+	// Задачу могли изменить после /done - в этом случае не трогаем ее
+	if !task.IsDone() {
+		return false, nil
+	}
+
+	before := *task
+	task.Status = previous.status
+	err = h.repository.WithTx(ctx, func(repo repository.TaskRepository) error {
+		if err := repo.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		return removeNextOccurrence(ctx, repo, userID, previous.nextID)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	h.recordChanges(ctx, userID, &before, task)
+	return true, nil
+}
+
+// removeNextOccurrence удаляет повтор, созданный отмененным /done, если с ним еще ничего не делали
+func removeNextOccurrence(ctx context.Context, repo repository.TaskRepository, userID int64, nextID int) error {
+	if nextID == 0 {
+		return nil
+	}
+
+	next, err := repo.GetTask(ctx, nextID)
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if int64(next.UserID) != userID || !next.IsActive() {
+		return nil
+	}
+
+	return repo.DeleteTask(ctx, next.ID)
+}
+
+// summary формирует сводку по результатам /done, сроки повторов показываются в поясе loc
+func (r doneResult) summary(loc *time.Location) string {
+	var lines []string
+
+	if len(r.completed) > 0 {
+		lines = append(lines, fmt.Sprintf("✅ Выполнено: %s", formatIDs(r.completed)))
+	}
+	if len(r.alreadyDone) > 0 {
+		lines = append(lines, fmt.Sprintf("☑️ Уже выполнены: %s", formatIDs(r.alreadyDone)))
+	}
+	if len(r.notFound) > 0 {
+		lines = append(lines, fmt.Sprintf("❓ Не найдены: %s", formatIDs(r.notFound)))
+	}
+	if len(r.notOwned) > 0 {
+		lines = append(lines, fmt.Sprintf("🚫 Не ваши задачи: %s", formatIDs(r.notOwned)))
+	}
+	if len(r.failed) > 0 {
+		lines = append(lines, fmt.Sprintf("❌ Ошибка сохранения: %s", formatIDs(r.failed)))
+	}
+	for _, next := range r.next {
+		lines = append(lines, fmt.Sprintf("🔁 Следующий повтор: задача %d, срок %s",
+			next.ID, utils.FormatDeadline(next.Deadline.In(loc), next.DeadlineHasTime)))
+	}
+	if len(r.seriesEnded) > 0 {
+		lines = append(lines, fmt.Sprintf("🏁 Повторы закончились: %s", formatIDs(r.seriesEnded)))
+	}
+
+	if len(r.completed) > 0 {
+		lines = append(lines, fmt.Sprintf("\nОтменить можно в течение %d минут.", int(undoTTL.Minutes())))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatIDs форматирует список ID задач для вывода
+func formatIDs(ids []int) string {
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, strconv.Itoa(id))
+	}
+	return strings.Join(parts, ", ")
+}
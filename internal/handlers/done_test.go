@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDone(t *testing.T) {
+	t.Run("per-ID summary", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 3, models.StatusActive) // 1-3
+		addMockTasks(t, repo, 1, 1, models.StatusDone)   // 4
+		addMockTasks(t, repo, 2, 1, models.StatusActive) // 5
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/done 1-2 4 5 99", "1-2 4 5 99")
+		require.NoError(t, h.handleDone(c))
+
+		summary := c.lastSent()
+		assert.Contains(t, summary, "✅ Выполнено: 1, 2")
+		assert.Contains(t, summary, "☑️ Уже выполнены: 4")
+		assert.Contains(t, summary, "🚫 Не ваши задачи: 5")
+		assert.Contains(t, summary, "❓ Не найдены: 99")
+
+		assert.Equal(t, models.StatusDone, repo.tasks[1].Status)
+		assert.Equal(t, models.StatusDone, repo.tasks[2].Status)
+		assert.Equal(t, models.StatusActive, repo.tasks[3].Status)
+		assert.Equal(t, models.StatusActive, repo.tasks[5].Status)
+
+		keyboard := c.lastMarkup().InlineKeyboard
+		require.Len(t, keyboard, 1)
+		assert.Equal(t, "↩️ Отменить", keyboard[0][0].Text)
+	})
+
+	t.Run("nothing completed has no undo button", func(t *testing.T) {
+		h := newTestHandlers(newMockTaskRepository())
+
+		c := newCommandContext(1, "/done 7", "7")
+		require.NoError(t, h.handleDone(c))
+
+		assert.Contains(t, c.lastSent(), "❓ Не найдены: 7")
+		assert.Nil(t, c.lastMarkup())
+	})
+
+	t.Run("recurring task gets the next occurrence", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/add Weekly report повтор: каждый понедельник", "")
+		require.NoError(t, h.handleAdd(c))
+		assert.Contains(t, c.lastSent(), "🔁 Повтор: каждый понедельник")
+
+		first := repo.tasks[1]
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", first.Recurrence)
+		assert.Equal(t, time.Monday, first.Deadline.Weekday())
+
+		c = newCommandContext(1, "/done 1", "1")
+		require.NoError(t, h.handleDone(c))
+
+		require.Contains(t, repo.tasks, 2)
+		next := repo.tasks[2]
+		assert.Contains(t, c.lastSent(), "🔁 Следующий повтор: задача 2")
+		assert.Equal(t, models.StatusActive, next.Status)
+		assert.Equal(t, 1, next.SeriesID)
+		assert.Equal(t, first.Deadline.AddDate(0, 0, 7), next.Deadline)
+
+		// Undo removes the occurrence that was not touched yet
+		cb := newCallbackContext(1, c.lastMarkup().InlineKeyboard[0][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Equal(t, models.StatusActive, repo.tasks[1].Status)
+		assert.NotContains(t, repo.tasks, 2)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		h := newTestHandlers(newMockTaskRepository())
+
+		c := newCommandContext(1, "/done", "")
+		require.NoError(t, h.handleDone(c))
+
+		assert.Contains(t, c.lastSent(), "❌ Ошибка в команде")
+	})
+}
+
+func TestHandleUndoDoneCallback(t *testing.T) {
+	setup := func(t *testing.T) (*Handlers, *mockTaskRepository, string) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		addMockTasks(t, repo, 1, 1, models.StatusPostponed)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/done 1 2", "1 2")
+		require.NoError(t, h.handleDone(c))
+		return h, repo, c.lastMarkup().InlineKeyboard[0][0].Data
+	}
+
+	t.Run("restores previous statuses", func(t *testing.T) {
+		h, repo, data := setup(t)
+
+		c := newCallbackContext(1, data)
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Equal(t, models.StatusActive, repo.tasks[1].Status)
+		assert.Equal(t, models.StatusPostponed, repo.tasks[2].Status)
+		require.Len(t, c.edited, 1)
+		assert.Contains(t, c.edited[0], "1, 2")
+
+		// Повторная отмена невозможна
+		c = newCallbackContext(1, data)
+		require.NoError(t, h.handleCallback(c))
+		assert.Contains(t, c.responses[0].Text, "истекло")
+	})
+
+	t.Run("other user cannot undo", func(t *testing.T) {
+		h, repo, data := setup(t)
+
+		c := newCallbackContext(2, data)
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Contains(t, c.responses[0].Text, "не ваше")
+		assert.Equal(t, models.StatusDone, repo.tasks[1].Status)
+	})
+
+	t.Run("undo taken by a concurrent tap", func(t *testing.T) {
+		h, repo, data := setup(t)
+
+		// Параллельное нажатие уже забрало запись
+		_, args := parseCallbackData(data)
+		_, ok := h.undo.Take(args[0])
+		require.True(t, ok)
+
+		c := newCallbackContext(1, data)
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Contains(t, c.responses[0].Text, "истекло")
+		assert.Empty(t, c.edited)
+		assert.Equal(t, models.StatusDone, repo.tasks[1].Status)
+	})
+
+	t.Run("expired undo", func(t *testing.T) {
+		h, repo, data := setup(t)
+		h.undo.now = func() time.Time { return time.Now().Add(undoTTL + time.Minute) }
+
+		c := newCallbackContext(1, data)
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Contains(t, c.responses[0].Text, "истекло")
+		assert.Equal(t, models.StatusDone, repo.tasks[1].Status)
+	})
+}
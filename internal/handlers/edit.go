@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/repository"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// handleEdit обрабатывает команду /edit
+func (h *Handlers) handleEdit(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		return h.editTask(c, userID, c.Text())
+	})
+}
+
+// editTask изменяет задачу по тексту команды /edit.
+// Если срок можно понять по-разному, пользователю предлагается выбрать дату.
+func (h *Handlers) editTask(c telebot.Context, userID int64, text string) error {
+	ctx := requestContext(c)
+
+	user := h.userSettings(ctx, userID)
+	loc := user.Location()
+	input, err := utils.ParseEditCommand(text, time.Now().In(loc), user.DateOrder)
+	var ambiguous *utils.AmbiguousDateError
+	if errors.As(err, &ambiguous) {
+		return h.askDate(c, userID, text, "срок", h.editTask, ambiguous)
+	}
+	if err != nil {
+		h.logUserAction(userID, "edit_task_error", fmt.Sprintf("Parse error: %v", err))
+		return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s\n\nПример: /edit 2 \"Купить продукты\" срок: 2025-07-21 !2", err.Error()))
+	}
+
+	if input.Description != "" {
+		if err := utils.ValidateDescription(input.Description); err != nil {
+			h.logUserAction(userID, "edit_task_error", fmt.Sprintf("Validation error: %v", err))
+			return c.Send(fmt.Sprintf("❌ %s", err.Error()))
+		}
+	}
+
+	task, err := h.getUserTask(ctx, userID, input.TaskID)
+	if err != nil {
+		return c.Send(taskAccessError(input.TaskID, err))
+	}
+
+	before := *task
+
+	descriptionChanged := input.Description != "" && input.Description != task.OriginalDescription
+	if descriptionChanged {
+		task.OriginalDescription = input.Description
+		// Обработанное LLM описание относится к старому тексту
+		task.LLMProcessedDesc = ""
+	}
+	if input.HasDeadline {
+		task.Deadline = input.Deadline
+		task.DeadlineHasTime = input.HasTime
+	}
+	if input.ClearDeadline {
+		task.Deadline = time.Time{}
+		task.DeadlineHasTime = false
+	}
+	if input.Status != "" {
+		task.Status = input.Status
+	}
+	if input.HasPriority {
+		task.Priority = input.Priority
+	}
+	if input.HasTags {
+		task.Tags = input.Tags
+	}
+
+	if err := h.repository.UpdateTask(ctx, task); err != nil {
+		h.logUserAction(userID, "edit_task_error", fmt.Sprintf("Database error: %v", err))
+		return c.Send("❌ Не удалось сохранить изменения. Попробуйте позже.")
+	}
+
+	changes := h.recordChanges(ctx, userID, &before, task)
+	h.logUserAction(userID, "edit_task", fmt.Sprintf("Task ID: %d, Changes: %d", task.ID, len(changes)))
+
+	// Новое описание отправляется в LLM только после сохранения правки
+	var rewritten bool
+	var rewriteErr error
+	if descriptionChanged {
+		rewritten, rewriteErr = h.rewriteDescription(ctx, userID, task)
+	}
+
+	if len(changes) == 0 {
+		return c.Send(fmt.Sprintf("ℹ️ Задача %d не изменилась", task.ID))
+	}
+
+	lines := []string{fmt.Sprintf("✏️ Задача %d обновлена:", task.ID)}
+	for _, change := range changes {
+		lines = append(lines, "• "+formatChangeValues(change, loc))
+	}
+
+	if rewritten {
+		lines = append(lines, "", fmt.Sprintf("✨ Улучшенное описание: %s", task.LLMProcessedDesc))
+		return c.Send(strings.Join(lines, "\n"), rewriteKeyboard(task.ID))
+	}
+	if rewriteErr != nil {
+		lines = append(lines, "", rewriteWarning(rewriteErr))
+	}
+
+	return c.Send(strings.Join(lines, "\n"))
+}
+
+// taskAccessError формирует сообщение об ошибке доступа к задаче
+func taskAccessError(taskID int, err error) string {
+	switch {
+	case errors.Is(err, repository.ErrTaskNotFound):
+		return fmt.Sprintf("❓ Задача %d не найдена", taskID)
+	case errors.Is(err, errTaskNotOwned):
+		return fmt.Sprintf("🚫 Задача %d принадлежит другому пользователю", taskID)
+	default:
+		return "❌ Не удалось загрузить задачу. Попробуйте позже."
+	}
+}
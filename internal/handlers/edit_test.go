@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleEdit(t *testing.T) {
+	setup := func(t *testing.T) (*Handlers, *mockTaskRepository) {
+		repo := newMockTaskRepository()
+		require.NoError(t, repo.AddTask(t.Context(), &models.Task{
+			UserID:              1,
+			OriginalDescription: "Buy groceries",
+			LLMProcessedDesc:    "Buy groceries for the week",
+			Deadline:            time.Date(2025, 7, 15, 23, 59, 59, 0, time.Local),
+		}))
+		return newTestHandlers(repo), repo
+	}
+
+	t.Run("description and deadline", func(t *testing.T) {
+		h, repo := setup(t)
+
+		c := newCommandContext(1, `/edit 1 "Cook dinner" срок: 2025-07-21`, "")
+		require.NoError(t, h.handleEdit(c))
+
+		task := repo.tasks[1]
+		assert.Equal(t, "Cook dinner", task.OriginalDescription)
+		assert.Empty(t, task.LLMProcessedDesc)
+		assert.Equal(t, 21, task.Deadline.Day())
+
+		assert.Contains(t, c.lastSent(), "✏️ Задача 1 обновлена")
+		assert.Contains(t, c.lastSent(), "описание: Buy groceries → Cook dinner")
+		assert.Contains(t, c.lastSent(), "срок: 15.07.2025 → 21.07.2025")
+		assert.Len(t, repo.history, 2)
+	})
+
+	t.Run("deadline with time", func(t *testing.T) {
+		h, repo := setup(t)
+
+		c := newCommandContext(1, "/edit 1 срок: 2025-07-15 15:30", "")
+		require.NoError(t, h.handleEdit(c))
+
+		task := repo.tasks[1]
+		assert.True(t, task.DeadlineHasTime)
+		assert.Equal(t, 15, task.Deadline.Hour())
+		assert.Contains(t, c.lastSent(), "срок: 15.07.2025 → 15.07.2025 15:30")
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		h, repo := setup(t)
+
+		c := newCommandContext(1, "/edit 1 !2", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Equal(t, models.PriorityHigh, repo.tasks[1].Priority)
+		assert.Contains(t, c.lastSent(), "приоритет: — → 🟧 высокий (!2)")
+
+		c = newCommandContext(1, "/edit 1 приоритет: -", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Equal(t, models.PriorityNone, repo.tasks[1].Priority)
+		assert.Contains(t, c.lastSent(), "приоритет: 🟧 высокий (!2) → —")
+		assert.Len(t, repo.history, 2)
+	})
+
+	t.Run("clear deadline and change status", func(t *testing.T) {
+		h, repo := setup(t)
+
+		c := newCommandContext(1, "/edit 1 срок: - статус: postponed", "")
+		require.NoError(t, h.handleEdit(c))
+
+		task := repo.tasks[1]
+		assert.False(t, task.HasDeadline())
+		assert.Equal(t, models.StatusPostponed, task.Status)
+		assert.Equal(t, "Buy groceries", task.OriginalDescription)
+		assert.Equal(t, "Buy groceries for the week", task.LLMProcessedDesc)
+		assert.Contains(t, c.lastSent(), "срок: 15.07.2025 → —")
+		assert.Contains(t, c.lastSent(), "статус: active → postponed")
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		h, repo := setup(t)
+
+		c := newCommandContext(1, "/edit 1 статус: active", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Contains(t, c.lastSent(), "не изменилась")
+		assert.Empty(t, repo.history)
+	})
+
+	t.Run("task of another user", func(t *testing.T) {
+		h, repo := setup(t)
+
+		c := newCommandContext(2, "/edit 1 Hacked", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Contains(t, c.lastSent(), "принадлежит другому пользователю")
+		assert.Equal(t, "Buy groceries", repo.tasks[1].OriginalDescription)
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		h, _ := setup(t)
+
+		c := newCommandContext(1, "/edit 42 New text", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Contains(t, c.lastSent(), "Задача 42 не найдена")
+	})
+
+	t.Run("parse error", func(t *testing.T) {
+		h, _ := setup(t)
+
+		c := newCommandContext(1, "/edit 1", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Contains(t, c.lastSent(), "❌ Ошибка в команде")
+	})
+}
+
+func TestHandleHistory(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTasks(t, repo, 1, 1, models.StatusActive)
+	h := newTestHandlers(repo)
+
+	t.Run("empty history", func(t *testing.T) {
+		c := newCommandContext(1, "/history 1", "1")
+		require.NoError(t, h.handleHistory(c))
+		assert.Contains(t, c.lastSent(), "пока нет изменений")
+	})
+
+	t.Run("edits and done are recorded", func(t *testing.T) {
+		require.NoError(t, h.handleEdit(newCommandContext(1, "/edit 1 Renamed", "")))
+		require.NoError(t, h.handleDone(newCommandContext(1, "/done 1", "1")))
+
+		c := newCommandContext(1, "/history 1", "1")
+		require.NoError(t, h.handleHistory(c))
+
+		history := c.lastSent()
+		assert.Contains(t, history, "🕒 История задачи 1")
+		assert.Contains(t, history, "вы: описание: Task active 1 → Renamed")
+		assert.Contains(t, history, "вы: статус: active → done")
+	})
+
+	t.Run("task of another user", func(t *testing.T) {
+		c := newCommandContext(2, "/history 1", "1")
+		require.NoError(t, h.handleHistory(c))
+		assert.Contains(t, c.lastSent(), "принадлежит другому пользователю")
+	})
+
+	t.Run("missing ID", func(t *testing.T) {
+		c := newCommandContext(1, "/history", "")
+		require.NoError(t, h.handleHistory(c))
+		assert.Contains(t, c.lastSent(), "Укажите ID задачи")
+	})
+}
+
+func TestFormatChangeValue(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+
+	cases := map[string]string{
+		// Срок на весь день показывается датой и в другом поясе
+		"2025-07-15T20:59:59Z all-day": "15.07.2025",
+		// Срок на 23:59:59 по Москве показывается со временем
+		"2025-07-15T20:59:59Z": "15.07.2025 23:59",
+		"":                     "—",
+		"not a date":           "not a date",
+	}
+	for value, expected := range cases {
+		assert.Equal(t, expected, formatChangeValue(models.FieldDeadline, value, moscow), value)
+	}
+
+	assert.Equal(t, "15.07.2025", formatChangeValue(models.FieldDeadline, "2025-07-15T20:59:59Z all-day", time.UTC))
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"telegram-bot-assistente/internal/repository"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// findResultsLimit - сколько найденных задач показывает /find
+const findResultsLimit = 10
+
+const findUsage = `Использование: /find запрос
+Примеры: /find отчет, /find "квартальный отчет", /find отч*`
+
+// handleFind обрабатывает команду /find: полнотекстовый поиск по описаниям задач и обсуждениям
+func (h *Handlers) handleFind(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		query := strings.TrimSpace(c.Message().Payload)
+		if query == "" {
+			return c.Send(findUsage)
+		}
+
+		// Лишняя задача показывает, что найдено больше, чем помещается в ответ
+		results, err := h.repository.SearchTasks(ctx, int(userID), query, findResultsLimit+1)
+		if errors.Is(err, repository.ErrEmptySearchQuery) {
+			return c.Send("❌ В запросе нет слов для поиска\n\n" + findUsage)
+		}
+		if err != nil {
+			h.logUserAction(userID, "find_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось выполнить поиск. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "find", fmt.Sprintf("Query: %q, found: %d", query, len(results)))
+
+		if len(results) == 0 {
+			return c.Send(fmt.Sprintf("🔎 По запросу «%s» ничего не найдено", query))
+		}
+
+		loc := h.userLocation(ctx, userID)
+		header := fmt.Sprintf("🔎 Найдено задач: %d", len(results))
+		if len(results) > findResultsLimit {
+			results = results[:findResultsLimit]
+			header = fmt.Sprintf("🔎 Найдено больше %d задач, показаны самые подходящие", findResultsLimit)
+		}
+
+		message := header
+		for i, result := range results {
+			item := "\n\n" + utils.FormatTaskItem(toTaskInfo(result.Task, loc), i+1)
+			if result.Snippet != "" {
+				item += "\n   💬 " + result.Snippet
+			}
+			if len(message)+len(item) > utils.MaxMessageLength {
+				break
+			}
+			message += item
+		}
+
+		return c.Send(message)
+	})
+}
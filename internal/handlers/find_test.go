@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleFind(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTaggedTask(t, repo, 1, "Квартальный отчет", "work")
+	addMockTaggedTask(t, repo, 1, "Купить молоко")
+	addMockTaggedTask(t, repo, 2, "Отчет другого пользователя")
+	h := newTestHandlers(repo)
+
+	t.Run("found", func(t *testing.T) {
+		c := newCommandContext(1, "/find отчет", "отчет")
+		require.NoError(t, h.handleFind(c))
+
+		assert.Contains(t, c.lastSent(), "🔎 Найдено задач: 1")
+		assert.Contains(t, c.lastSent(), "📝 1. Квартальный отчет (ID: 1)")
+		assert.Contains(t, c.lastSent(), "🏷 #work")
+		assert.Contains(t, c.lastSent(), "💬 Квартальный отчет")
+		assert.NotContains(t, c.lastSent(), "другого пользователя")
+	})
+
+	t.Run("nothing found", func(t *testing.T) {
+		c := newCommandContext(1, "/find хлеб", "хлеб")
+		require.NoError(t, h.handleFind(c))
+
+		assert.Contains(t, c.lastSent(), "ничего не найдено")
+	})
+
+	t.Run("no query", func(t *testing.T) {
+		c := newCommandContext(1, "/find", "")
+		require.NoError(t, h.handleFind(c))
+		assert.Contains(t, c.lastSent(), "Использование: /find")
+
+		c = newCommandContext(1, `/find "*"`, `"*"`)
+		require.NoError(t, h.handleFind(c))
+		assert.Contains(t, c.lastSent(), "В запросе нет слов")
+	})
+
+	t.Run("results are limited", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		for i := 0; i < findResultsLimit+2; i++ {
+			addMockTaggedTask(t, repo, 1, "Звонок")
+		}
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/find звонок", "звонок")
+		require.NoError(t, h.handleFind(c))
+
+		assert.Contains(t, c.lastSent(), "Найдено больше 10 задач, показаны самые подходящие")
+		assert.Contains(t, c.lastSent(), "10. Звонок")
+		assert.NotContains(t, c.lastSent(), "11. Звонок")
+	})
+}
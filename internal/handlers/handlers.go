@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"telegram-bot-assistente/internal/limiter"
+	"telegram-bot-assistente/internal/llm"
 	"telegram-bot-assistente/internal/models"
 	"telegram-bot-assistente/internal/repository"
 	"telegram-bot-assistente/internal/utils"
@@ -18,31 +22,98 @@ type Handler interface {
 	Handle(ctx context.Context, c telebot.Context) error
 }
 
+// requestTimeout ограничивает обработку одного обновления вместе с запросом к LLM
+const requestTimeout = 60 * time.Second
+
+// requestContextKey - ключ, под которым withRequestContext сохраняет контекст запроса
+const requestContextKey = "request_context"
+
 // Handlers содержит все обработчики команд бота
 type Handlers struct {
-	repository repository.TaskRepository
-	// Будут добавлены позже:
-	// llmClient llm.Client
-	// limiter limiter.Limiter
+	repository  repository.TaskRepository
+	discussions repository.DiscussionRepository
+	users       repository.UserRepository
+	undo        *pendingStore[doneUndo]
+	forwards    *pendingStore[pendingForward]
+	dates       *pendingStore[pendingDate]
+	llmClient   llm.Client
+	limiter     limiter.Limiter
+	stats       StatsProvider
+	reminders   ReminderSettingsStore
+	admins      map[int64]bool
+	// Telegram ID самого бота, чтобы узнавать ответы на его сообщения
+	botID int64
+	// Через сколько задачи удаляются из корзины навсегда
+	trashRetention time.Duration
+}
+
+// Option настраивает необязательные зависимости Handlers
+type Option func(*Handlers)
+
+// WithLLMClient подключает LLM-клиент для обработки описаний задач
+func WithLLMClient(client llm.Client) Option {
+	return func(h *Handlers) {
+		h.llmClient = client
+	}
+}
+
+// WithLimiter подключает проверку лимитов перед каждым запросом к LLM
+func WithLimiter(l limiter.Limiter) Option {
+	return func(h *Handlers) {
+		h.limiter = l
+	}
 }
 
 // NewHandlers создает новый экземпляр Handlers
-func NewHandlers(repo repository.TaskRepository) *Handlers {
-	return &Handlers{
-		repository: repo,
+func NewHandlers(repo repository.TaskRepository, discussions repository.DiscussionRepository, opts ...Option) *Handlers {
+	h := &Handlers{
+		repository:  repo,
+		discussions: discussions,
+		undo:        newPendingStore[doneUndo](undoTTL),
+		forwards:    newPendingStore[pendingForward](forwardTTL),
+		dates:       newPendingStore[pendingDate](dateChoiceTTL),
+		admins:      make(map[int64]bool),
+
+		trashRetention: defaultTrashRetention,
 	}
+
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
 }
 
 // RegisterRoutes регистрирует все маршруты команд бота
 func (h *Handlers) RegisterRoutes(bot *telebot.Bot) {
+	if bot.Me != nil {
+		h.botID = bot.Me.ID
+	}
+	bot.Use(withRequestContext, h.trackUser)
+
 	bot.Handle("/start", h.handleStart)
 	bot.Handle("/help", h.handleHelp)
 	bot.Handle("/add", h.handleAdd)
 	bot.Handle("/list", h.handleList)
 	bot.Handle("/done", h.handleDone)
 	bot.Handle("/edit", h.handleEdit)
+	bot.Handle("/postpone", h.handlePostpone)
+	bot.Handle("/delete", h.handleDelete)
+	bot.Handle("/trash", h.handleTrash)
+	bot.Handle("/restore", h.handleRestore)
+	bot.Handle("/history", h.handleHistory)
+	bot.Handle("/tags", h.handleTags)
+	bot.Handle("/find", h.handleFind)
+	bot.Handle("/thread", h.handleThread)
+	bot.Handle("/limits", h.handleLimits)
+	bot.Handle("/reminders", h.handleReminders)
+	bot.Handle("/tz", h.handleTimeZone)
+	bot.Handle("/dateformat", h.handleDateFormat)
+	bot.Handle("/admin", h.handleAdmin)
 
 	bot.Handle(telebot.OnText, h.handleMessage)
+	bot.Handle(telebot.OnPhoto, h.handleMessage)
+	bot.Handle(telebot.OnDocument, h.handleMessage)
 
 	// Обработка неизвестных команд
 	bot.Handle(telebot.OnCallback, h.handleCallback)
@@ -57,9 +128,20 @@ func (h *Handlers) handleStart(c telebot.Context) error {
 Этот бот поможет вам управлять задачами. Доступные команды:
 
 📝 /add "Описание задачи" срок: 2025-07-15 - добавить задачу
-📋 /list - показать все активные задачи
+📋 /list [фильтр] [#тег] - показать задачи
+🔎 /find [запрос] - найти задачи
 ✅ /done [id] - отметить задачу как выполненную
 ✏️ /edit [id] новое_описание срок: ... - редактировать задачу
+⏸️ /postpone [id] до: ... - отложить задачу
+🗑 /delete [id] - удалить задачу в корзину
+♻️ /trash, /restore [id] - корзина и возврат задачи
+🕒 /history [id] - история изменений задачи
+🏷 /tags - теги задач
+💬 /thread [id] - сообщения, привязанные к задаче
+📊 /limits - оставшиеся запросы к ИИ
+🔔 /reminders - настройка напоминаний о сроках
+🌍 /tz - часовой пояс для сроков
+📅 /dateformat - порядок дня и месяца в датах
 ❓ /help - показать справку
 
 Вы также можете пересылать сообщения боту для привязки их к задачам как обсуждения.
@@ -79,25 +161,89 @@ func (h *Handlers) handleHelp(c telebot.Context) error {
 📝 Добавление задачи:
 /add "Описание задачи" срок: 2025-07-15
 Пример: /add "Купить продукты" срок: 2025-07-20
+🤖 Бот предложит улучшенное описание: «✅ Принять», «📄 Оставить исходное» или «🔄 Другой вариант»
+
+🚩 Приоритет:
+/add "Отчет" !1 или /add "Отчет" приоритет: высокий
+!1 - срочный, !2 - высокий, !3 - средний, !4 - низкий
+
+🏷 Теги:
+/add "Отчет" #работа #срочное - добавить задачу с тегами
+/edit 2 #дом - заменить теги, /edit 2 теги: - - убрать
+/tags - теги и количество задач с ними
+/tags rename #старый #новый, /tags merge #первый #второй #итоговый
+
+🔁 Повторяющиеся задачи:
+/add "Отчет" повтор: каждый понедельник
+Повтор: каждый день, каждые 3 дня, по будням, по пн и пт, 1 числа каждого месяца, FREQ=WEEKLY;BYDAY=MO
+После /done появляется следующая задача серии
 
 📋 Просмотр задач:
-/list - показать все активные задачи (отсортированы по сроку)
+/list - показать активные задачи (по приоритету, затем по сроку)
+/list done - выполненные задачи
+/list overdue - просроченные задачи
+/list postponed - отложенные задачи
+/list all - все задачи
+/list #работа - задачи с тегом, /list done #работа - вместе с фильтром
+Длинные списки разбиваются на страницы с кнопками ◀️/▶️
+
+🔎 Поиск:
+/find отчет - найти задачи по описанию и обсуждениям
+/find "квартальный отчет" - точная фраза, /find отч* - по началу слова
 
 ✅ Отметка выполнения:
 /done [id] - отметить задачу как выполненную
-Пример: /done 3
+Примеры: /done 3, /done 3 5 7, /done 3-9
+После отметки можно нажать «↩️ Отменить» в течение 5 минут
 
 ✏️ Редактирование задачи:
 /edit [id] новое_описание срок: 2025-07-25
 Пример: /edit 2 "Купить продукты и готовить ужин" срок: 2025-07-21
+/edit 2 срок: - - убрать срок
+/edit 2 статус: done - изменить статус (active, done, postponed)
+/edit 2 !1 - изменить приоритет, /edit 2 приоритет: - - убрать
+/history [id] - кто и когда изменял задачу
+
+⏸️ Отложенные задачи:
+/postpone [id] - отложить задачу без срока
+/postpone [id] до: завтра в 10:00 - задача снова станет активной в это время
+В напоминаниях есть кнопки «💤 На час», «🌅 Завтра» и «📆 Через неделю»
+
+🗑 Удаление задач:
+/delete [id] - удалить задачу (после подтверждения она попадет в корзину)
+/trash - задачи в корзине и когда они сотрутся навсегда
+/restore [id] - вернуть задачу из корзины
 
 💬 Обсуждения:
-Пересылайте сообщения боту для привязки к задачам
+Пересылайте сообщения боту и выберите задачу для привязки
+Ответьте на сообщение бота о задаче, чтобы сразу привязать к ней сообщение
+/thread [id] - показать привязанные сообщения по порядку
+
+📊 Лимиты:
+/limits - сколько запросов к ИИ осталось и когда лимит обновится
+
+🔔 Напоминания:
+Бот напоминает о сроке задачи заранее и сообщает о просрочке
+/reminders - текущие настройки
+/reminders 1д 3ч - напоминать за 1 день и за 3 часа до срока
+/reminders off - выключить, /reminders on - включить
+
+🌍 Часовой пояс:
+/tz - текущий часовой пояс
+/tz Europe/Moscow - сроки вводятся и показываются в этом поясе
+
+📅 Формат дат:
+/dateformat dmy - 03/04 означает 3 апреля
+/dateformat mdy - 03/04 означает 4 марта
+Пока формат не выбран, бот переспросит, если дату можно понять по-разному
 
 📊 Форматы дат:
 - 2025-07-15 (YYYY-MM-DD)
 - 15.07.2025 (DD.MM.YYYY)
-- 15/07/2025 (DD/MM/YYYY)
+- 15/07/2025 (DD/MM/YYYY) или 07/15/2025 (MM/DD/YYYY, см. /dateformat)
+- 15.07 (DD.MM, ближайшая такая дата)
+- 2025-07-15 15:30 или 15.07 в 15:30 (со временем)
+- завтра, послезавтра, через 3 дня, в пятницу, до конца недели, 15 июля
 
 ❓ /help - показать эту справку
 `
@@ -119,87 +265,107 @@ func (h *Handlers) handleAdd(c telebot.Context) error {
 			return c.Send("❌ Пустая команда. Используйте: /add \"Описание задачи\" срок: 2025-07-15")
 		}
 
-		// Parse the command
-		input, err := utils.ParseAddCommand(text)
-		if err != nil {
-			h.logUserAction(userID, "add_task_error", fmt.Sprintf("Parse error: %v", err))
-			return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s\n\nПример: /add \"Купить продукты\" срок: 2025-07-20", err.Error()))
-		}
+		return h.addTask(c, userID, text)
+	})
+}
 
-		// Additional validation
-		if err := utils.ValidateDescription(input.Description); err != nil {
-			h.logUserAction(userID, "add_task_error", fmt.Sprintf("Validation error: %v", err))
-			return c.Send(fmt.Sprintf("❌ %s", err.Error()))
-		}
+// addTask создает задачу по тексту команды /add.
+// Если срок можно понять по-разному, пользователю предлагается выбрать дату.
+func (h *Handlers) addTask(c telebot.Context, userID int64, text string) error {
+	ctx := requestContext(c)
+
+	// Parse the command
+	user := h.userSettings(ctx, userID)
+	loc := user.Location()
+	input, err := utils.ParseAddCommand(text, time.Now().In(loc), user.DateOrder)
+	var ambiguous *utils.AmbiguousDateError
+	if errors.As(err, &ambiguous) {
+		return h.askDate(c, userID, text, "срок", h.addTask, ambiguous)
+	}
+	if err != nil {
+		h.logUserAction(userID, "add_task_error", fmt.Sprintf("Parse error: %v", err))
+		return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s\n\nПример: /add \"Купить продукты\" срок: 2025-07-20", err.Error()))
+	}
 
-		// Create the task
-		task := &models.Task{
-			UserID:              int(userID),
-			OriginalDescription: input.Description,
-			Status:              models.StatusActive,
-		}
+	// Additional validation
+	if err := utils.ValidateDescription(input.Description); err != nil {
+		h.logUserAction(userID, "add_task_error", fmt.Sprintf("Validation error: %v", err))
+		return c.Send(fmt.Sprintf("❌ %s", err.Error()))
+	}
 
-		if input.HasDeadline {
-			task.Deadline = input.Deadline
-		}
+	// Create the task
+	task := &models.Task{
+		UserID:              int(userID),
+		OriginalDescription: input.Description,
+		Status:              models.StatusActive,
+		Priority:            input.Priority,
+		Tags:                input.Tags,
+	}
 
-		// Save to database
-		if err := h.repository.AddTask(task); err != nil {
-			h.logUserAction(userID, "add_task_error", fmt.Sprintf("Database error: %v", err))
-			return c.Send("❌ Не удалось сохранить задачу. Попробуйте позже.")
-		}
+	if input.HasDeadline {
+		task.Deadline = input.Deadline
+		task.DeadlineHasTime = input.HasTime
+	}
+	if input.Recurrence != nil {
+		task.Recurrence = input.Recurrence.String()
+	}
 
-		// Log successful action
-		h.logUserAction(userID, "add_task", fmt.Sprintf("Task ID: %d, Description: %s", task.ID, task.OriginalDescription))
+	// Save to database
+	if err := h.repository.AddTask(ctx, task); err != nil {
+		h.logUserAction(userID, "add_task_error", fmt.Sprintf("Database error: %v", err))
+		return c.Send("❌ Не удалось сохранить задачу. Попробуйте позже.")
+	}
 
-		// Format success message
-		successMsg := fmt.Sprintf("✅ Задача добавлена!\n\n📝 ID: %d\n📄 Описание: %s", task.ID, task.OriginalDescription)
+	// Ask the LLM for a clarified description of the saved task; on failure the original is kept
+	rewritten, rewriteErr := h.rewriteDescription(ctx, userID, task)
 
-		if task.HasDeadline() {
-			successMsg += fmt.Sprintf("\n⏰ Срок: %s", task.Deadline.Format("02.01.2006"))
-		}
+	// Log successful action
+	h.logUserAction(userID, "add_task", fmt.Sprintf("Task ID: %d, Description: %s", task.ID, task.OriginalDescription))
 
-		return c.Send(successMsg)
-	})
-}
+	// Format success message
+	successMsg := fmt.Sprintf("✅ Задача добавлена!\n\n📝 ID: %d\n📄 Описание: %s", task.ID, task.OriginalDescription)
 
-// handleList обрабатывает команду /list
-func (h *Handlers) handleList(c telebot.Context) error {
-	return h.safeHandle(c, func() error {
-		// TODO: Реализовать получение списка задач
-		// Здесь будет получение задач из БД и форматирование вывода
-		return c.Send("🚧 Функция просмотра задач в разработке")
-	})
-}
+	if rewritten {
+		successMsg += fmt.Sprintf("\n✨ Улучшенное описание: %s", task.LLMProcessedDesc)
+	}
 
-// handleDone обрабатывает команду /done
-func (h *Handlers) handleDone(c telebot.Context) error {
-	return h.safeHandle(c, func() error {
-		// TODO: Реализовать отметку задачи как выполненной
-		// Здесь будет парсинг ID задачи и обновление статуса в БД
-		return c.Send("🚧 Функция отметки выполнения в разработке")
-	})
-}
+	if task.HasDeadline() {
+		successMsg += fmt.Sprintf("\n⏰ Срок: %s", utils.FormatDeadline(task.Deadline.In(loc), task.DeadlineHasTime))
+	}
 
-// handleEdit обрабатывает команду /edit
-func (h *Handlers) handleEdit(c telebot.Context) error {
-	return h.safeHandle(c, func() error {
-		// TODO: Реализовать редактирование задачи
-		// Здесь будет парсинг аргументов, вызов LLM API и обновление в БД
-		return c.Send("🚧 Функция редактирования задач в разработке")
-	})
+	if task.HasPriority() {
+		successMsg += fmt.Sprintf("\n🚩 Приоритет: %s", utils.FormatPriority(task.Priority))
+	}
+
+	if len(task.Tags) > 0 {
+		successMsg += fmt.Sprintf("\n🏷 Теги: %s", utils.FormatTags(task.Tags))
+	}
+
+	if task.IsRecurring() {
+		successMsg += fmt.Sprintf("\n🔁 Повтор: %s", utils.FormatRecurrence(task.Recurrence))
+	}
+
+	if rewriteErr != nil {
+		successMsg += "\n\n" + rewriteWarning(rewriteErr)
+	}
+
+	if rewritten {
+		return c.Send(successMsg, rewriteKeyboard(task.ID))
+	}
+	return c.Send(successMsg)
 }
 
 // handleMessage обрабатывает текстовые сообщения (пересылаемые сообщения)
 func (h *Handlers) handleMessage(c telebot.Context) error {
 	return h.safeHandle(c, func() error {
-		// TODO: Реализовать обработку пересылаемых сообщений
-		// Здесь будет логика привязки обсуждений к задачам
-
-		// Пока что просто игнорируем обычные текстовые сообщения
-		// и обрабатываем только пересылаемые
-		if c.Message().IsForwarded() {
-			return c.Send("🚧 Функция обработки пересылаемых сообщений в разработке")
+		// Пересланные сообщения и ответы на сообщения бота о задаче
+		// привязываются к задачам как обсуждения
+		msg := c.Message()
+		if isForwarded(msg) {
+			return h.handleForward(c)
+		}
+		if _, ok := h.repliedTaskID(msg); ok {
+			return h.handleForward(c)
 		}
 
 		// Если это обычное сообщение, предлагаем помощь
@@ -210,11 +376,28 @@ func (h *Handlers) handleMessage(c telebot.Context) error {
 // handleCallback обрабатывает inline-кнопки
 func (h *Handlers) handleCallback(c telebot.Context) error {
 	return h.safeHandle(c, func() error {
-		// TODO: Реализовать обработку inline-кнопок
-		// Здесь будет логика для быстрых действий через кнопки
-		return c.Respond(&telebot.CallbackResponse{
-			Text: "🚧 Функция в разработке",
-		})
+		action, args := parseCallbackData(c.Callback().Data)
+
+		switch action {
+		case callbackList:
+			return h.handleListCallback(c, args)
+		case callbackUndoDone:
+			return h.handleUndoDoneCallback(c, args)
+		case callbackAttach:
+			return h.handleAttachCallback(c, args)
+		case callbackRewrite:
+			return h.handleRewriteCallback(c, args)
+		case callbackDate:
+			return h.handleDateCallback(c, args)
+		case callbackSnooze:
+			return h.handleSnoozeCallback(c, args)
+		case callbackTrash:
+			return h.handleTrashCallback(c, args)
+		default:
+			return c.Respond(&telebot.CallbackResponse{
+				Text: "🚧 Функция в разработке",
+			})
+		}
 	})
 }
 
@@ -252,6 +435,26 @@ func (h *Handlers) validateCommand(args []string, minArgs int) error {
 	return nil
 }
 
+// withRequestContext создает для каждого обновления контекст с таймаутом и отменяет его,
+// когда обработка закончена: запросы к базе данных и LLM не переживают свое обновление
+func withRequestContext(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		defer cancel()
+
+		c.Set(requestContextKey, ctx)
+		return next(c)
+	}
+}
+
+// requestContext возвращает контекст обновления; вне withRequestContext - context.Background()
+func requestContext(c telebot.Context) context.Context {
+	if ctx, ok := c.Get(requestContextKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
 // getUserID получает ID пользователя из контекста
 func (h *Handlers) getUserID(c telebot.Context) int64 {
 	if c.Sender() != nil {
@@ -260,6 +463,23 @@ func (h *Handlers) getUserID(c telebot.Context) int64 {
 	return 0
 }
 
+// errTaskNotOwned возвращается, если задача принадлежит другому пользователю
+var errTaskNotOwned = errors.New("task belongs to another user")
+
+// getUserTask получает задачу по ID и проверяет, что она принадлежит пользователю
+func (h *Handlers) getUserTask(ctx context.Context, userID int64, taskID int) (*models.Task, error) {
+	task, err := h.repository.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	if int64(task.UserID) != userID {
+		return nil, errTaskNotOwned
+	}
+
+	return task, nil
+}
+
 // logUserAction логирует действие пользователя
 func (h *Handlers) logUserAction(userID int64, action string, details string) {
 	log.Printf("User %d: %s - %s", userID, action, details)
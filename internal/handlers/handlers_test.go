@@ -1,29 +1,393 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
 	"testing"
+	"time"
 
 	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/telebot.v3"
 )
 
-// mockTaskRepository is a simple mock for testing
-type mockTaskRepository struct{}
+// mockTaskRepository is a simple in-memory mock for testing
+type mockTaskRepository struct {
+	tasks   map[int]*models.Task
+	nextID  int
+	history []*models.TaskChange
+	// addErr and updateErr are returned by AddTask and UpdateTask when set
+	addErr    error
+	updateErr error
+}
+
+func newMockTaskRepository() *mockTaskRepository {
+	return &mockTaskRepository{tasks: make(map[int]*models.Task), nextID: 1}
+}
+
+func (m *mockTaskRepository) AddTask(ctx context.Context, task *models.Task) error {
+	if m.addErr != nil {
+		return m.addErr
+	}
+	if err := task.Validate(); err != nil {
+		return err
+	}
+	task.SetDefaults()
+	task.ID = m.nextID
+	m.nextID++
+	stored := *task
+	m.tasks[task.ID] = &stored
+	return nil
+}
+
+func (m *mockTaskRepository) GetTask(ctx context.Context, id int) (*models.Task, error) {
+	task, ok := m.tasks[id]
+	if !ok || task.IsDeleted() {
+		return nil, fmt.Errorf("%w: id %d", repository.ErrTaskNotFound, id)
+	}
+	result := *task
+	return &result, nil
+}
+
+func (m *mockTaskRepository) UpdateTask(ctx context.Context, task *models.Task) error {
+	if m.updateErr != nil {
+		return m.updateErr
+	}
+	if stored, ok := m.tasks[task.ID]; !ok || stored.IsDeleted() {
+		return fmt.Errorf("%w: id %d", repository.ErrTaskNotFound, task.ID)
+	}
+	stored := *task
+	m.tasks[task.ID] = &stored
+	return nil
+}
+
+func (m *mockTaskRepository) CompleteTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	task.Status = models.StatusDone
+	if err := m.UpdateTask(ctx, task); err != nil {
+		return nil, err
+	}
+
+	next, err := task.NextOccurrence(time.Local, time.Now())
+	if err != nil || next == nil {
+		return nil, err
+	}
+	if err := m.AddTask(ctx, next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+func (m *mockTaskRepository) DeleteTask(ctx context.Context, id int) error {
+	if _, ok := m.tasks[id]; !ok {
+		return fmt.Errorf("%w: id %d", repository.ErrTaskNotFound, id)
+	}
+	delete(m.tasks, id)
+	return nil
+}
+
+func (m *mockTaskRepository) TrashTask(ctx context.Context, id int) error {
+	task, ok := m.tasks[id]
+	if !ok || task.IsDeleted() {
+		return fmt.Errorf("%w: id %d", repository.ErrTaskNotFound, id)
+	}
+	task.DeletedAt = time.Now()
+	return nil
+}
+
+func (m *mockTaskRepository) RestoreTask(ctx context.Context, id int) error {
+	task, ok := m.tasks[id]
+	if !ok || !task.IsDeleted() {
+		return fmt.Errorf("%w: id %d", repository.ErrTaskNotFound, id)
+	}
+	task.DeletedAt = time.Time{}
+	return nil
+}
+
+func (m *mockTaskRepository) GetTrashedTasks(ctx context.Context, userID int) ([]*models.Task, error) {
+	var result []*models.Task
+	for id := m.nextID - 1; id > 0; id-- {
+		if task, ok := m.tasks[id]; ok && task.UserID == userID && task.IsDeleted() {
+			copied := *task
+			result = append(result, &copied)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTaskRepository) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for id, task := range m.tasks {
+		if task.IsDeleted() && task.DeletedAt.Before(before) {
+			delete(m.tasks, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (m *mockTaskRepository) GetTasksByUser(ctx context.Context, userID int) ([]*models.Task, error) {
+	return m.filter(func(task *models.Task) bool { return task.UserID == userID }), nil
+}
+
+func (m *mockTaskRepository) GetActiveTasks(ctx context.Context, userID int) ([]*models.Task, error) {
+	return m.GetTasksByStatus(ctx, userID, models.StatusActive)
+}
+
+func (m *mockTaskRepository) GetTasksByStatus(ctx context.Context, userID int, status string) ([]*models.Task, error) {
+	return m.filter(func(task *models.Task) bool { return task.UserID == userID && task.Status == status }), nil
+}
+
+func (m *mockTaskRepository) GetOverdueTasks(ctx context.Context, userID int) ([]*models.Task, error) {
+	return m.filter(func(task *models.Task) bool { return task.UserID == userID && task.IsOverdue() }), nil
+}
+
+func (m *mockTaskRepository) GetTasksByTag(ctx context.Context, userID int, tag string) ([]*models.Task, error) {
+	return m.filter(func(task *models.Task) bool { return task.UserID == userID && task.HasTag(tag) }), nil
+}
+
+func (m *mockTaskRepository) ListTags(ctx context.Context, userID int) ([]models.TagCount, error) {
+	counts := make(map[string]*models.TagCount)
+	for _, task := range m.filter(func(task *models.Task) bool { return task.UserID == userID }) {
+		for _, tag := range task.Tags {
+			if counts[tag] == nil {
+				counts[tag] = &models.TagCount{Name: tag}
+			}
+			counts[tag].Tasks++
+			if task.IsActive() {
+				counts[tag].Active++
+			}
+		}
+	}
+
+	tags := make([]models.TagCount, 0, len(counts))
+	for _, count := range counts {
+		tags = append(tags, *count)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+func (m *mockTaskRepository) RenameTag(ctx context.Context, userID int, from, to string) (bool, error) {
+	found, merged := false, false
+	for _, task := range m.tasks {
+		if task.UserID != userID {
+			continue
+		}
+		found = found || task.HasTag(from)
+		merged = merged || task.HasTag(to)
+	}
+	if !found {
+		return false, fmt.Errorf("%w: %s", repository.ErrTagNotFound, from)
+	}
+
+	for _, task := range m.tasks {
+		if task.UserID == userID && task.HasTag(from) {
+			tags := []string{to}
+			for _, tag := range task.Tags {
+				if tag != from {
+					tags = append(tags, tag)
+				}
+			}
+			task.Tags, _ = models.NormalizeTags(tags)
+		}
+	}
+	return merged, nil
+}
+
+func (m *mockTaskRepository) SearchTasks(ctx context.Context, userID int, query string, limit int) ([]models.SearchResult, error) {
+	query = strings.ToLower(strings.Trim(strings.TrimSpace(query), `"*`))
+	if query == "" {
+		return nil, repository.ErrEmptySearchQuery
+	}
+
+	var results []models.SearchResult
+	for _, task := range m.filter(func(task *models.Task) bool {
+		return task.UserID == userID && strings.Contains(strings.ToLower(task.GetDescription()), query)
+	}) {
+		results = append(results, models.SearchResult{Task: task, Snippet: task.GetDescription(), Rank: 1})
+	}
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// WithTx runs fn on the mock and restores the tasks if fn fails
+func (m *mockTaskRepository) WithTx(ctx context.Context, fn func(repo repository.TaskRepository) error) error {
+	tasks := make(map[int]*models.Task, len(m.tasks))
+	for id, task := range m.tasks {
+		copied := *task
+		tasks[id] = &copied
+	}
+	nextID, history := m.nextID, len(m.history)
+
+	if err := fn(m); err != nil {
+		m.tasks, m.nextID, m.history = tasks, nextID, m.history[:history]
+		return err
+	}
+	return nil
+}
+
+func (m *mockTaskRepository) AddTaskChanges(ctx context.Context, changes []*models.TaskChange) error {
+	for _, change := range changes {
+		change.SetDefaults()
+		change.ID = len(m.history) + 1
+		m.history = append(m.history, change)
+	}
+	return nil
+}
 
-func (m *mockTaskRepository) AddTask(task *models.Task) error                   { return nil }
-func (m *mockTaskRepository) GetTask(id int) (*models.Task, error)              { return nil, nil }
-func (m *mockTaskRepository) UpdateTask(task *models.Task) error                { return nil }
-func (m *mockTaskRepository) DeleteTask(id int) error                           { return nil }
-func (m *mockTaskRepository) GetTasksByUser(userID int) ([]*models.Task, error) { return nil, nil }
-func (m *mockTaskRepository) GetActiveTasks(userID int) ([]*models.Task, error) { return nil, nil }
-func (m *mockTaskRepository) GetTasksByStatus(userID int, status string) ([]*models.Task, error) {
-	return nil, nil
+func (m *mockTaskRepository) GetTaskHistory(ctx context.Context, taskID int) ([]*models.TaskChange, error) {
+	var result []*models.TaskChange
+	for _, change := range m.history {
+		if change.TaskID == taskID {
+			result = append(result, change)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockTaskRepository) filter(match func(task *models.Task) bool) []*models.Task {
+	var result []*models.Task
+	for id := 1; id < m.nextID; id++ {
+		if task, ok := m.tasks[id]; ok && !task.IsDeleted() && match(task) {
+			copied := *task
+			result = append(result, &copied)
+		}
+	}
+	return result
+}
+
+// fakeContext implements the parts of telebot.Context used by the handlers
+type fakeContext struct {
+	telebot.Context
+	sender    *telebot.User
+	message   *telebot.Message
+	callback  *telebot.Callback
+	sent      []string
+	markups   []*telebot.ReplyMarkup
+	edited    []string
+	responses []*telebot.CallbackResponse
+	store     map[string]interface{}
+}
+
+func newCommandContext(userID int64, text, payload string) *fakeContext {
+	return &fakeContext{
+		sender:  &telebot.User{ID: userID, FirstName: "Test"},
+		message: &telebot.Message{ID: 1, Text: text, Payload: payload},
+	}
+}
+
+func newCallbackContext(userID int64, data string) *fakeContext {
+	return &fakeContext{
+		sender:   &telebot.User{ID: userID, FirstName: "Test"},
+		message:  &telebot.Message{ID: 1},
+		callback: &telebot.Callback{Data: data},
+	}
+}
+
+func (c *fakeContext) Sender() *telebot.User       { return c.sender }
+func (c *fakeContext) Message() *telebot.Message   { return c.message }
+func (c *fakeContext) Callback() *telebot.Callback { return c.callback }
+func (c *fakeContext) Text() string                { return c.message.Text }
+
+func (c *fakeContext) Get(key string) interface{} { return c.store[key] }
+
+func (c *fakeContext) Set(key string, value interface{}) {
+	if c.store == nil {
+		c.store = make(map[string]interface{})
+	}
+	c.store[key] = value
+}
+
+func (c *fakeContext) Send(what interface{}, opts ...interface{}) error {
+	c.sent = append(c.sent, fmt.Sprint(what))
+	c.markups = append(c.markups, findMarkup(opts))
+	return nil
+}
+
+func (c *fakeContext) Edit(what interface{}, opts ...interface{}) error {
+	c.edited = append(c.edited, fmt.Sprint(what))
+	c.markups = append(c.markups, findMarkup(opts))
+	return nil
+}
+
+func (c *fakeContext) Respond(resp ...*telebot.CallbackResponse) error {
+	c.responses = append(c.responses, resp...)
+	return nil
+}
+
+func (c *fakeContext) lastSent() string {
+	if len(c.sent) == 0 {
+		return ""
+	}
+	return c.sent[len(c.sent)-1]
+}
+
+func (c *fakeContext) lastMarkup() *telebot.ReplyMarkup {
+	if len(c.markups) == 0 {
+		return nil
+	}
+	return c.markups[len(c.markups)-1]
+}
+
+func findMarkup(opts []interface{}) *telebot.ReplyMarkup {
+	for _, opt := range opts {
+		if markup, ok := opt.(*telebot.ReplyMarkup); ok {
+			return markup
+		}
+	}
+	return nil
+}
+
+// mockDiscussionRepository is a simple in-memory mock for testing
+type mockDiscussionRepository struct {
+	discussions []*models.Discussion
+}
+
+func (m *mockDiscussionRepository) AddDiscussion(ctx context.Context, discussion *models.Discussion) error {
+	if err := discussion.Validate(); err != nil {
+		return err
+	}
+	discussion.SetDefaults()
+	discussion.ID = len(m.discussions) + 1
+	stored := *discussion
+	m.discussions = append(m.discussions, &stored)
+	return nil
+}
+
+func (m *mockDiscussionRepository) GetDiscussionsByTask(ctx context.Context, taskID int) ([]*models.Discussion, error) {
+	var result []*models.Discussion
+	for _, discussion := range m.discussions {
+		if discussion.TaskID == taskID {
+			result = append(result, discussion)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockDiscussionRepository) DeleteDiscussion(ctx context.Context, id int) error {
+	for i, discussion := range m.discussions {
+		if discussion.ID == id {
+			m.discussions = append(m.discussions[:i], m.discussions[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: id %d", repository.ErrDiscussionNotFound, id)
+}
+
+// newTestHandlers creates handlers wired to in-memory mocks
+func newTestHandlers(repo *mockTaskRepository) *Handlers {
+	return NewHandlers(repo, &mockDiscussionRepository{})
 }
-func (m *mockTaskRepository) GetOverdueTasks(userID int) ([]*models.Task, error) { return nil, nil }
 
 func createTestHandlers() *Handlers {
-	return NewHandlers(&mockTaskRepository{})
+	return newTestHandlers(newMockTaskRepository())
 }
 
 // TestNewHandlers тестирует создание экземпляра Handlers
@@ -142,9 +506,18 @@ func TestMessageContent(t *testing.T) {
 Этот бот поможет вам управлять задачами. Доступные команды:
 
 📝 /add "Описание задачи" срок: 2025-07-15 - добавить задачу
-📋 /list - показать все активные задачи
+📋 /list [фильтр] [#тег] - показать задачи
+🔎 /find [запрос] - найти задачи
 ✅ /done [id] - отметить задачу как выполненную
 ✏️ /edit [id] новое_описание срок: ... - редактировать задачу
+⏸️ /postpone [id] до: ... - отложить задачу
+🕒 /history [id] - история изменений задачи
+🏷 /tags - теги задач
+💬 /thread [id] - сообщения, привязанные к задаче
+📊 /limits - оставшиеся запросы к ИИ
+🔔 /reminders - настройка напоминаний о сроках
+🌍 /tz - часовой пояс для сроков
+📅 /dateformat - порядок дня и месяца в датах
 ❓ /help - показать справку
 
 Вы также можете пересылать сообщения боту для привязки их к задачам как обсуждения.
@@ -178,25 +551,84 @@ func TestMessageContent(t *testing.T) {
 📝 Добавление задачи:
 /add "Описание задачи" срок: 2025-07-15
 Пример: /add "Купить продукты" срок: 2025-07-20
+🤖 Бот предложит улучшенное описание: «✅ Принять», «📄 Оставить исходное» или «🔄 Другой вариант»
+
+🚩 Приоритет:
+/add "Отчет" !1 или /add "Отчет" приоритет: высокий
+!1 - срочный, !2 - высокий, !3 - средний, !4 - низкий
+
+🏷 Теги:
+/add "Отчет" #работа #срочное - добавить задачу с тегами
+/edit 2 #дом - заменить теги, /edit 2 теги: - - убрать
+/tags - теги и количество задач с ними
+/tags rename #старый #новый, /tags merge #первый #второй #итоговый
+
+🔁 Повторяющиеся задачи:
+/add "Отчет" повтор: каждый понедельник
+Повтор: каждый день, каждые 3 дня, по будням, по пн и пт, 1 числа каждого месяца, FREQ=WEEKLY;BYDAY=MO
+После /done появляется следующая задача серии
 
 📋 Просмотр задач:
-/list - показать все активные задачи (отсортированы по сроку)
+/list - показать активные задачи (по приоритету, затем по сроку)
+/list done - выполненные задачи
+/list overdue - просроченные задачи
+/list postponed - отложенные задачи
+/list all - все задачи
+/list #работа - задачи с тегом, /list done #работа - вместе с фильтром
+Длинные списки разбиваются на страницы с кнопками ◀️/▶️
+
+🔎 Поиск:
+/find отчет - найти задачи по описанию и обсуждениям
+/find "квартальный отчет" - точная фраза, /find отч* - по началу слова
 
 ✅ Отметка выполнения:
 /done [id] - отметить задачу как выполненную
-Пример: /done 3
+Примеры: /done 3, /done 3 5 7, /done 3-9
+После отметки можно нажать «↩️ Отменить» в течение 5 минут
 
 ✏️ Редактирование задачи:
 /edit [id] новое_описание срок: 2025-07-25
 Пример: /edit 2 "Купить продукты и готовить ужин" срок: 2025-07-21
+/edit 2 срок: - - убрать срок
+/edit 2 статус: done - изменить статус (active, done, postponed)
+/edit 2 !1 - изменить приоритет, /edit 2 приоритет: - - убрать
+/history [id] - кто и когда изменял задачу
+
+⏸️ Отложенные задачи:
+/postpone [id] - отложить задачу без срока
+/postpone [id] до: завтра в 10:00 - задача снова станет активной в это время
+В напоминаниях есть кнопки «💤 На час», «🌅 Завтра» и «📆 Через неделю»
 
 💬 Обсуждения:
-Пересылайте сообщения боту для привязки к задачам
+Пересылайте сообщения боту и выберите задачу для привязки
+Ответьте на сообщение бота о задаче, чтобы сразу привязать к ней сообщение
+/thread [id] - показать привязанные сообщения по порядку
+
+📊 Лимиты:
+/limits - сколько запросов к ИИ осталось и когда лимит обновится
+
+🔔 Напоминания:
+Бот напоминает о сроке задачи заранее и сообщает о просрочке
+/reminders - текущие настройки
+/reminders 1д 3ч - напоминать за 1 день и за 3 часа до срока
+/reminders off - выключить, /reminders on - включить
+
+🌍 Часовой пояс:
+/tz - текущий часовой пояс
+/tz Europe/Moscow - сроки вводятся и показываются в этом поясе
+
+📅 Формат дат:
+/dateformat dmy - 03/04 означает 3 апреля
+/dateformat mdy - 03/04 означает 4 марта
+Пока формат не выбран, бот переспросит, если дату можно понять по-разному
 
 📊 Форматы дат:
 - 2025-07-15 (YYYY-MM-DD)
 - 15.07.2025 (DD.MM.YYYY)
-- 15/07/2025 (DD/MM/YYYY)
+- 15/07/2025 (DD/MM/YYYY) или 07/15/2025 (MM/DD/YYYY, см. /dateformat)
+- 15.07 (DD.MM, ближайшая такая дата)
+- 2025-07-15 15:30 или 15.07 в 15:30 (со временем)
+- завтра, послезавтра, через 3 дня, в пятницу, до конца недели, 15 июля
 
 ❓ /help - показать эту справку
 `
@@ -230,3 +662,20 @@ func TestInDevelopmentMessages(t *testing.T) {
 	assert.Contains(t, developmentMessage, "🚧", "Сообщение о разработке должно содержать эмодзи")
 	assert.Contains(t, developmentMessage, "разработке", "Сообщение должно указывать на разработку")
 }
+
+// TestRequestContext проверяет, что каждое обновление получает свой контекст с таймаутом
+func TestRequestContext(t *testing.T) {
+	c := newCommandContext(1, "/list", "")
+	assert.Equal(t, context.Background(), requestContext(c))
+
+	var handled context.Context
+	err := withRequestContext(func(c telebot.Context) error {
+		handled = requestContext(c)
+		return nil
+	})(c)
+	require.NoError(t, err)
+
+	_, hasDeadline := handled.Deadline()
+	assert.True(t, hasDeadline)
+	assert.ErrorIs(t, handled.Err(), context.Canceled, "the context is cancelled once the update is handled")
+}
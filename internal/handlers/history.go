@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// changeFieldNames содержит названия полей задачи для истории
+var changeFieldNames = map[string]string{
+	models.FieldDescription: "описание",
+	models.FieldDeadline:    "срок",
+	models.FieldStatus:      "статус",
+	models.FieldPriority:    "приоритет",
+	models.FieldTags:        "теги",
+}
+
+// handleHistory обрабатывает команду /history
+func (h *Handlers) handleHistory(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		args := utils.SplitCommandArgs(c.Message().Payload)
+		if err := h.validateCommand(args, 1); err != nil {
+			return c.Send("❌ Укажите ID задачи. Пример: /history 3")
+		}
+
+		taskID, err := utils.ParseTaskID(args[0])
+		if err != nil {
+			return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s", err.Error()))
+		}
+
+		task, err := h.getUserTask(ctx, userID, taskID)
+		if err != nil {
+			return c.Send(taskAccessError(taskID, err))
+		}
+
+		changes, err := h.repository.GetTaskHistory(ctx, task.ID)
+		if err != nil {
+			h.logUserAction(userID, "history_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить историю. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "history", fmt.Sprintf("Task ID: %d", task.ID))
+
+		if len(changes) == 0 {
+			return c.Send(fmt.Sprintf("🕒 У задачи %d пока нет изменений", task.ID))
+		}
+
+		var builder strings.Builder
+		builder.WriteString(fmt.Sprintf("🕒 История задачи %d:\n", task.ID))
+		for _, change := range changes {
+			builder.WriteString("\n" + formatTaskChange(change, userID, h.userLocation(ctx, userID)))
+		}
+
+		return c.Send(builder.String())
+	})
+}
+
+// recordChanges сохраняет в истории изменения задачи, сделанные пользователем.
+// Ошибка записи истории не отменяет само изменение, поэтому она только логируется.
+func (h *Handlers) recordChanges(ctx context.Context, userID int64, before, after *models.Task) []*models.TaskChange {
+	changes := models.DiffTasks(before, after, int(userID))
+	if len(changes) == 0 {
+		return nil
+	}
+
+	if err := h.repository.AddTaskChanges(ctx, changes); err != nil {
+		h.logUserAction(userID, "history_error", fmt.Sprintf("Task ID: %d, Database error: %v", after.ID, err))
+	}
+
+	return changes
+}
+
+// formatTaskChange форматирует запись истории: когда, кто и что изменил, в поясе loc
+func formatTaskChange(change *models.TaskChange, viewerID int64, loc *time.Location) string {
+	author := fmt.Sprintf("пользователь %d", change.UserID)
+	if int64(change.UserID) == viewerID {
+		author = "вы"
+	}
+
+	return fmt.Sprintf("%s, %s: %s",
+		change.ChangedAt.In(loc).Format("02.01.2006 15:04"),
+		author,
+		formatChangeValues(change, loc),
+	)
+}
+
+// formatChangeValues форматирует изменение поля в виде "поле: старое → новое"
+func formatChangeValues(change *models.TaskChange, loc *time.Location) string {
+	name, ok := changeFieldNames[change.Field]
+	if !ok {
+		name = change.Field
+	}
+
+	return fmt.Sprintf("%s: %s → %s",
+		name,
+		formatChangeValue(change.Field, change.OldValue, loc),
+		formatChangeValue(change.Field, change.NewValue, loc),
+	)
+}
+
+// formatChangeValue форматирует значение поля для вывода
+func formatChangeValue(field, value string, loc *time.Location) string {
+	if value == "" {
+		return "—"
+	}
+
+	if field == models.FieldPriority {
+		if priority, err := strconv.Atoi(value); err == nil {
+			return utils.FormatPriority(priority)
+		}
+	}
+
+	if field == models.FieldTags {
+		return utils.FormatTags(strings.Fields(value))
+	}
+
+	if field == models.FieldDeadline {
+		if deadline, hasTime, err := models.ParseDeadlineValue(value); err == nil {
+			return utils.FormatDeadline(deadline.In(loc), hasTime)
+		}
+	}
+
+	return value
+}
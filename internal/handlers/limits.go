@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+// handleLimits обрабатывает команду /limits
+func (h *Handlers) handleLimits(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		if h.limiter == nil {
+			return c.Send("ℹ️ Лимиты запросов к ИИ не настроены")
+		}
+
+		limit, err := h.limiter.Status(requestContext(c), userID)
+		if err != nil {
+			h.logUserAction(userID, "limits_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить лимиты. Попробуйте позже.")
+		}
+
+		lines := append([]string{"📊 Лимиты запросов к ИИ", ""}, formatQuota(limit, h.limiter.Policy(), h.userLocation(requestContext(c), userID))...)
+		return c.Send(strings.Join(lines, "\n"))
+	})
+}
+
+// formatQuota описывает тариф и остаток запросов пользователя, время показывается в поясе loc
+func formatQuota(limit *models.APILimit, policy models.QuotaPolicy, loc *time.Location) []string {
+	rule := limit.Rule(policy)
+
+	lines := []string{"Тариф: " + tierName(limit.GetTier())}
+	if rule.IsUnlimited() {
+		return append(lines, "♾️ Запросы без ограничений")
+	}
+
+	return append(lines,
+		fmt.Sprintf("Использовано: %d из %d %s", limit.RequestsCount, rule.Limit, periodName(rule)),
+		fmt.Sprintf("Осталось: %d", limit.GetRemainingRequests(policy)),
+		fmt.Sprintf("🔄 Обновление: %s", limit.ResetDate.In(loc).Format("02.01.2006 15:04")),
+	)
+}
+
+// tierName возвращает название тарифа для пользователя
+func tierName(tier string) string {
+	switch tier {
+	case models.TierPremium:
+		return "⭐ премиум"
+	case models.TierAdmin:
+		return "🛡 администратор"
+	default:
+		return "бесплатный"
+	}
+}
+
+// periodName описывает период лимита для пользователя
+func periodName(rule models.QuotaRule) string {
+	switch rule.Period {
+	case models.PeriodDay:
+		return "в день"
+	case models.PeriodWeek:
+		return "в неделю"
+	case models.PeriodMonth:
+		return "в месяц"
+	default:
+		return fmt.Sprintf("за %s", formatWindow(rule.Window))
+	}
+}
+
+// formatWindow форматирует длину скользящего окна в часах или днях
+func formatWindow(window time.Duration) string {
+	if window%(24*time.Hour) == 0 {
+		return fmt.Sprintf("%d дн.", int(window/(24*time.Hour)))
+	}
+	return fmt.Sprintf("%d ч.", int(window.Round(time.Hour)/time.Hour))
+}
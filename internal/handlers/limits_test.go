@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/limiter"
+	"telegram-bot-assistente/internal/llm/llmtest"
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockLimiter is a simple in-memory limiter for testing
+type mockLimiter struct {
+	limits map[int64]*models.APILimit
+}
+
+func newMockLimiter() *mockLimiter {
+	return &mockLimiter{limits: make(map[int64]*models.APILimit)}
+}
+
+func (m *mockLimiter) get(userID int64) *models.APILimit {
+	limit, ok := m.limits[userID]
+	if !ok {
+		limit = &models.APILimit{UserID: int(userID), ResetDate: time.Now().Add(24 * time.Hour)}
+		m.limits[userID] = limit
+	}
+	return limit
+}
+
+func (m *mockLimiter) Allow(_ context.Context, userID int64) (*models.APILimit, error) {
+	limit := m.get(userID)
+	if !limit.CanMakeRequest(m.Policy()) {
+		return limit, limiter.ErrLimitExceeded
+	}
+	limit.IncrementRequests()
+	return limit, nil
+}
+
+func (m *mockLimiter) Refund(_ context.Context, userID int64, allowed *models.APILimit) (*models.APILimit, error) {
+	limit := m.get(userID)
+	if limit.ResetDate.Equal(allowed.ResetDate) {
+		limit.RefundRequest()
+	}
+	return limit, nil
+}
+
+func (m *mockLimiter) Policy() models.QuotaPolicy {
+	return models.DefaultQuotaPolicy()
+}
+
+func (m *mockLimiter) Status(_ context.Context, userID int64) (*models.APILimit, error) {
+	return m.get(userID), nil
+}
+
+func (m *mockLimiter) SetTier(_ context.Context, userID int64, tier string) (*models.APILimit, error) {
+	limit := m.get(userID)
+	limit.Tier = tier
+	limit.IsPremium = tier == models.TierPremium
+	return limit, nil
+}
+
+func (m *mockLimiter) Reset(_ context.Context, userID int64) (*models.APILimit, error) {
+	limit := m.get(userID)
+	limit.RequestsCount = 0
+	return limit, nil
+}
+
+func TestRewriteLimit(t *testing.T) {
+	repo := newMockTaskRepository()
+	limits := newMockLimiter()
+	server := llmtest.NewServer(t)
+	h := NewHandlers(repo, &mockDiscussionRepository{},
+		WithLLMClient(server.Client(t)),
+		WithLimiter(limits),
+	)
+
+	c := newCommandContext(1, `/add "молоко"`, "")
+	require.NoError(t, h.handleAdd(c))
+	assert.Equal(t, 1, limits.get(1).RequestsCount)
+	assert.Len(t, server.Requests(), 1)
+
+	limits.get(1).RequestsCount = models.DefaultRequestLimit
+
+	c = newCommandContext(1, `/add "хлеб"`, "")
+	require.NoError(t, h.handleAdd(c))
+
+	assert.Len(t, server.Requests(), 1, "LLM must not be called when the limit is exhausted")
+	require.Contains(t, repo.tasks, 2)
+	assert.Empty(t, repo.tasks[2].LLMProcessedDesc)
+	assert.Contains(t, c.lastSent(), "исчерпан лимит запросов к ИИ")
+}
+
+func TestRewriteLimitRefund(t *testing.T) {
+	repo := newMockTaskRepository()
+	limits := newMockLimiter()
+	server := llmtest.NewServer(t)
+	h := NewHandlers(repo, &mockDiscussionRepository{},
+		WithLLMClient(server.Client(t)),
+		WithLimiter(limits),
+	)
+
+	// Запрос, на который LLM не ответил, не расходует лимит
+	server.Enqueue(llmtest.Reply{ErrorCode: 1001}, llmtest.Reply{ErrorCode: 1001}, llmtest.Reply{ErrorCode: 1001})
+	c := newCommandContext(1, `/add "молоко"`, "")
+	require.NoError(t, h.handleAdd(c))
+	assert.Contains(t, c.lastSent(), "не ответил вовремя")
+	assert.Equal(t, 0, limits.get(1).RequestsCount)
+
+	server.Enqueue(llmtest.Reply{Content: "Купить хлеб"})
+	c = newCommandContext(1, `/add "хлеб"`, "")
+	require.NoError(t, h.handleAdd(c))
+	assert.Equal(t, 1, limits.get(1).RequestsCount)
+}
+
+func TestRewriteSaveFailure(t *testing.T) {
+	setup := func(t *testing.T) (*Handlers, *mockTaskRepository, *mockLimiter, *llmtest.Server) {
+		repo := newMockTaskRepository()
+		limits := newMockLimiter()
+		server := llmtest.NewServer(t)
+		h := NewHandlers(repo, &mockDiscussionRepository{},
+			WithLLMClient(server.Client(t)),
+			WithLimiter(limits),
+		)
+		return h, repo, limits, server
+	}
+
+	t.Run("task not saved", func(t *testing.T) {
+		h, repo, limits, server := setup(t)
+		repo.addErr = errors.New("database is locked")
+
+		c := newCommandContext(1, `/add "молоко"`, "")
+		require.NoError(t, h.handleAdd(c))
+
+		assert.Contains(t, c.lastSent(), "Не удалось сохранить задачу")
+		assert.Empty(t, server.Requests(), "LLM must not be called for a task that was not saved")
+		assert.Equal(t, 0, limits.get(1).RequestsCount)
+	})
+
+	t.Run("edit not saved", func(t *testing.T) {
+		h, repo, limits, server := setup(t)
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		repo.updateErr = errors.New("database is locked")
+
+		c := newCommandContext(1, `/edit 1 "кефир"`, "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Contains(t, c.lastSent(), "Не удалось сохранить изменения")
+		assert.Empty(t, server.Requests())
+		assert.Equal(t, 0, limits.get(1).RequestsCount)
+	})
+
+	t.Run("rewrite not saved", func(t *testing.T) {
+		h, repo, limits, server := setup(t)
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		task, err := repo.GetTask(t.Context(), 1)
+		require.NoError(t, err)
+
+		server.Enqueue(llmtest.Reply{Content: "Купить кефир"})
+		repo.updateErr = errors.New("database is locked")
+
+		rewritten, err := h.rewriteDescription(t.Context(), 1, task)
+		assert.False(t, rewritten)
+		assert.ErrorIs(t, err, errRewriteNotSaved)
+		assert.Contains(t, rewriteWarning(err), "не удалось его сохранить")
+		assert.Empty(t, task.LLMProcessedDesc)
+		assert.Equal(t, 0, limits.get(1).RequestsCount)
+	})
+}
+
+func TestHandleLimits(t *testing.T) {
+	t.Run("regular user", func(t *testing.T) {
+		limits := newMockLimiter()
+		limits.get(1).RequestsCount = 3
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithLimiter(limits))
+
+		c := newCommandContext(1, "/limits", "")
+		require.NoError(t, h.handleLimits(c))
+
+		assert.Contains(t, c.lastSent(), "Использовано: 3 из 10 в неделю")
+		assert.Contains(t, c.lastSent(), "Осталось: 7")
+		assert.Contains(t, c.lastSent(), "🔄 Обновление:")
+	})
+
+	t.Run("premium user", func(t *testing.T) {
+		limits := newMockLimiter()
+		limits.get(1).Tier = models.TierPremium
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithLimiter(limits))
+
+		c := newCommandContext(1, "/limits", "")
+		require.NoError(t, h.handleLimits(c))
+
+		assert.Contains(t, c.lastSent(), "премиум")
+		assert.Contains(t, c.lastSent(), "без ограничений")
+	})
+
+	t.Run("limiter not configured", func(t *testing.T) {
+		h := createTestHandlers()
+
+		c := newCommandContext(1, "/limits", "")
+		require.NoError(t, h.handleLimits(c))
+
+		assert.Contains(t, c.lastSent(), "не настроены")
+	})
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// tasksPerPage - количество задач на одной странице /list
+const tasksPerPage = 10
+
+// Фильтры команды /list
+const (
+	listFilterActive    = "active"
+	listFilterDone      = "done"
+	listFilterOverdue   = "overdue"
+	listFilterPostponed = "postponed"
+	listFilterAll       = "all"
+)
+
+// listFilterTitles содержит заголовки списков для каждого фильтра
+var listFilterTitles = map[string]string{
+	listFilterActive:    "Активные задачи",
+	listFilterDone:      "Выполненные задачи",
+	listFilterOverdue:   "Просроченные задачи",
+	listFilterPostponed: "Отложенные задачи",
+	listFilterAll:       "Все задачи",
+}
+
+// handleList обрабатывает команду /list
+func (h *Handlers) handleList(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		filter, tag, err := parseListArgs(utils.SplitCommandArgs(c.Message().Payload))
+		if err != nil {
+			return c.Send(err.Error())
+		}
+
+		text, markup, err := h.renderListPage(ctx, int(userID), filter, tag, 0)
+		if err != nil {
+			h.logUserAction(userID, "list_tasks_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить задачи. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "list_tasks", fmt.Sprintf("Filter: %s, Tag: %s", filter, tag))
+		return c.Send(text, markup)
+	})
+}
+
+// parseListArgs разбирает аргументы /list: фильтр и #тег в любом порядке
+func parseListArgs(args []string) (filter, tag string, err error) {
+	filter = listFilterActive
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "#") {
+			if tag, err = models.NormalizeTag(arg); err != nil {
+				return "", "", fmt.Errorf("❌ Некорректный тег %s", arg)
+			}
+			continue
+		}
+
+		filter = strings.ToLower(arg)
+		if _, ok := listFilterTitles[filter]; !ok {
+			return "", "", errors.New("❌ Неизвестный фильтр. Используйте: /list [done|overdue|postponed|all] [#тег]")
+		}
+	}
+
+	return filter, tag, nil
+}
+
+// handleListCallback обрабатывает переключение страниц списка задач
+func (h *Handlers) handleListCallback(c telebot.Context, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	filter := args[0]
+	if _, ok := listFilterTitles[filter]; !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Неизвестный фильтр"})
+	}
+
+	page, err := strconv.Atoi(args[1])
+	if err != nil || page < 0 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректный номер страницы"})
+	}
+
+	var tag string
+	if len(args) == 3 {
+		tag = args[2]
+	}
+
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+	text, markup, err := h.renderListPage(ctx, int(userID), filter, tag, page)
+	if err != nil {
+		h.logUserAction(userID, "list_tasks_error", fmt.Sprintf("Database error: %v", err))
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Не удалось загрузить задачи"})
+	}
+
+	if err := editCallbackMessage(c, text, markup); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// renderListPage формирует страницу списка задач и клавиатуру навигации.
+// Если указан тег, показываются только задачи с этим тегом.
+func (h *Handlers) renderListPage(ctx context.Context, userID int, filter, tag string, page int) (string, *telebot.ReplyMarkup, error) {
+	tasks, err := h.loadTasks(ctx, userID, filter, tag)
+	if err != nil {
+		return "", nil, err
+	}
+
+	loc := h.userLocation(ctx, int64(userID))
+	infos := make([]utils.TaskInfo, 0, len(tasks))
+	for _, task := range tasks {
+		infos = append(infos, toTaskInfo(task, loc))
+	}
+
+	title := listFilterTitles[filter]
+	if tag != "" {
+		title += " #" + tag
+	}
+
+	pages := utils.PaginateTaskList(infos, title, tasksPerPage, utils.MaxMessageLength)
+	if page >= len(pages) {
+		page = len(pages) - 1
+	}
+
+	return pages[page], listNavigation(filter, tag, page, len(pages)), nil
+}
+
+// loadTasks загружает задачи пользователя в соответствии с фильтром и тегом
+func (h *Handlers) loadTasks(ctx context.Context, userID int, filter, tag string) ([]*models.Task, error) {
+	if tag != "" {
+		tasks, err := h.repository.GetTasksByTag(ctx, userID, tag)
+		if err != nil {
+			return nil, err
+		}
+		return filterTasks(tasks, filter), nil
+	}
+
+	switch filter {
+	case listFilterDone:
+		return h.repository.GetTasksByStatus(ctx, userID, models.StatusDone)
+	case listFilterOverdue:
+		return h.repository.GetOverdueTasks(ctx, userID)
+	case listFilterPostponed:
+		return h.repository.GetTasksByStatus(ctx, userID, models.StatusPostponed)
+	case listFilterAll:
+		return h.repository.GetTasksByUser(ctx, userID)
+	default:
+		return h.repository.GetActiveTasks(ctx, userID)
+	}
+}
+
+// filterTasks оставляет задачи, подходящие под фильтр /list
+func filterTasks(tasks []*models.Task, filter string) []*models.Task {
+	filtered := make([]*models.Task, 0, len(tasks))
+	for _, task := range tasks {
+		var ok bool
+		switch filter {
+		case listFilterDone:
+			ok = task.IsDone()
+		case listFilterOverdue:
+			ok = task.IsActive() && task.IsOverdue()
+		case listFilterPostponed:
+			ok = task.IsPostponed()
+		case listFilterAll:
+			ok = true
+		default:
+			ok = task.IsActive()
+		}
+		if ok {
+			filtered = append(filtered, task)
+		}
+	}
+	return filtered
+}
+
+// listNavigation создает кнопки ◀️/▶️ для переключения страниц
+func listNavigation(filter, tag string, page, total int) *telebot.ReplyMarkup {
+	args := func(page int) []string {
+		if tag == "" {
+			return []string{filter, strconv.Itoa(page)}
+		}
+		return []string{filter, strconv.Itoa(page), tag}
+	}
+
+	var row []telebot.InlineButton
+	if page > 0 {
+		row = append(row, inlineButton("◀️", callbackList, args(page-1)...))
+	}
+	if page < total-1 {
+		row = append(row, inlineButton("▶️", callbackList, args(page+1)...))
+	}
+
+	return inlineKeyboard(row)
+}
+
+// toTaskInfo преобразует задачу в структуру для форматирования, срок переводится в пояс loc
+func toTaskInfo(task *models.Task, loc *time.Location) utils.TaskInfo {
+	info := utils.TaskInfo{
+		ID:          task.ID,
+		Description: task.GetDescription(),
+		Deadline:    task.Deadline.In(loc),
+		HasDeadline: task.HasDeadline(),
+		HasTime:     task.DeadlineHasTime,
+		Status:      task.Status,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		IsOverdue:   task.IsOverdue(),
+		Recurrence:  task.Recurrence,
+	}
+	if task.Status == models.StatusPostponed && !task.PostponedUntil.IsZero() {
+		info.PostponedUntil = task.PostponedUntil.In(loc)
+	}
+	return info
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addMockTasks(t *testing.T, repo *mockTaskRepository, userID, count int, status string) {
+	for i := 1; i <= count; i++ {
+		err := repo.AddTask(t.Context(), &models.Task{
+			UserID:              userID,
+			OriginalDescription: fmt.Sprintf("Task %s %d", status, i),
+			Status:              status,
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestHandleList(t *testing.T) {
+	t.Run("active tasks by default", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 2, models.StatusActive)
+		addMockTasks(t, repo, 1, 1, models.StatusDone)
+		addMockTasks(t, repo, 2, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/list", "")
+		require.NoError(t, h.handleList(c))
+
+		assert.Contains(t, c.lastSent(), "📋 Активные задачи")
+		assert.Contains(t, c.lastSent(), "Task active 1")
+		assert.Contains(t, c.lastSent(), "Task active 2")
+		assert.NotContains(t, c.lastSent(), "Task done 1")
+		assert.Empty(t, c.lastMarkup().InlineKeyboard)
+	})
+
+	t.Run("done filter", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		addMockTasks(t, repo, 1, 1, models.StatusDone)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/list done", "done")
+		require.NoError(t, h.handleList(c))
+
+		assert.Contains(t, c.lastSent(), "📋 Выполненные задачи")
+		assert.Contains(t, c.lastSent(), "Task done 1")
+		assert.NotContains(t, c.lastSent(), "Task active 1")
+	})
+
+	t.Run("overdue filter", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		require.NoError(t, repo.AddTask(t.Context(), &models.Task{
+			UserID:              1,
+			OriginalDescription: "Late task",
+			Deadline:            time.Now().Add(-time.Hour),
+		}))
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/list overdue", "overdue")
+		require.NoError(t, h.handleList(c))
+
+		assert.Contains(t, c.lastSent(), "🔴 1. Late task")
+		assert.NotContains(t, c.lastSent(), "Task active 1")
+	})
+
+	t.Run("added priority is shown", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/add Report !1", "")
+		require.NoError(t, h.handleAdd(c))
+		assert.Contains(t, c.lastSent(), "🚩 Приоритет: 🟥 срочный (!1)")
+		assert.Equal(t, models.PriorityUrgent, repo.tasks[1].Priority)
+
+		c = newCommandContext(1, "/list", "")
+		require.NoError(t, h.handleList(c))
+		assert.Contains(t, c.lastSent(), "📝 1. 🟥 Report (ID: 1)")
+	})
+
+	t.Run("unknown filter", func(t *testing.T) {
+		h := newTestHandlers(newMockTaskRepository())
+
+		c := newCommandContext(1, "/list nonsense", "nonsense")
+		require.NoError(t, h.handleList(c))
+
+		assert.Contains(t, c.lastSent(), "Неизвестный фильтр")
+	})
+
+	t.Run("long list is paginated", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 25, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/list all", "all")
+		require.NoError(t, h.handleList(c))
+
+		assert.Contains(t, c.lastSent(), "(стр. 1/3)")
+		keyboard := c.lastMarkup().InlineKeyboard
+		require.Len(t, keyboard, 1)
+		require.Len(t, keyboard[0], 1)
+		assert.Equal(t, "▶️", keyboard[0][0].Text)
+		assert.Equal(t, "list|all|1", keyboard[0][0].Data)
+	})
+}
+
+func TestHandleListCallback(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTasks(t, repo, 1, 25, models.StatusActive)
+	h := newTestHandlers(repo)
+
+	t.Run("next page", func(t *testing.T) {
+		c := newCallbackContext(1, "list|active|1")
+		require.NoError(t, h.handleCallback(c))
+
+		require.Len(t, c.edited, 1)
+		assert.Contains(t, c.edited[0], "(стр. 2/3)")
+		assert.Contains(t, c.edited[0], "11. Task active 11")
+
+		keyboard := c.lastMarkup().InlineKeyboard
+		require.Len(t, keyboard, 1)
+		require.Len(t, keyboard[0], 2)
+		assert.Equal(t, "list|active|0", keyboard[0][0].Data)
+		assert.Equal(t, "list|active|2", keyboard[0][1].Data)
+	})
+
+	t.Run("page out of range shows last page", func(t *testing.T) {
+		c := newCallbackContext(1, "list|active|10")
+		require.NoError(t, h.handleCallback(c))
+
+		require.Len(t, c.edited, 1)
+		assert.Contains(t, c.edited[0], "(стр. 3/3)")
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		c := newCallbackContext(1, "list|active")
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Empty(t, c.edited)
+		require.Len(t, c.responses, 1)
+		assert.Contains(t, c.responses[0].Text, "Некорректные данные")
+	})
+}
+
+func TestParseCallbackData(t *testing.T) {
+	action, args := parseCallbackData(callbackData(callbackList, "done", "3"))
+	assert.Equal(t, callbackList, action)
+	assert.Equal(t, []string{"done", "3"}, args)
+
+	action, args = parseCallbackData("")
+	assert.Empty(t, action)
+	assert.Empty(t, args)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// pendingStore хранит данные для inline-кнопок, которые действуют ограниченное время.
+// В данные кнопки помещается только короткий ключ, так как Telegram ограничивает их 64 байтами.
+type pendingStore[T any] struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	now    func() time.Time
+	nextID int64
+	items  map[string]pendingItem[T]
+}
+
+// pendingItem - значение с временем истечения
+type pendingItem[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// newPendingStore создает хранилище с указанным временем жизни записей
+func newPendingStore[T any](ttl time.Duration) *pendingStore[T] {
+	return &pendingStore[T]{
+		ttl:   ttl,
+		now:   time.Now,
+		items: make(map[string]pendingItem[T]),
+	}
+}
+
+// Put сохраняет значение и возвращает ключ для данных кнопки
+func (s *pendingStore[T]) Put(value T) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for key, item := range s.items {
+		if now.After(item.expiresAt) {
+			delete(s.items, key)
+		}
+	}
+
+	s.nextID++
+	key := strconv.FormatInt(s.nextID, 36)
+	s.items[key] = pendingItem[T]{value: value, expiresAt: now.Add(s.ttl)}
+
+	return key
+}
+
+// Get возвращает значение, если оно существует и не истекло
+func (s *pendingStore[T]) Get(key string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	if !ok || s.now().After(item.expiresAt) {
+		var zero T
+		return zero, false
+	}
+
+	return item.value, true
+}
+
+// Take возвращает значение и удаляет его из хранилища
+func (s *pendingStore[T]) Take(key string) (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.items[key]
+	delete(s.items, key)
+
+	if !ok || s.now().After(item.expiresAt) {
+		var zero T
+		return zero, false
+	}
+
+	return item.value, true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// postponeMorningHour - час, в который возвращаются задачи, отложенные до дня без времени
+const postponeMorningHour = 9
+
+// Периоды кнопок «отложить» в напоминаниях
+const (
+	snoozeHour     = "1h"
+	snoozeTomorrow = "1d"
+	snoozeWeek     = "1w"
+)
+
+// SnoozeKeyboard создает кнопки, которыми можно отложить задачу из напоминания о ней
+func SnoozeKeyboard(task *models.Task) *telebot.ReplyMarkup {
+	id := strconv.Itoa(task.ID)
+	return inlineKeyboard([]telebot.InlineButton{
+		inlineButton("💤 На час", callbackSnooze, id, snoozeHour),
+		inlineButton("🌅 Завтра", callbackSnooze, id, snoozeTomorrow),
+		inlineButton("📆 Через неделю", callbackSnooze, id, snoozeWeek),
+	})
+}
+
+// snoozeUntil возвращает время, до которого кнопка откладывает задачу
+func snoozeUntil(period string, now time.Time) (time.Time, bool) {
+	switch period {
+	case snoozeHour:
+		return now.Add(time.Hour).Truncate(time.Minute), true
+	case snoozeTomorrow:
+		return morningOf(now.AddDate(0, 0, 1)), true
+	case snoozeWeek:
+		return now.AddDate(0, 0, 7).Truncate(time.Minute), true
+	default:
+		return time.Time{}, false
+	}
+}
+
+// morningOf возвращает утро дня date в его часовом поясе
+func morningOf(date time.Time) time.Time {
+	return time.Date(date.Year(), date.Month(), date.Day(), postponeMorningHour, 0, 0, 0, date.Location())
+}
+
+// handlePostpone обрабатывает команду /postpone
+func (h *Handlers) handlePostpone(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		return h.postponeTask(c, userID, c.Text())
+	})
+}
+
+// postponeTask откладывает задачу по тексту команды /postpone.
+// Задача, отложенная до дня без времени, возвращается утром этого дня.
+func (h *Handlers) postponeTask(c telebot.Context, userID int64, text string) error {
+	ctx := requestContext(c)
+
+	user := h.userSettings(ctx, userID)
+	now := time.Now().In(user.Location())
+	input, err := utils.ParsePostponeCommand(text, now, user.DateOrder)
+	var ambiguous *utils.AmbiguousDateError
+	if errors.As(err, &ambiguous) {
+		return h.askDate(c, userID, text, "до", h.postponeTask, ambiguous)
+	}
+	if err != nil {
+		h.logUserAction(userID, "postpone_task_error", fmt.Sprintf("Parse error: %v", err))
+		return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s\n\nПримеры: /postpone 3, /postpone 3 до: завтра, /postpone 3 до: 15.07 в 10:00", err.Error()))
+	}
+
+	until := input.Until
+	if input.HasUntil && !input.HasTime {
+		until = morningOf(until)
+	}
+	if input.HasUntil && !until.After(now) {
+		return c.Send("❌ Отложить можно только до момента в будущем")
+	}
+
+	task, err := h.getUserTask(ctx, userID, input.TaskID)
+	if err != nil {
+		return c.Send(taskAccessError(input.TaskID, err))
+	}
+	if task.IsDone() {
+		return c.Send(fmt.Sprintf("☑️ Задача %d уже выполнена", task.ID))
+	}
+
+	if err := h.postpone(ctx, userID, task, until); err != nil {
+		return c.Send("❌ Не удалось отложить задачу. Попробуйте позже.")
+	}
+
+	if until.IsZero() {
+		return c.Send(fmt.Sprintf("⏸️ Задача %d отложена без срока\n\nВернуть в работу: /edit %d статус: active", task.ID, task.ID))
+	}
+
+	return c.Send(fmt.Sprintf("⏸️ Задача %d отложена до %s\n\nВ назначенное время она снова станет активной, и бот напомнит о ней",
+		task.ID, utils.FormatDateWords(until, true)))
+}
+
+// handleSnoozeCallback откладывает задачу кнопкой из напоминания
+func (h *Handlers) handleSnoozeCallback(c telebot.Context, args []string) error {
+	if len(args) != 2 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	taskID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+	now := time.Now().In(h.userLocation(ctx, userID))
+	until, ok := snoozeUntil(args[1], now)
+	if !ok {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	task, err := h.getUserTask(ctx, userID, taskID)
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: taskAccessError(taskID, err)})
+	}
+	if task.IsDone() {
+		return c.Respond(&telebot.CallbackResponse{Text: fmt.Sprintf("☑️ Задача %d уже выполнена", task.ID)})
+	}
+
+	if err := h.postpone(ctx, userID, task, until); err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Не удалось отложить задачу. Попробуйте позже."})
+	}
+
+	text := "💤 Отложено до " + utils.FormatDateWords(until, true)
+	if err := editCallbackMessage(c, c.Message().Text+"\n\n"+text); err != nil {
+		return err
+	}
+
+	return c.Respond(&telebot.CallbackResponse{Text: text})
+}
+
+// postpone откладывает задачу до until и записывает изменение в историю
+func (h *Handlers) postpone(ctx context.Context, userID int64, task *models.Task, until time.Time) error {
+	before := *task
+	task.Postpone(until)
+	if err := h.repository.UpdateTask(ctx, task); err != nil {
+		h.logUserAction(userID, "postpone_task_error", fmt.Sprintf("Task ID: %d, Database error: %v", task.ID, err))
+		return err
+	}
+
+	h.recordChanges(ctx, userID, &before, task)
+	h.logUserAction(userID, "postpone_task", fmt.Sprintf("Task ID: %d, Until: %v", task.ID, until))
+	return nil
+}
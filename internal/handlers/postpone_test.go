@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandlePostpone(t *testing.T) {
+	t.Run("until a day returns in the morning", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/postpone 1 до: 2099-07-15", "1 до: 2099-07-15")
+		require.NoError(t, h.handlePostpone(c))
+
+		task := repo.tasks[1]
+		assert.Equal(t, models.StatusPostponed, task.Status)
+		assert.Equal(t, time.Date(2099, 7, 15, postponeMorningHour, 0, 0, 0, time.Local), task.PostponedUntil)
+		assert.Contains(t, c.lastSent(), "⏸️ Задача 1 отложена до ср, 15 июля 2099 в 09:00")
+		require.Len(t, repo.history, 1)
+		assert.Equal(t, models.FieldStatus, repo.history[0].Field)
+	})
+
+	t.Run("indefinitely", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/postpone 1", "1")
+		require.NoError(t, h.handlePostpone(c))
+
+		assert.Equal(t, models.StatusPostponed, repo.tasks[1].Status)
+		assert.True(t, repo.tasks[1].PostponedUntil.IsZero())
+		assert.Contains(t, c.lastSent(), "отложена без срока")
+		assert.Contains(t, c.lastSent(), "/edit 1 статус: active")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive) // 1
+		addMockTasks(t, repo, 1, 1, models.StatusDone)   // 2
+		addMockTasks(t, repo, 2, 1, models.StatusActive) // 3
+		h := newTestHandlers(repo)
+
+		cases := map[string]string{
+			"/postpone":                    "Ошибка в команде",
+			"/postpone 1 до: 2000-01-01":   "только до момента в будущем",
+			"/postpone 1 до: когда-нибудь": "Ошибка в команде",
+			"/postpone 2":                  "уже выполнена",
+			"/postpone 3":                  "принадлежит другому пользователю",
+			"/postpone 9":                  "не найдена",
+		}
+		for text, expected := range cases {
+			c := newCommandContext(1, text, "")
+			require.NoError(t, h.handlePostpone(c))
+			assert.Contains(t, c.lastSent(), expected, text)
+		}
+
+		assert.Equal(t, models.StatusActive, repo.tasks[1].Status)
+	})
+
+	t.Run("ambiguous date is asked", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/postpone 1 до: 03/04/2099", "")
+		require.NoError(t, h.handlePostpone(c))
+		assert.Equal(t, models.StatusActive, repo.tasks[1].Status)
+
+		cb := newCallbackContext(1, c.lastMarkup().InlineKeyboard[1][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Equal(t, models.StatusPostponed, repo.tasks[1].Status)
+		assert.Equal(t, time.Date(2099, 3, 4, postponeMorningHour, 0, 0, 0, time.Local), repo.tasks[1].PostponedUntil)
+	})
+}
+
+func TestSnoozeCallback(t *testing.T) {
+	setup := func() (*Handlers, *mockTaskRepository) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		return newTestHandlers(repo), repo
+	}
+
+	t.Run("buttons postpone the task", func(t *testing.T) {
+		keyboard := SnoozeKeyboard(&models.Task{ID: 1}).InlineKeyboard
+		require.Len(t, keyboard, 1)
+		require.Len(t, keyboard[0], 3)
+
+		now := time.Now()
+		expected := []time.Time{
+			now.Add(time.Hour),
+			morningOf(now.AddDate(0, 0, 1)),
+			now.AddDate(0, 0, 7),
+		}
+
+		for i, button := range keyboard[0] {
+			h, repo := setup()
+			cb := newCallbackContext(1, button.Data)
+			cb.message.Text = "🔔 Напоминание"
+			require.NoError(t, h.handleCallback(cb))
+
+			task := repo.tasks[1]
+			assert.Equal(t, models.StatusPostponed, task.Status, button.Text)
+			assert.WithinDuration(t, expected[i], task.PostponedUntil, time.Minute, button.Text)
+			require.Len(t, cb.edited, 1)
+			assert.Contains(t, cb.edited[0], "🔔 Напоминание\n\n💤 Отложено до")
+			assert.Nil(t, cb.lastMarkup())
+		}
+	})
+
+	t.Run("other user's task", func(t *testing.T) {
+		h, repo := setup()
+
+		cb := newCallbackContext(2, callbackData(callbackSnooze, "1", snoozeHour))
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Equal(t, models.StatusActive, repo.tasks[1].Status)
+		assert.Contains(t, cb.responses[0].Text, "принадлежит другому пользователю")
+	})
+
+	t.Run("done task is not postponed", func(t *testing.T) {
+		h, repo := setup()
+		repo.tasks[1].Status = models.StatusDone
+
+		cb := newCallbackContext(1, callbackData(callbackSnooze, "1", snoozeWeek))
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.Equal(t, models.StatusDone, repo.tasks[1].Status)
+		assert.Contains(t, cb.responses[0].Text, "уже выполнена")
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		h, _ := setup()
+
+		cb := newCallbackContext(1, callbackData(callbackSnooze, "1", "1y"))
+		require.NoError(t, h.handleCallback(cb))
+		assert.Contains(t, cb.responses[0].Text, "Некорректные данные")
+	})
+}
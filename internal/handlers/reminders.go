@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// ReminderSettingsStore хранит настройки напоминаний пользователей
+type ReminderSettingsStore interface {
+	GetReminderSettings(ctx context.Context, userID int) (*models.ReminderSettings, error)
+	SaveReminderSettings(ctx context.Context, settings *models.ReminderSettings) error
+}
+
+// WithReminderSettings подключает настройки напоминаний для /reminders
+func WithReminderSettings(store ReminderSettingsStore) Option {
+	return func(h *Handlers) {
+		h.reminders = store
+	}
+}
+
+const remindersUsage = `Использование:
+/reminders - текущие настройки
+/reminders 1д 3ч 30мин - за сколько до срока напоминать
+/reminders default - по умолчанию (за 1 день и за 1 час)
+/reminders off - выключить напоминания
+/reminders on - включить напоминания`
+
+// handleReminders обрабатывает команду /reminders
+func (h *Handlers) handleReminders(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		if h.reminders == nil {
+			return c.Send("ℹ️ Напоминания не настроены")
+		}
+
+		ctx := requestContext(c)
+		settings, err := h.reminders.GetReminderSettings(ctx, int(userID))
+		if err != nil {
+			h.logUserAction(userID, "reminders_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить настройки напоминаний. Попробуйте позже.")
+		}
+
+		args := strings.Fields(c.Message().Payload)
+		if len(args) == 0 {
+			return c.Send(formatReminderSettings(settings) + "\n\n" + remindersUsage)
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "off":
+			settings.Enabled = false
+		case "on":
+			settings.Enabled = true
+		case "default":
+			settings = models.DefaultReminderSettings(int(userID))
+		default:
+			leadTimes, errMessage := parseReminderLeadTimes(args)
+			if errMessage != "" {
+				return c.Send("❌ " + errMessage + "\n\n" + remindersUsage)
+			}
+			settings.Enabled = true
+			settings.LeadTimes = leadTimes
+		}
+
+		if err := h.reminders.SaveReminderSettings(ctx, settings); err != nil {
+			h.logUserAction(userID, "reminders_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось сохранить настройки напоминаний. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "reminders", c.Message().Payload)
+		return c.Send("✅ Настройки сохранены\n\n" + formatReminderSettings(settings))
+	})
+}
+
+// parseReminderLeadTimes разбирает время напоминаний и возвращает текст ошибки для пользователя
+func parseReminderLeadTimes(args []string) ([]time.Duration, string) {
+	if len(args) > models.MaxReminderLeadTimes {
+		return nil, fmt.Sprintf("Можно задать не больше %d напоминаний", models.MaxReminderLeadTimes)
+	}
+
+	leadTimes := make([]time.Duration, 0, len(args))
+	seen := make(map[time.Duration]bool)
+	for _, arg := range args {
+		lead, err := utils.ParseLeadTime(arg)
+		if err != nil {
+			return nil, fmt.Sprintf("Не понял время «%s». Примеры: 1д, 3ч, 30мин", arg)
+		}
+		if lead < models.MinReminderLeadTime || lead > models.MaxReminderLeadTime {
+			return nil, fmt.Sprintf("Напоминать можно не раньше чем за %s и не позже чем за %s до срока",
+				utils.FormatLeadTime(models.MaxReminderLeadTime), utils.FormatLeadTime(models.MinReminderLeadTime))
+		}
+		if !seen[lead] {
+			seen[lead] = true
+			leadTimes = append(leadTimes, lead)
+		}
+	}
+
+	return leadTimes, ""
+}
+
+// formatReminderSettings описывает настройки напоминаний пользователя
+func formatReminderSettings(settings *models.ReminderSettings) string {
+	if !settings.Enabled {
+		return "🔕 Напоминания выключены"
+	}
+
+	if len(settings.LeadTimes) == 0 {
+		return "🔔 Напоминания: только о просроченных задачах"
+	}
+
+	leads := make([]string, len(settings.LeadTimes))
+	for i, lead := range settings.LeadTimes {
+		leads[i] = "за " + utils.FormatLeadTime(lead)
+	}
+
+	return fmt.Sprintf("🔔 Напоминания: %s до срока и при просрочке", strings.Join(leads, ", "))
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockReminderSettings is a simple in-memory reminder settings store for testing
+type mockReminderSettings struct {
+	settings map[int]*models.ReminderSettings
+}
+
+func newMockReminderSettings() *mockReminderSettings {
+	return &mockReminderSettings{settings: make(map[int]*models.ReminderSettings)}
+}
+
+func (m *mockReminderSettings) GetReminderSettings(ctx context.Context, userID int) (*models.ReminderSettings, error) {
+	if settings, ok := m.settings[userID]; ok {
+		copied := *settings
+		return &copied, nil
+	}
+	return models.DefaultReminderSettings(userID), nil
+}
+
+func (m *mockReminderSettings) SaveReminderSettings(ctx context.Context, settings *models.ReminderSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	settings.Normalize()
+	copied := *settings
+	m.settings[settings.UserID] = &copied
+	return nil
+}
+
+func TestHandleReminders(t *testing.T) {
+	setup := func() (*Handlers, *mockReminderSettings) {
+		store := newMockReminderSettings()
+		return NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithReminderSettings(store)), store
+	}
+
+	t.Run("show defaults", func(t *testing.T) {
+		h, _ := setup()
+
+		c := newCommandContext(1, "/reminders", "")
+		require.NoError(t, h.handleReminders(c))
+
+		assert.Contains(t, c.lastSent(), "за 1 дн., за 1 ч. до срока и при просрочке")
+		assert.Contains(t, c.lastSent(), "Использование")
+	})
+
+	t.Run("set lead times", func(t *testing.T) {
+		h, store := setup()
+
+		c := newCommandContext(1, "/reminders 30мин 2д", "30мин 2д")
+		require.NoError(t, h.handleReminders(c))
+
+		assert.Contains(t, c.lastSent(), "Настройки сохранены")
+		assert.Contains(t, c.lastSent(), "за 2 дн., за 30 мин.")
+		assert.Equal(t, []time.Duration{48 * time.Hour, 30 * time.Minute}, store.settings[1].LeadTimes)
+	})
+
+	t.Run("off, on and default", func(t *testing.T) {
+		h, store := setup()
+
+		c := newCommandContext(1, "/reminders 3ч", "3ч")
+		require.NoError(t, h.handleReminders(c))
+
+		c = newCommandContext(1, "/reminders off", "off")
+		require.NoError(t, h.handleReminders(c))
+		assert.Contains(t, c.lastSent(), "выключены")
+		assert.False(t, store.settings[1].Enabled)
+
+		c = newCommandContext(1, "/reminders on", "on")
+		require.NoError(t, h.handleReminders(c))
+		assert.True(t, store.settings[1].Enabled)
+		assert.Equal(t, []time.Duration{3 * time.Hour}, store.settings[1].LeadTimes)
+
+		c = newCommandContext(1, "/reminders default", "default")
+		require.NoError(t, h.handleReminders(c))
+		assert.Equal(t, models.DefaultReminderLeadTimes, store.settings[1].LeadTimes)
+	})
+
+	t.Run("invalid lead times", func(t *testing.T) {
+		h, store := setup()
+
+		c := newCommandContext(1, "/reminders скоро", "скоро")
+		require.NoError(t, h.handleReminders(c))
+		assert.Contains(t, c.lastSent(), "Не понял время «скоро»")
+
+		c = newCommandContext(1, "/reminders 1мин", "1мин")
+		require.NoError(t, h.handleReminders(c))
+		assert.Contains(t, c.lastSent(), "Напоминать можно")
+
+		assert.Empty(t, store.settings)
+	})
+
+	t.Run("not configured", func(t *testing.T) {
+		h := createTestHandlers()
+
+		c := newCommandContext(1, "/reminders", "")
+		require.NoError(t, h.handleReminders(c))
+		assert.Contains(t, c.lastSent(), "не настроены")
+	})
+}
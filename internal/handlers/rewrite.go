@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telegram-bot-assistente/internal/limiter"
+	"telegram-bot-assistente/internal/llm"
+	"telegram-bot-assistente/internal/models"
+
+	"gopkg.in/telebot.v3"
+)
+
+// Варианты выбора улучшенного описания
+const (
+	rewriteAccept     = "accept"
+	rewriteOriginal   = "original"
+	rewriteRegenerate = "regen"
+)
+
+// llmRewriteTimeout ограничивает общее время запроса к LLM вместе с повторами
+const llmRewriteTimeout = 45 * time.Second
+
+// limitRefundTimeout ограничивает возврат запроса в лимит, когда время запроса к LLM уже вышло
+const limitRefundTimeout = 5 * time.Second
+
+// errRewriteNotSaved - LLM ответил, но улучшенное описание не удалось сохранить
+var errRewriteNotSaved = errors.New("rewritten description not saved")
+
+// rewriteDescription запрашивает у LLM улучшенное описание уже сохраненной задачи
+// и сохраняет его в task.LLMProcessedDesc. Возвращает false, если LLM не подключен
+// или предложил исходный текст. Если ответа нет или его не удалось сохранить,
+// запрос возвращается в лимит.
+func (h *Handlers) rewriteDescription(ctx context.Context, userID int64, task *models.Task, previous ...string) (bool, error) {
+	if h.llmClient == nil {
+		return false, nil
+	}
+
+	llmCtx, cancel := context.WithTimeout(ctx, llmRewriteTimeout)
+	defer cancel()
+
+	var allowed *models.APILimit
+	if h.limiter != nil {
+		limit, err := h.limiter.Allow(llmCtx, userID)
+		if err != nil {
+			h.logUserAction(userID, "llm_rewrite_limit", fmt.Sprintf("Task ID: %d, Error: %v", task.ID, err))
+			return false, err
+		}
+		allowed = limit
+	}
+
+	rewrite, err := llm.RewriteTask(llmCtx, h.llmClient, task.OriginalDescription, previous...)
+	if err != nil {
+		h.logUserAction(userID, "llm_rewrite_error", fmt.Sprintf("Task ID: %d, Error: %v", task.ID, err))
+		if allowed != nil {
+			h.refundLimit(ctx, userID, allowed)
+		}
+		return false, err
+	}
+
+	// Совпадающий с оригиналом вариант не предлагаем
+	if rewrite == task.OriginalDescription {
+		return false, nil
+	}
+
+	current := task.LLMProcessedDesc
+	task.LLMProcessedDesc = rewrite
+	if err := h.repository.UpdateTask(ctx, task); err != nil {
+		h.logUserAction(userID, "llm_rewrite_error", fmt.Sprintf("Task ID: %d, Database error: %v", task.ID, err))
+		task.LLMProcessedDesc = current
+		if allowed != nil {
+			h.refundLimit(ctx, userID, allowed)
+		}
+		return false, fmt.Errorf("%w: %v", errRewriteNotSaved, err)
+	}
+
+	return true, nil
+}
+
+// refundLimit возвращает в лимит запрос, на который LLM не ответил. Контекст запроса
+// к этому моменту может быть уже отменен по таймауту, поэтому возврат получает свой.
+func (h *Handlers) refundLimit(ctx context.Context, userID int64, allowed *models.APILimit) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), limitRefundTimeout)
+	defer cancel()
+
+	if _, err := h.limiter.Refund(ctx, userID, allowed); err != nil {
+		h.logUserAction(userID, "llm_limit_refund_error", fmt.Sprintf("Error: %v", err))
+	}
+}
+
+// rewriteFailureReason описывает причину ошибки LLM для пользователя
+func rewriteFailureReason(err error) string {
+	switch {
+	case errors.Is(err, limiter.ErrLimitExceeded):
+		return "исчерпан лимит запросов к ИИ, подробнее: /limits"
+	case errors.Is(err, errRewriteNotSaved):
+		return "не удалось его сохранить"
+	case errors.Is(err, llm.ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		return "сервис не ответил вовремя"
+	case errors.Is(err, llm.ErrRateLimited):
+		return "сервис перегружен"
+	default:
+		return "сервис недоступен"
+	}
+}
+
+// rewriteWarning сообщает пользователю, что описание осталось исходным из-за ошибки LLM
+func rewriteWarning(err error) string {
+	return fmt.Sprintf("⚠️ Не удалось улучшить описание: %s. Сохранен исходный текст задачи.", rewriteFailureReason(err))
+}
+
+// rewriteKeyboard создает кнопки выбора между улучшенным и исходным описанием
+func rewriteKeyboard(taskID int) *telebot.ReplyMarkup {
+	id := strconv.Itoa(taskID)
+	return inlineKeyboard(
+		[]telebot.InlineButton{
+			inlineButton("✅ Принять", callbackRewrite, rewriteAccept, id),
+			inlineButton("📄 Оставить исходное", callbackRewrite, rewriteOriginal, id),
+		},
+		[]telebot.InlineButton{
+			inlineButton("🔄 Другой вариант", callbackRewrite, rewriteRegenerate, id),
+		},
+	)
+}
+
+// formatRewriteProposal формирует сообщение с предложенным LLM описанием
+func formatRewriteProposal(task *models.Task) string {
+	return fmt.Sprintf("🤖 Улучшенное описание задачи\n\n📝 ID: %d\n📄 Исходное: %s\n✨ Предложение: %s",
+		task.ID, task.OriginalDescription, task.LLMProcessedDesc)
+}
+
+// handleRewriteCallback обрабатывает выбор варианта описания
+func (h *Handlers) handleRewriteCallback(c telebot.Context, args []string) error {
+	if len(args) != 2 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	taskID, err := strconv.Atoi(args[1])
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+	task, err := h.getUserTask(ctx, userID, taskID)
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: taskAccessError(taskID, err)})
+	}
+
+	switch args[0] {
+	case rewriteAccept:
+		if task.LLMProcessedDesc == "" {
+			return c.Respond(&telebot.CallbackResponse{Text: "ℹ️ Улучшенное описание больше не актуально"})
+		}
+
+		h.logUserAction(userID, "llm_rewrite_accept", fmt.Sprintf("Task ID: %d", task.ID))
+		if err := c.Respond(&telebot.CallbackResponse{Text: "✅ Описание принято"}); err != nil {
+			return err
+		}
+		return editCallbackMessage(c, fmt.Sprintf("✅ Задача %d: используется улучшенное описание\n📄 %s", task.ID, task.LLMProcessedDesc))
+
+	case rewriteOriginal:
+		if task.LLMProcessedDesc != "" {
+			task.LLMProcessedDesc = ""
+			if err := h.repository.UpdateTask(ctx, task); err != nil {
+				h.logUserAction(userID, "llm_rewrite_error", fmt.Sprintf("Task ID: %d, Database error: %v", task.ID, err))
+				return c.Respond(&telebot.CallbackResponse{Text: "❌ Не удалось сохранить выбор. Попробуйте позже."})
+			}
+		}
+
+		h.logUserAction(userID, "llm_rewrite_reject", fmt.Sprintf("Task ID: %d", task.ID))
+		if err := c.Respond(&telebot.CallbackResponse{Text: "📄 Оставлено исходное описание"}); err != nil {
+			return err
+		}
+		return editCallbackMessage(c, fmt.Sprintf("📄 Задача %d: оставлено исходное описание\n%s", task.ID, task.OriginalDescription))
+
+	case rewriteRegenerate:
+		var previous []string
+		if task.LLMProcessedDesc != "" {
+			previous = append(previous, task.LLMProcessedDesc)
+		}
+
+		rewritten, err := h.rewriteDescription(ctx, userID, task, previous...)
+		if err != nil {
+			return c.Respond(&telebot.CallbackResponse{
+				Text:      fmt.Sprintf("⚠️ Не удалось получить другой вариант: %s", rewriteFailureReason(err)),
+				ShowAlert: true,
+			})
+		}
+		if !rewritten {
+			return c.Respond(&telebot.CallbackResponse{Text: "ℹ️ Другого варианта не нашлось"})
+		}
+
+		h.logUserAction(userID, "llm_rewrite_regenerate", fmt.Sprintf("Task ID: %d", task.ID))
+		if err := c.Respond(); err != nil {
+			return err
+		}
+		return editCallbackMessage(c, formatRewriteProposal(task), rewriteKeyboard(task.ID))
+
+	default:
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+}
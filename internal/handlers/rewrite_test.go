@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/llm/llmtest"
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newLLMTestHandlers(t *testing.T, repo *mockTaskRepository) (*Handlers, *llmtest.Server) {
+	server := llmtest.NewServer(t)
+	return NewHandlers(repo, &mockDiscussionRepository{}, WithLLMClient(server.Client(t))), server
+}
+
+func TestHandleAddRewrite(t *testing.T) {
+	t.Run("stores rewrite and offers choice", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		h, server := newLLMTestHandlers(t, repo)
+		server.Enqueue(llmtest.Reply{Content: "Купить молоко и хлеб"})
+
+		c := newCommandContext(1, `/add "молоко хлеб"`, "")
+		require.NoError(t, h.handleAdd(c))
+
+		task := repo.tasks[1]
+		assert.Equal(t, "молоко хлеб", task.OriginalDescription)
+		assert.Equal(t, "Купить молоко и хлеб", task.LLMProcessedDesc)
+
+		assert.Contains(t, c.lastSent(), "✨ Улучшенное описание: Купить молоко и хлеб")
+		keyboard := c.lastMarkup().InlineKeyboard
+		require.Len(t, keyboard, 2)
+		assert.Equal(t, "rewrite|accept|1", keyboard[0][0].Data)
+		assert.Equal(t, "rewrite|original|1", keyboard[0][1].Data)
+		assert.Equal(t, "rewrite|regen|1", keyboard[1][0].Data)
+	})
+
+	t.Run("llm failure keeps original", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		h, server := newLLMTestHandlers(t, repo)
+		server.Respond = func(llmtest.Request) llmtest.Reply {
+			return llmtest.Reply{StatusCode: http.StatusTooManyRequests}
+		}
+
+		c := newCommandContext(1, `/add "молоко хлеб"`, "")
+		require.NoError(t, h.handleAdd(c))
+
+		require.Contains(t, repo.tasks, 1)
+		assert.Empty(t, repo.tasks[1].LLMProcessedDesc)
+		assert.Contains(t, c.lastSent(), "✅ Задача добавлена")
+		assert.Contains(t, c.lastSent(), "⚠️ Не удалось улучшить описание: сервис перегружен")
+		assert.Nil(t, c.lastMarkup())
+	})
+
+	t.Run("without llm client", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, `/add "молоко хлеб"`, "")
+		require.NoError(t, h.handleAdd(c))
+
+		assert.Empty(t, repo.tasks[1].LLMProcessedDesc)
+		assert.NotContains(t, c.lastSent(), "⚠️")
+		assert.Nil(t, c.lastMarkup())
+	})
+}
+
+func TestHandleEditRewrite(t *testing.T) {
+	repo := newMockTaskRepository()
+	require.NoError(t, repo.AddTask(t.Context(), &models.Task{
+		UserID:              1,
+		OriginalDescription: "молоко",
+		LLMProcessedDesc:    "Купить молоко",
+		Deadline:            time.Date(2025, 7, 15, 23, 59, 59, 0, time.Local),
+	}))
+	h, server := newLLMTestHandlers(t, repo)
+
+	t.Run("deadline change does not call llm", func(t *testing.T) {
+		c := newCommandContext(1, "/edit 1 срок: 2025-07-21", "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Empty(t, server.Requests())
+		assert.Equal(t, "Купить молоко", repo.tasks[1].LLMProcessedDesc)
+	})
+
+	t.Run("description change is rewritten", func(t *testing.T) {
+		server.Enqueue(llmtest.Reply{Content: "Купить кефир"})
+
+		c := newCommandContext(1, `/edit 1 "кефир"`, "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Equal(t, "Купить кефир", repo.tasks[1].LLMProcessedDesc)
+		assert.Contains(t, c.lastSent(), "✨ Улучшенное описание: Купить кефир")
+		assert.NotNil(t, c.lastMarkup())
+	})
+
+	t.Run("llm timeout keeps original", func(t *testing.T) {
+		server.Enqueue(llmtest.Reply{ErrorCode: 1001, ErrorMsg: "timeout"}, llmtest.Reply{ErrorCode: 1001}, llmtest.Reply{ErrorCode: 1001})
+
+		c := newCommandContext(1, `/edit 1 "сметана"`, "")
+		require.NoError(t, h.handleEdit(c))
+
+		assert.Equal(t, "сметана", repo.tasks[1].OriginalDescription)
+		assert.Empty(t, repo.tasks[1].LLMProcessedDesc)
+		assert.Contains(t, c.lastSent(), "сервис не ответил вовремя")
+	})
+}
+
+func TestHandleRewriteCallback(t *testing.T) {
+	setup := func(t *testing.T) (*Handlers, *mockTaskRepository, *llmtest.Server) {
+		repo := newMockTaskRepository()
+		require.NoError(t, repo.AddTask(t.Context(), &models.Task{
+			UserID:              1,
+			OriginalDescription: "молоко",
+			LLMProcessedDesc:    "Купить молоко",
+		}))
+		h, server := newLLMTestHandlers(t, repo)
+		return h, repo, server
+	}
+
+	t.Run("accept", func(t *testing.T) {
+		h, repo, _ := setup(t)
+
+		c := newCallbackContext(1, "rewrite|accept|1")
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Equal(t, "Купить молоко", repo.tasks[1].LLMProcessedDesc)
+		require.Len(t, c.edited, 1)
+		assert.Contains(t, c.edited[0], "используется улучшенное описание")
+	})
+
+	t.Run("keep original", func(t *testing.T) {
+		h, repo, _ := setup(t)
+
+		c := newCallbackContext(1, "rewrite|original|1")
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Empty(t, repo.tasks[1].LLMProcessedDesc)
+		assert.Equal(t, "молоко", repo.tasks[1].GetDescription())
+		require.Len(t, c.edited, 1)
+		assert.Contains(t, c.edited[0], "оставлено исходное описание")
+	})
+
+	t.Run("regenerate", func(t *testing.T) {
+		h, repo, server := setup(t)
+		server.Enqueue(llmtest.Reply{Content: "Купить литр молока"})
+
+		c := newCallbackContext(1, "rewrite|regen|1")
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Equal(t, "Купить литр молока", repo.tasks[1].LLMProcessedDesc)
+		require.Len(t, c.edited, 1)
+		assert.Contains(t, c.edited[0], "✨ Предложение: Купить литр молока")
+		assert.NotNil(t, c.lastMarkup())
+
+		requests := server.Requests()
+		require.Len(t, requests, 1)
+		assert.Contains(t, requests[0].Messages[1].Content, "Купить молоко")
+	})
+
+	t.Run("regenerate failure keeps previous rewrite", func(t *testing.T) {
+		h, repo, server := setup(t)
+		server.Respond = func(llmtest.Request) llmtest.Reply {
+			return llmtest.Reply{StatusCode: http.StatusServiceUnavailable}
+		}
+
+		c := newCallbackContext(1, "rewrite|regen|1")
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Equal(t, "Купить молоко", repo.tasks[1].LLMProcessedDesc)
+		assert.Empty(t, c.edited)
+		require.Len(t, c.responses, 1)
+		assert.True(t, c.responses[0].ShowAlert)
+	})
+
+	t.Run("other user", func(t *testing.T) {
+		h, repo, _ := setup(t)
+
+		c := newCallbackContext(2, "rewrite|original|1")
+		require.NoError(t, h.handleCallback(c))
+
+		assert.Equal(t, "Купить молоко", repo.tasks[1].LLMProcessedDesc)
+		assert.Contains(t, c.responses[0].Text, "принадлежит другому пользователю")
+	})
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+const tagsUsage = `Использование:
+/tags - теги и количество задач с ними
+/tags rename #старый #новый - переименовать тег
+/tags merge #первый #второй #итоговый - объединить теги в последний`
+
+// handleTags обрабатывает команду /tags
+func (h *Handlers) handleTags(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		args := utils.SplitCommandArgs(c.Message().Payload)
+		if len(args) == 0 {
+			return h.listTags(c, userID)
+		}
+
+		tags := make([]string, 0, len(args)-1)
+		for _, arg := range args[1:] {
+			tag, err := models.NormalizeTag(arg)
+			if err != nil {
+				return c.Send(fmt.Sprintf("❌ Некорректный тег %s\n\n%s", arg, tagsUsage))
+			}
+			tags = append(tags, tag)
+		}
+
+		switch strings.ToLower(args[0]) {
+		case "rename":
+			if len(tags) != 2 {
+				return c.Send("❌ Укажите старый и новый тег\n\n" + tagsUsage)
+			}
+		case "merge":
+			if len(tags) < 2 {
+				return c.Send("❌ Укажите хотя бы два тега\n\n" + tagsUsage)
+			}
+		default:
+			return c.Send(tagsUsage)
+		}
+
+		return h.mergeTags(c, userID, tags[:len(tags)-1], tags[len(tags)-1])
+	})
+}
+
+// listTags показывает теги пользователя с количеством задач
+func (h *Handlers) listTags(c telebot.Context, userID int64) error {
+	ctx := requestContext(c)
+
+	tags, err := h.repository.ListTags(ctx, int(userID))
+	if err != nil {
+		h.logUserAction(userID, "tags_error", fmt.Sprintf("Database error: %v", err))
+		return c.Send("❌ Не удалось загрузить теги. Попробуйте позже.")
+	}
+
+	h.logUserAction(userID, "tags", fmt.Sprintf("Tags: %d", len(tags)))
+
+	if len(tags) == 0 {
+		return c.Send("🏷 У вас пока нет тегов. Добавьте #тег в описание задачи: /add Отчет #работа")
+	}
+
+	lines := []string{"🏷 Ваши теги:", ""}
+	for _, tag := range tags {
+		lines = append(lines, fmt.Sprintf("#%s - %d (активных: %d)", tag.Name, tag.Tasks, tag.Active))
+	}
+	lines = append(lines, "", "Задачи с тегом: /list #тег")
+
+	return c.Send(strings.Join(lines, "\n"))
+}
+
+// mergeTags переименовывает теги sources в target; если target уже есть, теги объединяются
+func (h *Handlers) mergeTags(c telebot.Context, userID int64, sources []string, target string) error {
+	ctx := requestContext(c)
+
+	var lines []string
+	for _, source := range sources {
+		if source == target {
+			continue
+		}
+
+		merged, err := h.repository.RenameTag(ctx, int(userID), source, target)
+		switch {
+		case errors.Is(err, repository.ErrTagNotFound):
+			lines = append(lines, fmt.Sprintf("❓ Тег #%s не найден", source))
+		case err != nil:
+			h.logUserAction(userID, "tags_error", fmt.Sprintf("Rename %s: %v", source, err))
+			lines = append(lines, fmt.Sprintf("❌ Не удалось изменить тег #%s", source))
+		case merged:
+			lines = append(lines, fmt.Sprintf("🔀 #%s объединен с #%s", source, target))
+		default:
+			lines = append(lines, fmt.Sprintf("✏️ #%s переименован в #%s", source, target))
+		}
+	}
+
+	h.logUserAction(userID, "rename_tags", fmt.Sprintf("%v -> %s", sources, target))
+
+	if len(lines) == 0 {
+		return c.Send("ℹ️ Теги не изменились")
+	}
+	return c.Send(strings.Join(lines, "\n"))
+}
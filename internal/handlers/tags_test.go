@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"testing"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addMockTaggedTask(t *testing.T, repo *mockTaskRepository, userID int, description string, tags ...string) {
+	require.NoError(t, repo.AddTask(t.Context(), &models.Task{
+		UserID:              userID,
+		OriginalDescription: description,
+		Status:              models.StatusActive,
+		Tags:                tags,
+	}))
+}
+
+func TestHandleTags(t *testing.T) {
+	t.Run("tags with counts", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTaggedTask(t, repo, 1, "Report", "work")
+		addMockTaggedTask(t, repo, 1, "Call", "phone", "work")
+		addMockTaggedTask(t, repo, 2, "Other user", "gym")
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/tags", "")
+		require.NoError(t, h.handleTags(c))
+
+		assert.Contains(t, c.lastSent(), "#work - 2 (активных: 2)")
+		assert.Contains(t, c.lastSent(), "#phone - 1 (активных: 1)")
+		assert.NotContains(t, c.lastSent(), "#gym")
+	})
+
+	t.Run("no tags", func(t *testing.T) {
+		h := newTestHandlers(newMockTaskRepository())
+
+		c := newCommandContext(1, "/tags", "")
+		require.NoError(t, h.handleTags(c))
+
+		assert.Contains(t, c.lastSent(), "нет тегов")
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTaggedTask(t, repo, 1, "Report", "wrk")
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/tags rename #wrk #Work", "rename #wrk #Work")
+		require.NoError(t, h.handleTags(c))
+
+		assert.Contains(t, c.lastSent(), "✏️ #wrk переименован в #work")
+		assert.Equal(t, []string{"work"}, repo.tasks[1].Tags)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTaggedTask(t, repo, 1, "Report", "job")
+		addMockTaggedTask(t, repo, 1, "Call", "work")
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/tags merge #job #missing #work", "merge #job #missing #work")
+		require.NoError(t, h.handleTags(c))
+
+		assert.Contains(t, c.lastSent(), "🔀 #job объединен с #work")
+		assert.Contains(t, c.lastSent(), "❓ Тег #missing не найден")
+		assert.Equal(t, []string{"work"}, repo.tasks[1].Tags)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		h := newTestHandlers(newMockTaskRepository())
+
+		c := newCommandContext(1, "/tags rename #a", "rename #a")
+		require.NoError(t, h.handleTags(c))
+		assert.Contains(t, c.lastSent(), "Укажите старый и новый тег")
+
+		c = newCommandContext(1, "/tags rename #a b.c", "rename #a b.c")
+		require.NoError(t, h.handleTags(c))
+		assert.Contains(t, c.lastSent(), "Некорректный тег b.c")
+	})
+}
+
+func TestHandleListByTag(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTaggedTask(t, repo, 1, "Report", "work")
+	addMockTaggedTask(t, repo, 1, "Dishes", "home")
+	h := newTestHandlers(repo)
+
+	c := newCommandContext(1, "/add Slides #work", "")
+	require.NoError(t, h.handleAdd(c))
+	assert.Contains(t, c.lastSent(), "🏷 Теги: #work")
+
+	c = newCommandContext(1, "/list #work", "#work")
+	require.NoError(t, h.handleList(c))
+
+	assert.Contains(t, c.lastSent(), "#work")
+	assert.Contains(t, c.lastSent(), "Report")
+	assert.Contains(t, c.lastSent(), "Slides")
+	assert.NotContains(t, c.lastSent(), "Dishes")
+
+	c = newCommandContext(1, "/edit 1 теги: -", "1 теги: -")
+	require.NoError(t, h.handleEdit(c))
+	assert.Empty(t, repo.tasks[1].Tags)
+}
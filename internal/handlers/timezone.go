@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+
+	"gopkg.in/telebot.v3"
+)
+
+const timeZoneUsage = `Использование:
+/tz - текущий часовой пояс
+/tz Europe/Moscow - задать часовой пояс (IANA)
+/tz - - вернуть часовой пояс сервера`
+
+// userSettings возвращает пользователя с его настройками часового пояса и формата дат.
+// Без реестра пользователей или при ошибке возвращаются настройки по умолчанию.
+func (h *Handlers) userSettings(ctx context.Context, userID int64) *models.User {
+	if h.users == nil {
+		return &models.User{ID: int(userID)}
+	}
+
+	user, err := h.users.GetUser(ctx, int(userID))
+	if err != nil {
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			log.Printf("Failed to load settings of user %d: %v", userID, err)
+		}
+		return &models.User{ID: int(userID)}
+	}
+
+	return user
+}
+
+// userLocation возвращает часовой пояс пользователя или часовой пояс сервера
+func (h *Handlers) userLocation(ctx context.Context, userID int64) *time.Location {
+	return h.userSettings(ctx, userID).Location()
+}
+
+// handleTimeZone обрабатывает команду /tz
+func (h *Handlers) handleTimeZone(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		if h.users == nil {
+			return c.Send("ℹ️ Часовые пояса не настроены")
+		}
+
+		args := strings.Fields(c.Message().Payload)
+		if len(args) == 0 {
+			return c.Send(formatTimeZone(h.userLocation(requestContext(c), userID), time.Now()) + "\n\n" + timeZoneUsage)
+		}
+
+		timeZone := args[0]
+		if timeZone == "-" {
+			timeZone = ""
+		} else if _, err := models.LoadTimeZone(timeZone); err != nil {
+			return c.Send(fmt.Sprintf("❌ Неизвестный часовой пояс «%s». Укажите пояс IANA, например Europe/Moscow или Asia/Yekaterinburg", timeZone))
+		}
+
+		if err := h.users.SetTimeZone(requestContext(c), int(userID), timeZone); err != nil {
+			h.logUserAction(userID, "tz_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось сохранить часовой пояс. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "tz", args[0])
+		return c.Send("✅ Часовой пояс сохранен\n\n" + formatTimeZone(h.userLocation(requestContext(c), userID), time.Now()))
+	})
+}
+
+// formatTimeZone описывает часовой пояс и текущее время в нем
+func formatTimeZone(loc *time.Location, now time.Time) string {
+	name := loc.String()
+	if loc == time.Local {
+		name = "часовой пояс сервера"
+	}
+
+	return fmt.Sprintf("🕒 Часовой пояс: %s, сейчас %s", name, now.In(loc).Format("02.01.2006 15:04"))
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleTimeZone(t *testing.T) {
+	setup := func() (*Handlers, *mockTaskRepository, *mockUserRepository) {
+		repo := newMockTaskRepository()
+		users := newMockUserRepository(&models.User{ID: 1, FirstName: "Alice"})
+		return NewHandlers(repo, &mockDiscussionRepository{}, WithUserRepository(users)), repo, users
+	}
+
+	t.Run("show the server's zone by default", func(t *testing.T) {
+		h, _, _ := setup()
+
+		c := newCommandContext(1, "/tz", "")
+		require.NoError(t, h.handleTimeZone(c))
+
+		assert.Contains(t, c.lastSent(), "часовой пояс сервера")
+		assert.Contains(t, c.lastSent(), "Использование")
+	})
+
+	t.Run("set and reset", func(t *testing.T) {
+		h, _, users := setup()
+
+		c := newCommandContext(1, "/tz Asia/Tokyo", "Asia/Tokyo")
+		require.NoError(t, h.handleTimeZone(c))
+		assert.Contains(t, c.lastSent(), "Часовой пояс сохранен")
+		assert.Contains(t, c.lastSent(), "Asia/Tokyo")
+		assert.Equal(t, "Asia/Tokyo", users.users[1].TimeZone)
+
+		c = newCommandContext(1, "/tz -", "-")
+		require.NoError(t, h.handleTimeZone(c))
+		assert.Empty(t, users.users[1].TimeZone)
+	})
+
+	t.Run("unknown zone", func(t *testing.T) {
+		h, _, users := setup()
+
+		c := newCommandContext(1, "/tz Moscow", "Moscow")
+		require.NoError(t, h.handleTimeZone(c))
+		assert.Contains(t, c.lastSent(), "Неизвестный часовой пояс «Moscow»")
+		assert.Empty(t, users.users[1].TimeZone)
+	})
+
+	t.Run("deadlines are read and shown in the user's zone", func(t *testing.T) {
+		h, repo, users := setup()
+		users.users[1].TimeZone = "Asia/Tokyo"
+
+		c := newCommandContext(1, "/add Team call срок: 2025-07-15 09:00", "")
+		require.NoError(t, h.handleAdd(c))
+		assert.Contains(t, c.lastSent(), "Срок: 15.07.2025 09:00")
+
+		task := repo.tasks[1]
+		assert.Equal(t, time.Date(2025, 7, 15, 0, 0, 0, 0, time.UTC), task.Deadline.UTC())
+
+		// Stored in UTC, shown back in the user's zone
+		task.Deadline = task.Deadline.UTC()
+		c = newCommandContext(1, "/list all", "all")
+		require.NoError(t, h.handleList(c))
+		assert.Contains(t, c.lastSent(), "Срок: 15.07.2025 09:00")
+	})
+}
@@ -0,0 +1,206 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"telegram-bot-assistente/internal/repository"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// defaultTrashRetention - сколько задачи хранятся в корзине, если срок не задан
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// Ответы на подтверждение /delete
+const (
+	trashConfirm = "yes"
+	trashCancel  = "no"
+)
+
+// WithTrashRetention задает, через сколько задачи удаляются из корзины навсегда.
+// Само удаление выполняет планировщик, срок нужен для подсказок пользователю.
+func WithTrashRetention(retention time.Duration) Option {
+	return func(h *Handlers) {
+		h.trashRetention = retention
+	}
+}
+
+// handleDelete обрабатывает команду /delete: спрашивает подтверждение перед удалением
+func (h *Handlers) handleDelete(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		args := utils.SplitCommandArgs(c.Message().Payload)
+		if err := h.validateCommand(args, 1); err != nil {
+			return c.Send("❌ Укажите ID задачи. Пример: /delete 3")
+		}
+
+		taskID, err := utils.ParseTaskID(args[0])
+		if err != nil {
+			return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s", err.Error()))
+		}
+
+		task, err := h.getUserTask(ctx, userID, taskID)
+		if err != nil {
+			return c.Send(taskAccessError(taskID, err))
+		}
+
+		id := strconv.Itoa(task.ID)
+		markup := inlineKeyboard([]telebot.InlineButton{
+			inlineButton("🗑 Удалить", callbackTrash, id, trashConfirm),
+			inlineButton("Отмена", callbackTrash, id, trashCancel),
+		})
+
+		return c.Send(fmt.Sprintf("🗑 Удалить задачу %d?\n\n%s\n\nЗадача попадет в корзину, вернуть ее можно командой /restore %d",
+			task.ID, task.GetDescription(), task.ID), markup)
+	})
+}
+
+// handleTrashCallback перемещает задачу в корзину после подтверждения
+func (h *Handlers) handleTrashCallback(c telebot.Context, args []string) error {
+	if len(args) != 2 {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	taskID, err := strconv.Atoi(args[0])
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	switch args[1] {
+	case trashCancel:
+		if err := editCallbackMessage(c, fmt.Sprintf("↩️ Удаление задачи %d отменено", taskID)); err != nil {
+			return err
+		}
+		return c.Respond()
+	case trashConfirm:
+	default:
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Некорректные данные кнопки"})
+	}
+
+	userID := h.getUserID(c)
+	ctx := requestContext(c)
+
+	task, err := h.getUserTask(ctx, userID, taskID)
+	if err != nil {
+		return c.Respond(&telebot.CallbackResponse{Text: taskAccessError(taskID, err)})
+	}
+
+	if err := h.repository.TrashTask(ctx, task.ID); err != nil {
+		h.logUserAction(userID, "delete_task_error", fmt.Sprintf("Task ID: %d, Database error: %v", task.ID, err))
+		return c.Respond(&telebot.CallbackResponse{Text: "❌ Не удалось удалить задачу. Попробуйте позже."})
+	}
+
+	h.logUserAction(userID, "delete_task", fmt.Sprintf("Task ID: %d", task.ID))
+
+	text := fmt.Sprintf("🗑 Задача %d перемещена в корзину\n\nВернуть: /restore %d, содержимое корзины: /trash", task.ID, task.ID)
+	if err := editCallbackMessage(c, text); err != nil {
+		return err
+	}
+
+	return c.Respond()
+}
+
+// handleTrash обрабатывает команду /trash: показывает удаленные задачи и когда они сотрутся
+func (h *Handlers) handleTrash(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		tasks, err := h.repository.GetTrashedTasks(ctx, int(userID))
+		if err != nil {
+			h.logUserAction(userID, "trash_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить корзину. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "trash", fmt.Sprintf("Tasks: %d", len(tasks)))
+
+		if len(tasks) == 0 {
+			return c.Send("🗑 Корзина пуста")
+		}
+
+		loc := h.userLocation(ctx, userID)
+		message := fmt.Sprintf("🗑 Задачи в корзине: %d\nВернуть задачу: /restore [id]", len(tasks))
+		for i, task := range tasks {
+			item := "\n\n" + utils.FormatTaskItem(toTaskInfo(task, loc), i+1)
+			item += "\n   🗑 Удалена " + utils.FormatDateWords(task.DeletedAt.In(loc), true)
+			if h.trashRetention > 0 {
+				item += ", сотрется " + utils.FormatDateWords(task.DeletedAt.Add(h.trashRetention).In(loc), false)
+			}
+			if len(message)+len(item) > utils.MaxMessageLength {
+				break
+			}
+			message += item
+		}
+
+		return c.Send(message)
+	})
+}
+
+// handleRestore обрабатывает команду /restore: возвращает задачу из корзины
+func (h *Handlers) handleRestore(c telebot.Context) error {
+	return h.safeHandle(c, func() error {
+		ctx := requestContext(c)
+
+		userID := h.getUserID(c)
+		if userID == 0 {
+			return c.Send("❌ Не удалось определить пользователя")
+		}
+
+		args := utils.SplitCommandArgs(c.Message().Payload)
+		if err := h.validateCommand(args, 1); err != nil {
+			return c.Send("❌ Укажите ID задачи. Пример: /restore 3\n\nУдаленные задачи: /trash")
+		}
+
+		taskID, err := utils.ParseTaskID(args[0])
+		if err != nil {
+			return c.Send(fmt.Sprintf("❌ Ошибка в команде: %s", err.Error()))
+		}
+
+		// Задачи в корзине не видны GetTask, поэтому владелец проверяется по своей корзине
+		trashed, err := h.repository.GetTrashedTasks(ctx, int(userID))
+		if err != nil {
+			h.logUserAction(userID, "restore_task_error", fmt.Sprintf("Database error: %v", err))
+			return c.Send("❌ Не удалось загрузить корзину. Попробуйте позже.")
+		}
+
+		inTrash := false
+		for _, task := range trashed {
+			if task.ID == taskID {
+				inTrash = true
+				break
+			}
+		}
+
+		if !inTrash {
+			if _, err := h.getUserTask(ctx, userID, taskID); err != nil {
+				if errors.Is(err, repository.ErrTaskNotFound) {
+					return c.Send(fmt.Sprintf("❓ Задачи %d нет в корзине", taskID))
+				}
+				return c.Send(taskAccessError(taskID, err))
+			}
+			return c.Send(fmt.Sprintf("ℹ️ Задача %d не удалена", taskID))
+		}
+
+		if err := h.repository.RestoreTask(ctx, taskID); err != nil {
+			h.logUserAction(userID, "restore_task_error", fmt.Sprintf("Task ID: %d, Database error: %v", taskID, err))
+			return c.Send("❌ Не удалось вернуть задачу. Попробуйте позже.")
+		}
+
+		h.logUserAction(userID, "restore_task", fmt.Sprintf("Task ID: %d", taskID))
+		return c.Send(fmt.Sprintf("♻️ Задача %d возвращена из корзины", taskID))
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleDelete(t *testing.T) {
+	t.Run("confirmed deletion moves the task to the trash", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/delete 1", "1")
+		require.NoError(t, h.handleDelete(c))
+		assert.Contains(t, c.lastSent(), "Удалить задачу 1?")
+		assert.False(t, repo.tasks[1].IsDeleted())
+
+		buttons := c.lastMarkup().InlineKeyboard[0]
+		require.Len(t, buttons, 2)
+
+		cb := newCallbackContext(1, buttons[0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.True(t, repo.tasks[1].IsDeleted())
+		require.Len(t, cb.edited, 1)
+		assert.Contains(t, cb.edited[0], "перемещена в корзину")
+
+		active, err := repo.GetActiveTasks(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+
+		// Повторное нажатие не находит задачу
+		cb = newCallbackContext(1, buttons[0].Data)
+		require.NoError(t, h.handleCallback(cb))
+		require.Len(t, cb.responses, 1)
+		assert.Contains(t, cb.responses[0].Text, "не найдена")
+	})
+
+	t.Run("cancel keeps the task", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/delete 1", "1")
+		require.NoError(t, h.handleDelete(c))
+
+		cb := newCallbackContext(1, c.lastMarkup().InlineKeyboard[0][1].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.False(t, repo.tasks[1].IsDeleted())
+		require.Len(t, cb.edited, 1)
+		assert.Contains(t, cb.edited[0], "отменено")
+	})
+
+	t.Run("another user cannot confirm", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 1, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		c := newCommandContext(1, "/delete 1", "1")
+		require.NoError(t, h.handleDelete(c))
+
+		cb := newCallbackContext(2, c.lastMarkup().InlineKeyboard[0][0].Data)
+		require.NoError(t, h.handleCallback(cb))
+
+		assert.False(t, repo.tasks[1].IsDeleted())
+		require.Len(t, cb.responses, 1)
+		assert.Contains(t, cb.responses[0].Text, "принадлежит другому пользователю")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		repo := newMockTaskRepository()
+		addMockTasks(t, repo, 2, 1, models.StatusActive)
+		h := newTestHandlers(repo)
+
+		cases := map[string]string{
+			"":    "Укажите ID задачи",
+			"abc": "Ошибка в команде",
+			"1":   "принадлежит другому пользователю",
+			"9":   "не найдена",
+		}
+		for payload, expected := range cases {
+			c := newCommandContext(1, "/delete "+payload, payload)
+			require.NoError(t, h.handleDelete(c))
+			assert.Contains(t, c.lastSent(), expected, payload)
+			assert.Nil(t, c.lastMarkup(), payload)
+		}
+	})
+}
+
+func TestHandleTrash(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTasks(t, repo, 1, 3, models.StatusActive)
+	h := NewHandlers(repo, &mockDiscussionRepository{}, WithTrashRetention(7*24*time.Hour))
+
+	c := newCommandContext(1, "/trash", "")
+	require.NoError(t, h.handleTrash(c))
+	assert.Equal(t, "🗑 Корзина пуста", c.lastSent())
+
+	repo.tasks[2].DeletedAt = time.Date(2099, 7, 15, 10, 30, 0, 0, time.Local)
+
+	c = newCommandContext(1, "/trash", "")
+	require.NoError(t, h.handleTrash(c))
+	assert.Contains(t, c.lastSent(), "Задачи в корзине: 1")
+	assert.Contains(t, c.lastSent(), "(ID: 2)")
+	assert.NotContains(t, c.lastSent(), "(ID: 1)")
+	assert.Contains(t, c.lastSent(), "Удалена ср, 15 июля 2099 в 10:30, сотрется ср, 22 июля 2099")
+
+	// Чужая корзина не видна
+	c = newCommandContext(2, "/trash", "")
+	require.NoError(t, h.handleTrash(c))
+	assert.Equal(t, "🗑 Корзина пуста", c.lastSent())
+}
+
+func TestHandleRestore(t *testing.T) {
+	repo := newMockTaskRepository()
+	addMockTasks(t, repo, 1, 2, models.StatusActive) // 1, 2
+	addMockTasks(t, repo, 2, 1, models.StatusActive) // 3
+	require.NoError(t, repo.TrashTask(t.Context(), 1))
+	require.NoError(t, repo.TrashTask(t.Context(), 3))
+	h := newTestHandlers(repo)
+
+	cases := map[string]string{
+		"":  "Укажите ID задачи",
+		"2": "Задача 2 не удалена",
+		"3": "Задачи 3 нет в корзине",
+		"9": "Задачи 9 нет в корзине",
+	}
+	for payload, expected := range cases {
+		c := newCommandContext(1, "/restore "+payload, payload)
+		require.NoError(t, h.handleRestore(c))
+		assert.Contains(t, c.lastSent(), expected, payload)
+	}
+	assert.True(t, repo.tasks[3].IsDeleted())
+
+	c := newCommandContext(1, "/restore 1", "1")
+	require.NoError(t, h.handleRestore(c))
+	assert.Contains(t, c.lastSent(), "Задача 1 возвращена из корзины")
+
+	task, err := repo.GetTask(t.Context(), 1)
+	require.NoError(t, err)
+	assert.False(t, task.IsDeleted())
+}
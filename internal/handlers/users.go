@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"log"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+
+	"gopkg.in/telebot.v3"
+)
+
+// WithUserRepository подключает реестр пользователей, который обновляется при каждом обращении
+func WithUserRepository(users repository.UserRepository) Option {
+	return func(h *Handlers) {
+		h.users = users
+	}
+}
+
+// trackUser сохраняет или обновляет профиль отправителя перед обработкой любого обновления.
+// Ошибки реестра только логируются, чтобы не мешать основной команде.
+func (h *Handlers) trackUser(next telebot.HandlerFunc) telebot.HandlerFunc {
+	return func(c telebot.Context) error {
+		sender := c.Sender()
+		if h.users == nil || sender == nil || sender.IsBot {
+			return next(c)
+		}
+
+		created, err := h.users.UpsertUser(requestContext(c), &models.User{
+			ID:        int(sender.ID),
+			Username:  sender.Username,
+			FirstName: sender.FirstName,
+			LastName:  sender.LastName,
+		})
+		if err != nil {
+			log.Printf("Failed to save user %d: %v", sender.ID, err)
+			return next(c)
+		}
+
+		// Администраторы из конфигурации получают свой тариф при первом обращении
+		if created && h.isAdmin(sender.ID) && h.limiter != nil {
+			if _, err := h.limiter.SetTier(requestContext(c), sender.ID, models.TierAdmin); err != nil {
+				log.Printf("Failed to set admin tier for user %d: %v", sender.ID, err)
+			}
+		}
+
+		return next(c)
+	}
+}
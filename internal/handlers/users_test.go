@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/telebot.v3"
+)
+
+// mockUserRepository is a simple in-memory user registry for testing
+type mockUserRepository struct {
+	users   map[int]*models.User
+	upserts int
+	err     error
+}
+
+func newMockUserRepository(users ...*models.User) *mockUserRepository {
+	m := &mockUserRepository{users: make(map[int]*models.User)}
+	for _, user := range users {
+		m.users[user.ID] = user
+	}
+	return m
+}
+
+func (m *mockUserRepository) UpsertUser(ctx context.Context, user *models.User) (bool, error) {
+	if m.err != nil {
+		return false, m.err
+	}
+	m.upserts++
+	existing, exists := m.users[user.ID]
+	saved := *user
+	if exists {
+		saved.TimeZone = existing.TimeZone
+		saved.DateOrder = existing.DateOrder
+	}
+	m.users[user.ID] = &saved
+	return !exists, nil
+}
+
+func (m *mockUserRepository) GetUser(ctx context.Context, id int) (*models.User, error) {
+	if user, ok := m.users[id]; ok {
+		return user, nil
+	}
+	return nil, fmt.Errorf("%w: id %d", repository.ErrUserNotFound, id)
+}
+
+func (m *mockUserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	username = strings.TrimPrefix(username, "@")
+	for _, user := range m.users {
+		if strings.EqualFold(user.Username, username) {
+			return user, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: @%s", repository.ErrUserNotFound, username)
+}
+
+func (m *mockUserRepository) SetTimeZone(ctx context.Context, userID int, timeZone string) error {
+	if m.err != nil {
+		return m.err
+	}
+	user, ok := m.users[userID]
+	if !ok {
+		return fmt.Errorf("%w: id %d", repository.ErrUserNotFound, userID)
+	}
+	user.TimeZone = timeZone
+	return nil
+}
+
+func (m *mockUserRepository) SetDateOrder(ctx context.Context, userID int, order string) error {
+	if m.err != nil {
+		return m.err
+	}
+	user, ok := m.users[userID]
+	if !ok {
+		return fmt.Errorf("%w: id %d", repository.ErrUserNotFound, userID)
+	}
+	user.DateOrder = order
+	return nil
+}
+
+func TestTrackUser(t *testing.T) {
+	called := 0
+	next := func(c telebot.Context) error {
+		called++
+		return nil
+	}
+
+	t.Run("saves the sender before the handler", func(t *testing.T) {
+		users := newMockUserRepository()
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithUserRepository(users))
+
+		c := newCommandContext(1, "/list", "")
+		c.sender.Username = "alice"
+		require.NoError(t, h.trackUser(next)(c))
+
+		require.Contains(t, users.users, 1)
+		assert.Equal(t, "alice", users.users[1].Username)
+		assert.Equal(t, "Test", users.users[1].FirstName)
+		assert.Equal(t, 1, called)
+	})
+
+	t.Run("bots are not saved", func(t *testing.T) {
+		users := newMockUserRepository()
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithUserRepository(users))
+
+		c := newCommandContext(1, "/list", "")
+		c.sender.IsBot = true
+		require.NoError(t, h.trackUser(next)(c))
+
+		assert.Zero(t, users.upserts)
+	})
+
+	t.Run("registry errors do not block the handler", func(t *testing.T) {
+		users := newMockUserRepository()
+		users.err = errors.New("db is down")
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{}, WithUserRepository(users))
+
+		before := called
+		require.NoError(t, h.trackUser(next)(newCommandContext(1, "/list", "")))
+		assert.Equal(t, before+1, called)
+	})
+
+	t.Run("admins get their tier on first contact", func(t *testing.T) {
+		users := newMockUserRepository()
+		limits := newMockLimiter()
+		h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{},
+			WithUserRepository(users),
+			WithLimiter(limits),
+			WithAdmins(100),
+		)
+
+		require.NoError(t, h.trackUser(next)(newCommandContext(100, "/start", "")))
+		assert.Equal(t, models.TierAdmin, limits.get(100).Tier)
+
+		require.NoError(t, h.trackUser(next)(newCommandContext(1, "/start", "")))
+		assert.Empty(t, limits.get(1).Tier)
+	})
+}
+
+func TestHandleAdminUserLookup(t *testing.T) {
+	const adminID = 100
+
+	users := newMockUserRepository(&models.User{ID: 42, Username: "alice", FirstName: "Alice"})
+	limits := newMockLimiter()
+	h := NewHandlers(newMockTaskRepository(), &mockDiscussionRepository{},
+		WithUserRepository(users),
+		WithLimiter(limits),
+		WithAdmins(adminID),
+	)
+
+	t.Run("by username", func(t *testing.T) {
+		c := newCommandContext(adminID, "/admin premium @Alice on", "premium @Alice on")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "Пользователю 42 выдан премиум")
+		assert.Equal(t, models.TierPremium, limits.get(42).Tier)
+	})
+
+	t.Run("user info shows the name", func(t *testing.T) {
+		c := newCommandContext(adminID, "/admin user @alice", "user @alice")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "👤 Пользователь 42 - @alice")
+	})
+
+	t.Run("unknown username", func(t *testing.T) {
+		c := newCommandContext(adminID, "/admin quota @bob reset", "quota @bob reset")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "Пользователь @bob не найден")
+	})
+
+	t.Run("unknown id", func(t *testing.T) {
+		c := newCommandContext(adminID, "/admin premium 7 on", "premium 7 on")
+		require.NoError(t, h.handleAdmin(c))
+
+		assert.Contains(t, c.lastSent(), "Пользователь 7 не найден")
+		assert.NotContains(t, limits.limits, int64(7))
+	})
+}
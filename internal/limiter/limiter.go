@@ -0,0 +1,30 @@
+// Package limiter enforces per-user quotas on LLM requests.
+package limiter
+
+import (
+	"context"
+	"errors"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// ErrLimitExceeded is returned when a user has used up the quota for the current period
+var ErrLimitExceeded = errors.New("api request limit exceeded")
+
+// Limiter checks and consumes per-user LLM request quotas
+type Limiter interface {
+	// Allow atomically checks the user's quota and consumes one request.
+	// When the quota is used up it returns the current limit and ErrLimitExceeded.
+	Allow(ctx context.Context, userID int64) (*models.APILimit, error)
+	// Refund returns the request consumed by Allow when it was not served.
+	// Nothing is returned if a new period has started since.
+	Refund(ctx context.Context, userID int64, allowed *models.APILimit) (*models.APILimit, error)
+	// Status returns the user's quota without consuming it
+	Status(ctx context.Context, userID int64) (*models.APILimit, error)
+	// Policy returns the quota policy being enforced
+	Policy() models.QuotaPolicy
+	// SetTier changes the user's tier
+	SetTier(ctx context.Context, userID int64, tier string) (*models.APILimit, error)
+	// Reset clears the user's request counter and starts a new period
+	Reset(ctx context.Context, userID int64) (*models.APILimit, error)
+}
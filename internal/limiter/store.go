@@ -0,0 +1,225 @@
+package limiter
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+)
+
+// SQLStore implements Limiter on top of the api_limits table of a SQLite or PostgreSQL database
+type SQLStore struct {
+	db     *sql.DB
+	policy models.QuotaPolicy
+	now    func() time.Time
+}
+
+// NewSQLStore creates a new quota store enforcing the given policy
+func NewSQLStore(database *repository.Database, policy models.QuotaPolicy) *SQLStore {
+	return &SQLStore{
+		db:     database.GetDB(),
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Policy returns the quota policy enforced by the store
+func (s *SQLStore) Policy() models.QuotaPolicy {
+	return s.policy
+}
+
+// Allow atomically checks the user's quota and consumes one request.
+// The whole check-then-increment runs in a single transaction that starts with
+// a write to the user's row, so concurrent calls, also from other bot replicas,
+// are serialized and the quota cannot be overshot.
+func (s *SQLStore) Allow(ctx context.Context, userID int64) (*models.APILimit, error) {
+	now := s.now()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	limit, err := s.lockLimit(ctx, tx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := limit.Rule(s.policy)
+	if !rule.IsUnlimited() && limit.RequestsCount >= rule.Limit {
+		// A lazy reset may have happened, so the row is still committed
+		if err := tx.Commit(); err != nil {
+			return nil, fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return limit, ErrLimitExceeded
+	}
+
+	limit.IncrementRequests()
+	if err := saveLimit(ctx, tx, limit); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return limit, nil
+}
+
+// Refund returns the request consumed by Allow when it was not served, for example
+// because the LLM did not answer. A request of a period that has already been reset
+// is not returned, so the new period does not get an extra request.
+func (s *SQLStore) Refund(ctx context.Context, userID int64, allowed *models.APILimit) (*models.APILimit, error) {
+	return s.update(ctx, userID, func(tx *sql.Tx, limit *models.APILimit) error {
+		if !limit.ResetDate.Equal(allowed.ResetDate) {
+			return nil
+		}
+		limit.RefundRequest()
+		return saveLimit(ctx, tx, limit)
+	})
+}
+
+// Status returns the user's quota without consuming it
+func (s *SQLStore) Status(ctx context.Context, userID int64) (*models.APILimit, error) {
+	now := s.now()
+
+	limit, err := scanLimit(s.db.QueryRowContext(ctx,
+		"SELECT user_id, requests_count, reset_date, is_premium, tier FROM api_limits WHERE user_id = ?", userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		limit = &models.APILimit{UserID: int(userID), Tier: models.TierFree}
+		limit.ResetAt(s.policy, now)
+		return limit, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if limit.ShouldResetAt(now) {
+		limit.ResetAt(s.policy, now)
+	}
+
+	return limit, nil
+}
+
+// SetTier changes the user's tier, keeping is_premium in sync for older readers
+func (s *SQLStore) SetTier(ctx context.Context, userID int64, tier string) (*models.APILimit, error) {
+	if !models.IsValidTier(tier) {
+		return nil, fmt.Errorf("unknown tier %q", tier)
+	}
+
+	return s.update(ctx, userID, func(tx *sql.Tx, limit *models.APILimit) error {
+		limit.Tier = tier
+		limit.IsPremium = tier == models.TierPremium
+		_, err := tx.ExecContext(ctx, "UPDATE api_limits SET tier = ?, is_premium = ? WHERE user_id = ?",
+			limit.Tier, limit.IsPremium, limit.UserID)
+		if err != nil {
+			return fmt.Errorf("failed to update tier: %w", err)
+		}
+		return nil
+	})
+}
+
+// Reset clears the user's request counter and starts a new period
+func (s *SQLStore) Reset(ctx context.Context, userID int64) (*models.APILimit, error) {
+	return s.update(ctx, userID, func(tx *sql.Tx, limit *models.APILimit) error {
+		limit.ResetAt(s.policy, s.now())
+		return saveLimit(ctx, tx, limit)
+	})
+}
+
+// update runs fn on the locked limit of the user within a transaction
+func (s *SQLStore) update(ctx context.Context, userID int64, fn func(tx *sql.Tx, limit *models.APILimit) error) (*models.APILimit, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	limit, err := s.lockLimit(ctx, tx, userID, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := fn(tx, limit); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return limit, nil
+}
+
+// lockLimit creates the user's row if needed, takes the write lock and applies a lazy reset
+func (s *SQLStore) lockLimit(ctx context.Context, tx *sql.Tx, userID int64, now time.Time) (*models.APILimit, error) {
+	initial := &models.APILimit{UserID: int(userID), Tier: models.TierFree}
+	initial.ResetAt(s.policy, now)
+
+	// The first statement of the transaction always writes the row, which acquires the
+	// SQLite write lock or the PostgreSQL row lock before the row is read
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO api_limits (user_id, requests_count, reset_date, is_premium, tier) VALUES (?, 0, ?, FALSE, ?)
+		ON CONFLICT (user_id) DO UPDATE SET user_id = excluded.user_id`,
+		userID, initial.ResetDate, initial.Tier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create api limit: %w", err)
+	}
+
+	limit, err := scanLimit(tx.QueryRowContext(ctx,
+		"SELECT user_id, requests_count, reset_date, is_premium, tier FROM api_limits WHERE user_id = ?", userID))
+	if err != nil {
+		return nil, err
+	}
+
+	if limit.ShouldResetAt(now) {
+		limit.ResetAt(s.policy, now)
+		if err := saveLimit(ctx, tx, limit); err != nil {
+			return nil, err
+		}
+	}
+
+	return limit, nil
+}
+
+// saveLimit stores the request counter and reset date of the limit
+func saveLimit(ctx context.Context, tx *sql.Tx, limit *models.APILimit) error {
+	_, err := tx.ExecContext(ctx,
+		"UPDATE api_limits SET requests_count = ?, reset_date = ? WHERE user_id = ?",
+		limit.RequestsCount, limit.ResetDate, limit.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update api limit: %w", err)
+	}
+	return nil
+}
+
+// scanLimit reads an api_limits row
+func scanLimit(row *sql.Row) (*models.APILimit, error) {
+	var limit models.APILimit
+	var tier sql.NullString
+
+	if err := row.Scan(&limit.UserID, &limit.RequestsCount, &limit.ResetDate, &limit.IsPremium, &tier); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get api limit: %w", err)
+	}
+
+	// The SQLite driver reads a reset date it cannot parse as the zero time
+	if limit.ResetDate.IsZero() {
+		return nil, fmt.Errorf("invalid reset date of api limit for user %d", limit.UserID)
+	}
+	limit.ResetDate = limit.ResetDate.UTC()
+	// Rows created before tiers existed keep the tier implied by is_premium
+	limit.Tier = limit.GetTier()
+	if tier.Valid && tier.String != "" && tier.String != models.TierFree {
+		limit.Tier = tier.String
+	}
+
+	return &limit, nil
+}
@@ -0,0 +1,305 @@
+package limiter
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// monthlyPolicy limits regular users to 10 requests per calendar month
+var monthlyPolicy = models.QuotaPolicy{
+	Default: models.QuotaRule{Period: models.PeriodMonth, Limit: models.DefaultRequestLimit},
+	Tiers: map[string]models.QuotaRule{
+		models.TierPremium: {Period: models.PeriodMonth, Limit: models.Unlimited},
+	},
+}
+
+func setupTestStore(t *testing.T) *SQLStore {
+	return setupTestStoreWithPolicy(t, monthlyPolicy)
+}
+
+func setupTestStoreWithPolicy(t *testing.T, policy models.QuotaPolicy) *SQLStore {
+	db, err := repository.NewDatabase(filepath.Join(t.TempDir(), "limits.db"))
+	if errors.Is(err, repository.ErrNoFTS5) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// Limits reference users, so test users must exist
+	users := repository.NewUserRepository(db)
+	for _, id := range []int{1, 2} {
+		_, err := users.UpsertUser(t.Context(), &models.User{ID: id, FirstName: "Test"})
+		require.NoError(t, err)
+	}
+
+	store := NewSQLStore(db, policy)
+	store.now = func() time.Time { return time.Date(2025, 7, 15, 12, 0, 0, 0, time.UTC) }
+	return store
+}
+
+func TestSQLStore_Allow(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("consumes quota until exhausted", func(t *testing.T) {
+		store := setupTestStore(t)
+
+		for i := 1; i <= models.DefaultRequestLimit; i++ {
+			limit, err := store.Allow(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, i, limit.RequestsCount)
+		}
+
+		limit, err := store.Allow(ctx, 1)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		require.NotNil(t, limit)
+		assert.Equal(t, models.DefaultRequestLimit, limit.RequestsCount)
+		assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), limit.ResetDate.UTC())
+
+		// Other users are not affected
+		_, err = store.Allow(ctx, 2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("lazy reset after reset date", func(t *testing.T) {
+		store := setupTestStore(t)
+		for i := 0; i < models.DefaultRequestLimit; i++ {
+			_, err := store.Allow(ctx, 1)
+			require.NoError(t, err)
+		}
+
+		store.now = func() time.Time { return time.Date(2025, 8, 2, 9, 0, 0, 0, time.UTC) }
+
+		limit, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, limit.RequestsCount)
+		assert.Equal(t, time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), limit.ResetDate.UTC())
+	})
+
+	t.Run("premium users are not limited", func(t *testing.T) {
+		store := setupTestStore(t)
+		_, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		_, err = store.db.Exec("UPDATE api_limits SET tier = 'premium', requests_count = 100 WHERE user_id = 1")
+		require.NoError(t, err)
+
+		limit, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.TierPremium, limit.Tier)
+		assert.Equal(t, 101, limit.RequestsCount)
+	})
+
+	t.Run("legacy is_premium rows are premium", func(t *testing.T) {
+		store := setupTestStore(t)
+		_, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		_, err = store.db.Exec("UPDATE api_limits SET is_premium = 1, requests_count = 100 WHERE user_id = 1")
+		require.NoError(t, err)
+
+		limit, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.TierPremium, limit.Tier)
+	})
+
+	t.Run("tier overrides", func(t *testing.T) {
+		store := setupTestStoreWithPolicy(t, models.QuotaPolicy{
+			Default: models.QuotaRule{Period: models.PeriodDay, Limit: 1},
+			Tiers: map[string]models.QuotaRule{
+				models.TierAdmin: {Period: models.PeriodDay, Limit: 3},
+			},
+		})
+
+		_, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		_, err = store.Allow(ctx, 1)
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		_, err = store.db.Exec("UPDATE api_limits SET tier = 'admin' WHERE user_id = 1")
+		require.NoError(t, err)
+
+		limit, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, limit.RequestsCount)
+		assert.Equal(t, time.Date(2025, 7, 16, 0, 0, 0, 0, time.UTC), limit.ResetDate.UTC())
+	})
+
+	t.Run("rolling window starts with the first request", func(t *testing.T) {
+		store := setupTestStoreWithPolicy(t, models.QuotaPolicy{
+			Default: models.QuotaRule{Period: models.PeriodRolling, Limit: 2, Window: 48 * time.Hour},
+		})
+
+		limit, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 7, 17, 12, 0, 0, 0, time.UTC), limit.ResetDate.UTC())
+
+		store.now = func() time.Time { return time.Date(2025, 7, 17, 12, 30, 0, 0, time.UTC) }
+		limit, err = store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, limit.RequestsCount)
+		assert.Equal(t, time.Date(2025, 7, 19, 12, 30, 0, 0, time.UTC), limit.ResetDate.UTC())
+	})
+
+	t.Run("concurrent requests cannot overshoot", func(t *testing.T) {
+		store := setupTestStore(t)
+
+		const workers = 30
+		var wg sync.WaitGroup
+		var mu sync.Mutex
+		allowed, exceeded := 0, 0
+
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := store.Allow(ctx, 1)
+
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					allowed++
+				case assert.ErrorIs(t, err, ErrLimitExceeded):
+					exceeded++
+				}
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, models.DefaultRequestLimit, allowed)
+		assert.Equal(t, workers-models.DefaultRequestLimit, exceeded)
+
+		limit, err := store.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, models.DefaultRequestLimit, limit.RequestsCount)
+	})
+}
+
+func TestSQLStore_Status(t *testing.T) {
+	ctx := context.Background()
+	store := setupTestStore(t)
+
+	t.Run("unknown user has full quota", func(t *testing.T) {
+		limit, err := store.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 0, limit.RequestsCount)
+		assert.Equal(t, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC), limit.ResetDate.UTC())
+
+		// Status does not create rows
+		var count int
+		require.NoError(t, store.db.QueryRow("SELECT COUNT(*) FROM api_limits").Scan(&count))
+		assert.Zero(t, count)
+	})
+
+	t.Run("does not consume quota", func(t *testing.T) {
+		_, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			limit, err := store.Status(ctx, 1)
+			require.NoError(t, err)
+			assert.Equal(t, 1, limit.RequestsCount)
+		}
+	})
+
+	t.Run("expired period is reported as reset", func(t *testing.T) {
+		store.now = func() time.Time { return time.Date(2025, 8, 5, 0, 0, 0, 0, time.UTC) }
+
+		limit, err := store.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 0, limit.RequestsCount)
+	})
+}
+
+func TestSQLStore_SetTier(t *testing.T) {
+	ctx := context.Background()
+	store := setupTestStore(t)
+
+	limit, err := store.SetTier(ctx, 1, models.TierPremium)
+	require.NoError(t, err)
+	assert.Equal(t, models.TierPremium, limit.Tier)
+	assert.True(t, limit.IsPremium)
+
+	limit, err = store.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.TierPremium, limit.Tier)
+
+	limit, err = store.SetTier(ctx, 1, models.TierFree)
+	require.NoError(t, err)
+	assert.False(t, limit.IsPremium)
+
+	limit, err = store.Status(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, models.TierFree, limit.Tier)
+
+	_, err = store.SetTier(ctx, 1, "vip")
+	assert.Error(t, err)
+
+	// Limits can only be stored for registered users
+	_, err = store.SetTier(ctx, 99, models.TierPremium)
+	assert.Error(t, err)
+}
+
+func TestSQLStore_Refund(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns the consumed request", func(t *testing.T) {
+		store := setupTestStore(t)
+
+		_, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		allowed, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 2, allowed.RequestsCount)
+
+		limit, err := store.Refund(ctx, 1, allowed)
+		require.NoError(t, err)
+		assert.Equal(t, 1, limit.RequestsCount)
+
+		limit, err = store.Status(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 1, limit.RequestsCount)
+	})
+
+	t.Run("a new period gets no extra request", func(t *testing.T) {
+		store := setupTestStore(t)
+
+		allowed, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+
+		store.now = func() time.Time { return time.Date(2025, 8, 2, 9, 0, 0, 0, time.UTC) }
+		_, err = store.Allow(ctx, 1)
+		require.NoError(t, err)
+
+		limit, err := store.Refund(ctx, 1, allowed)
+		require.NoError(t, err)
+		assert.Equal(t, 1, limit.RequestsCount)
+	})
+}
+
+func TestSQLStore_Reset(t *testing.T) {
+	ctx := context.Background()
+	store := setupTestStore(t)
+
+	for i := 0; i < models.DefaultRequestLimit; i++ {
+		_, err := store.Allow(ctx, 1)
+		require.NoError(t, err)
+	}
+	_, err := store.Allow(ctx, 1)
+	require.ErrorIs(t, err, ErrLimitExceeded)
+
+	limit, err := store.Reset(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 0, limit.RequestsCount)
+
+	_, err = store.Allow(ctx, 1)
+	assert.NoError(t, err)
+}
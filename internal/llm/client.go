@@ -0,0 +1,36 @@
+package llm
+
+import "context"
+
+// Client defines the interface for LLM chat completion providers
+type Client interface {
+	// Complete sends the conversation to the model and returns its reply
+	Complete(ctx context.Context, req *Request) (*Response, error)
+}
+
+// Message roles
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+// Message represents a single message of a conversation
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// Request represents a chat completion request
+type Request struct {
+	Messages    []Message
+	Temperature float64 // 0 means the provider default
+	MaxTokens   int     // 0 means the provider default
+}
+
+// Response represents a chat completion result
+type Response struct {
+	Content      string
+	FinishReason string
+	TotalTokens  int
+}
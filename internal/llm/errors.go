@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Typed errors returned by LLM clients. Use errors.Is to check for them.
+var (
+	ErrTimeout       = errors.New("llm request timed out")
+	ErrUnauthorized  = errors.New("llm request unauthorized")
+	ErrRateLimited   = errors.New("llm rate limit exceeded")
+	ErrEmptyResponse = errors.New("llm returned an empty response")
+)
+
+// MiniMax business error codes (base_resp.status_code)
+const (
+	miniMaxCodeUnknown        = 1000
+	miniMaxCodeTimeout        = 1001
+	miniMaxCodeRateLimit      = 1002
+	miniMaxCodeAuthFailed     = 1004
+	miniMaxCodeInternal       = 1013
+	miniMaxCodeTokenRateLimit = 1039
+)
+
+// APIError represents an error returned by the LLM provider
+type APIError struct {
+	StatusCode int    // HTTP status code
+	Code       int    // Provider-specific error code
+	Message    string // Provider error message
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Code != 0 {
+		return fmt.Sprintf("llm api error (http %d, code %d): %s", e.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Sprintf("llm api error (http %d): %s", e.StatusCode, e.Message)
+}
+
+// Is allows matching an APIError against the typed sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden ||
+			e.Code == miniMaxCodeAuthFailed
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests ||
+			e.Code == miniMaxCodeRateLimit || e.Code == miniMaxCodeTokenRateLimit
+	case ErrTimeout:
+		return e.StatusCode == http.StatusGatewayTimeout || e.Code == miniMaxCodeTimeout
+	default:
+		return false
+	}
+}
+
+// Retryable reports whether the request may succeed if repeated
+func (e *APIError) Retryable() bool {
+	if e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= http.StatusInternalServerError {
+		return true
+	}
+
+	switch e.Code {
+	case miniMaxCodeUnknown, miniMaxCodeTimeout, miniMaxCodeRateLimit, miniMaxCodeInternal, miniMaxCodeTokenRateLimit:
+		return true
+	default:
+		return false
+	}
+}
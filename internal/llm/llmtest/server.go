@@ -0,0 +1,178 @@
+// Package llmtest provides an in-process fake of the MiniMax chat completion API
+// for offline tests of code that depends on llm.Client.
+package llmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/llm"
+)
+
+// APIKey is the key accepted by the fake server
+const APIKey = "test-api-key"
+
+// Reply describes how the fake server answers a single request
+type Reply struct {
+	Content    string        // Assistant message content
+	StatusCode int           // HTTP status code, defaults to 200
+	ErrorCode  int           // MiniMax base_resp.status_code, 0 means success
+	ErrorMsg   string        // MiniMax base_resp.status_msg
+	RetryAfter int           // Retry-After header in seconds
+	Delay      time.Duration // Delay before answering, useful to trigger timeouts
+}
+
+// Request is a chat completion request received by the fake server
+type Request struct {
+	Model       string        `json:"model"`
+	Messages    []llm.Message `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+// Server is a fake MiniMax API backed by httptest.Server.
+// Queued replies are returned in order; when the queue is empty,
+// the Respond function produces the reply.
+type Server struct {
+	*httptest.Server
+
+	// Respond builds the default reply, by default it echoes the last user message
+	Respond func(req Request) Reply
+
+	mu       sync.Mutex
+	queue    []Reply
+	requests []Request
+}
+
+// NewServer starts a fake server that is closed when the test finishes
+func NewServer(t testing.TB) *Server {
+	s := &Server{Respond: echo}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Enqueue adds replies returned to the next requests
+func (s *Server) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queue = append(s.queue, replies...)
+}
+
+// Requests returns all requests received so far
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+// Config returns a client configuration pointing to the fake server without retry delays
+func (s *Server) Config() llm.MiniMaxConfig {
+	return llm.MiniMaxConfig{
+		APIKey:     APIKey,
+		BaseURL:    s.URL,
+		Timeout:    5 * time.Second,
+		RetryDelay: time.Millisecond,
+	}
+}
+
+// Client returns a MiniMax client connected to the fake server
+func (s *Server) Client(t testing.TB) *llm.MiniMaxClient {
+	client, err := llm.NewMiniMaxClient(s.Config())
+	if err != nil {
+		t.Fatalf("failed to create llm client: %v", err)
+	}
+	return client
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.URL.Path != "/text/chatcompletion_v2" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if r.Header.Get("Authorization") != "Bearer "+APIKey {
+		writeJSON(w, http.StatusUnauthorized, errorBody(1004, "authorization failed"))
+		return
+	}
+
+	var req Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorBody(2013, "invalid params: "+err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	var reply Reply
+	if len(s.queue) > 0 {
+		reply = s.queue[0]
+		s.queue = s.queue[1:]
+	} else {
+		reply = s.Respond(req)
+	}
+	s.mu.Unlock()
+
+	if reply.Delay > 0 {
+		select {
+		case <-time.After(reply.Delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	if reply.RetryAfter > 0 {
+		w.Header().Set("Retry-After", fmt.Sprint(reply.RetryAfter))
+	}
+
+	status := reply.StatusCode
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	if reply.ErrorCode != 0 || status != http.StatusOK {
+		writeJSON(w, status, errorBody(reply.ErrorCode, reply.ErrorMsg))
+		return
+	}
+
+	writeJSON(w, status, map[string]interface{}{
+		"id":     "fake",
+		"model":  req.Model,
+		"object": "chat.completion",
+		"choices": []map[string]interface{}{{
+			"index":         0,
+			"finish_reason": "stop",
+			"message":       map[string]string{"role": llm.RoleAssistant, "content": reply.Content},
+		}},
+		"usage":     map[string]int{"total_tokens": len(reply.Content)},
+		"base_resp": map[string]interface{}{"status_code": 0, "status_msg": "success"},
+	})
+}
+
+// echo replies with the last user message
+func echo(req Request) Reply {
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == llm.RoleUser {
+			return Reply{Content: "Echo: " + strings.TrimSpace(req.Messages[i].Content)}
+		}
+	}
+	return Reply{Content: "Echo"}
+}
+
+func errorBody(code int, msg string) map[string]interface{} {
+	return map[string]interface{}{
+		"base_resp": map[string]interface{}{"status_code": code, "status_msg": msg},
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
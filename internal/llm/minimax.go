@@ -0,0 +1,291 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MiniMax API defaults
+const (
+	DefaultMiniMaxBaseURL = "https://api.minimax.chat/v1"
+	DefaultMiniMaxModel   = "abab6.5s-chat"
+	DefaultTimeout        = 30 * time.Second
+	DefaultMaxRetries     = 2
+	DefaultRetryDelay     = 500 * time.Millisecond
+
+	// maxRetryDelay caps the exponential backoff
+	maxRetryDelay = 10 * time.Second
+	// maxResponseSize limits how much of a response body is read
+	maxResponseSize = 1 << 20
+)
+
+// MiniMaxConfig holds the settings of the MiniMax client
+type MiniMaxConfig struct {
+	APIKey     string
+	BaseURL    string        // Defaults to DefaultMiniMaxBaseURL
+	Model      string        // Defaults to DefaultMiniMaxModel
+	Timeout    time.Duration // Timeout of a single attempt, defaults to DefaultTimeout
+	MaxRetries int           // Retries after the first attempt, defaults to DefaultMaxRetries; negative disables retries
+	RetryDelay time.Duration // Initial backoff delay, doubled after every retry
+	HTTPClient *http.Client  // Defaults to a new http.Client
+}
+
+// MiniMaxClient implements Client for the MiniMax chat completion API
+type MiniMaxClient struct {
+	apiKey     string
+	endpoint   string
+	model      string
+	timeout    time.Duration
+	maxRetries int
+	retryDelay time.Duration
+	httpClient *http.Client
+}
+
+// NewMiniMaxClient creates a new MiniMax client
+func NewMiniMaxClient(cfg MiniMaxConfig) (*MiniMaxClient, error) {
+	if strings.TrimSpace(cfg.APIKey) == "" {
+		return nil, errors.New("minimax api key is required")
+	}
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultMiniMaxBaseURL
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultMiniMaxModel
+	}
+
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	maxRetries := cfg.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = DefaultMaxRetries
+	}
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+
+	retryDelay := cfg.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = DefaultRetryDelay
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{}
+	}
+
+	return &MiniMaxClient{
+		apiKey:     cfg.APIKey,
+		endpoint:   strings.TrimRight(baseURL, "/") + "/text/chatcompletion_v2",
+		model:      model,
+		timeout:    timeout,
+		maxRetries: maxRetries,
+		retryDelay: retryDelay,
+		httpClient: httpClient,
+	}, nil
+}
+
+// miniMaxRequest is the wire format of a chat completion request
+type miniMaxRequest struct {
+	Model       string    `json:"model"`
+	Messages    []Message `json:"messages"`
+	Temperature float64   `json:"temperature,omitempty"`
+	MaxTokens   int       `json:"max_tokens,omitempty"`
+}
+
+// miniMaxResponse is the wire format of a chat completion response
+type miniMaxResponse struct {
+	Choices []struct {
+		FinishReason string  `json:"finish_reason"`
+		Message      Message `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		TotalTokens int `json:"total_tokens"`
+	} `json:"usage"`
+	BaseResp struct {
+		StatusCode int    `json:"status_code"`
+		StatusMsg  string `json:"status_msg"`
+	} `json:"base_resp"`
+}
+
+// Complete sends a chat completion request, retrying transient failures with exponential backoff
+func (c *MiniMaxClient) Complete(ctx context.Context, req *Request) (*Response, error) {
+	if req == nil || len(req.Messages) == 0 {
+		return nil, errors.New("llm request must contain at least one message")
+	}
+
+	body, err := json.Marshal(miniMaxRequest{
+		Model:       c.model,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode llm request: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			if err := c.wait(ctx, attempt, lastErr); err != nil {
+				return nil, fmt.Errorf("llm request cancelled after %d attempts: %w (last error: %v)", attempt, err, lastErr)
+			}
+		}
+
+		resp, err := c.do(ctx, body)
+		if err == nil {
+			return resp, nil
+		}
+
+		lastErr = err
+		if !isRetryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// do performs a single attempt limited by the client timeout
+func (c *MiniMaxClient) do(ctx context.Context, body []byte) (*Response, error) {
+	attemptCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(attemptCtx, http.MethodPost, c.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create llm request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		// Cancellation by the caller is not a timeout and must not be retried
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %s", ErrTimeout, c.timeout)
+		}
+		return nil, &networkError{err: err}
+	}
+	defer httpResp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %s", ErrTimeout, c.timeout)
+		}
+		return nil, &networkError{err: err}
+	}
+
+	if httpResp.StatusCode != http.StatusOK {
+		apiErr := &APIError{StatusCode: httpResp.StatusCode, Message: strings.TrimSpace(string(data))}
+		var parsed miniMaxResponse
+		if json.Unmarshal(data, &parsed) == nil && parsed.BaseResp.StatusCode != 0 {
+			apiErr.Code = parsed.BaseResp.StatusCode
+			apiErr.Message = parsed.BaseResp.StatusMsg
+		}
+		return nil, &retryAfterError{APIError: apiErr, delay: parseRetryAfter(httpResp.Header.Get("Retry-After"))}
+	}
+
+	var parsed miniMaxResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to decode llm response: %w", err)
+	}
+
+	// MiniMax reports business errors with HTTP 200 and a non-zero base_resp code
+	if parsed.BaseResp.StatusCode != 0 {
+		return nil, &APIError{
+			StatusCode: httpResp.StatusCode,
+			Code:       parsed.BaseResp.StatusCode,
+			Message:    parsed.BaseResp.StatusMsg,
+		}
+	}
+
+	if len(parsed.Choices) == 0 || strings.TrimSpace(parsed.Choices[0].Message.Content) == "" {
+		return nil, ErrEmptyResponse
+	}
+
+	return &Response{
+		Content:      strings.TrimSpace(parsed.Choices[0].Message.Content),
+		FinishReason: parsed.Choices[0].FinishReason,
+		TotalTokens:  parsed.Usage.TotalTokens,
+	}, nil
+}
+
+// wait sleeps before the next attempt, honouring Retry-After and context cancellation
+func (c *MiniMaxClient) wait(ctx context.Context, attempt int, lastErr error) error {
+	delay := c.retryDelay << (attempt - 1)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+
+	var retryAfter *retryAfterError
+	if errors.As(lastErr, &retryAfter) && retryAfter.delay > delay {
+		delay = min(retryAfter.delay, maxRetryDelay)
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// isRetryable reports whether a failed attempt should be repeated
+func isRetryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Retryable()
+	}
+
+	var netErr *networkError
+	return errors.Is(err, ErrTimeout) || errors.As(err, &netErr)
+}
+
+// networkError wraps transport-level failures, which are always retryable
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return "llm request failed: " + e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+// retryAfterError carries the delay requested by the server in the Retry-After header
+type retryAfterError struct {
+	*APIError
+	delay time.Duration
+}
+
+func (e *retryAfterError) Unwrap() error { return e.APIError }
+
+// parseRetryAfter parses the Retry-After header given in seconds
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,190 @@
+package llm_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/llm"
+	"telegram-bot-assistente/internal/llm/llmtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequest(text string) *llm.Request {
+	return &llm.Request{
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: "You are a helpful assistant"},
+			{Role: llm.RoleUser, Content: text},
+		},
+		Temperature: 0.3,
+		MaxTokens:   200,
+	}
+}
+
+func TestNewMiniMaxClient(t *testing.T) {
+	_, err := llm.NewMiniMaxClient(llm.MiniMaxConfig{})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "api key is required")
+
+	client, err := llm.NewMiniMaxClient(llm.MiniMaxConfig{APIKey: "key"})
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+}
+
+func TestMiniMaxClient_Complete(t *testing.T) {
+	t.Run("successful completion", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(llmtest.Reply{Content: "  Купить молоко и хлеб  "})
+
+		resp, err := server.Client(t).Complete(context.Background(), newRequest("купить продукты"))
+		require.NoError(t, err)
+		assert.Equal(t, "Купить молоко и хлеб", resp.Content)
+		assert.Equal(t, "stop", resp.FinishReason)
+
+		requests := server.Requests()
+		require.Len(t, requests, 1)
+		assert.Equal(t, llm.DefaultMiniMaxModel, requests[0].Model)
+		assert.Len(t, requests[0].Messages, 2)
+		assert.Equal(t, "купить продукты", requests[0].Messages[1].Content)
+		assert.Equal(t, 200, requests[0].MaxTokens)
+	})
+
+	t.Run("default echo reply", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+
+		resp, err := server.Client(t).Complete(context.Background(), newRequest("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "Echo: hello", resp.Content)
+	})
+
+	t.Run("empty request", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+
+		_, err := server.Client(t).Complete(context.Background(), &llm.Request{})
+		assert.Error(t, err)
+		assert.Empty(t, server.Requests())
+	})
+
+	t.Run("retries server errors", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(
+			llmtest.Reply{StatusCode: http.StatusInternalServerError},
+			llmtest.Reply{ErrorCode: 1002, ErrorMsg: "rate limit"},
+			llmtest.Reply{Content: "ok"},
+		)
+
+		resp, err := server.Client(t).Complete(context.Background(), newRequest("hello"))
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp.Content)
+		assert.Len(t, server.Requests(), 3)
+	})
+
+	t.Run("gives up after max retries", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Respond = func(llmtest.Request) llmtest.Reply {
+			return llmtest.Reply{StatusCode: http.StatusTooManyRequests}
+		}
+
+		_, err := server.Client(t).Complete(context.Background(), newRequest("hello"))
+		assert.ErrorIs(t, err, llm.ErrRateLimited)
+		assert.Len(t, server.Requests(), 1+llm.DefaultMaxRetries)
+
+		var apiErr *llm.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusTooManyRequests, apiErr.StatusCode)
+	})
+
+	t.Run("unauthorized is not retried", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		cfg := server.Config()
+		cfg.APIKey = "wrong-key"
+		client, err := llm.NewMiniMaxClient(cfg)
+		require.NoError(t, err)
+
+		_, err = client.Complete(context.Background(), newRequest("hello"))
+		assert.ErrorIs(t, err, llm.ErrUnauthorized)
+		assert.Empty(t, server.Requests())
+	})
+
+	t.Run("business error is not retried", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(llmtest.Reply{ErrorCode: 2013, ErrorMsg: "invalid params"})
+
+		_, err := server.Client(t).Complete(context.Background(), newRequest("hello"))
+		var apiErr *llm.APIError
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, 2013, apiErr.Code)
+		assert.Len(t, server.Requests(), 1)
+	})
+
+	t.Run("empty response", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(llmtest.Reply{Content: "   "})
+
+		_, err := server.Client(t).Complete(context.Background(), newRequest("hello"))
+		assert.ErrorIs(t, err, llm.ErrEmptyResponse)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Respond = func(llmtest.Request) llmtest.Reply {
+			return llmtest.Reply{Content: "late", Delay: time.Second}
+		}
+		cfg := server.Config()
+		cfg.Timeout = 50 * time.Millisecond
+		cfg.MaxRetries = 1
+		client, err := llm.NewMiniMaxClient(cfg)
+		require.NoError(t, err)
+
+		_, err = client.Complete(context.Background(), newRequest("hello"))
+		assert.ErrorIs(t, err, llm.ErrTimeout)
+		assert.Len(t, server.Requests(), 2)
+	})
+
+	t.Run("context cancellation stops retries", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Respond = func(llmtest.Request) llmtest.Reply {
+			return llmtest.Reply{Content: "late", Delay: time.Second}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_, err := server.Client(t).Complete(ctx, newRequest("hello"))
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, llm.ErrTimeout)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Len(t, server.Requests(), 1)
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       *llm.APIError
+		target    error
+		retryable bool
+	}{
+		{"http 401", &llm.APIError{StatusCode: 401}, llm.ErrUnauthorized, false},
+		{"minimax auth code", &llm.APIError{StatusCode: 200, Code: 1004}, llm.ErrUnauthorized, false},
+		{"http 429", &llm.APIError{StatusCode: 429}, llm.ErrRateLimited, true},
+		{"minimax rate limit code", &llm.APIError{StatusCode: 200, Code: 1002}, llm.ErrRateLimited, true},
+		{"minimax timeout code", &llm.APIError{StatusCode: 200, Code: 1001}, llm.ErrTimeout, true},
+		{"http 503", &llm.APIError{StatusCode: 503}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.target != nil {
+				assert.ErrorIs(t, tt.err, tt.target)
+			}
+			assert.Equal(t, tt.retryable, tt.err.Retryable())
+			assert.NotEmpty(t, tt.err.Error())
+		})
+	}
+}
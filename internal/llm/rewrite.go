@@ -0,0 +1,77 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// MaxRewriteLength limits the length of a rewritten task description in characters
+const MaxRewriteLength = 500
+
+const (
+	rewriteTemperature    = 0.3
+	regenerateTemperature = 0.9
+	rewriteMaxTokens      = 300
+	rewriteSystemPrompt   = `Ты помощник по планированию задач. Перепиши описание задачи пользователя так, чтобы оно стало понятным и выполнимым действием:
+- начни с глагола в повелительной форме;
+- сохрани все детали: имена, места, суммы, даты и время;
+- ничего не выдумывай и не добавляй новых шагов;
+- пиши на языке исходного описания.
+Ответь только новым описанием одной строкой, без кавычек, пояснений и разметки.`
+)
+
+// RewriteTask asks the model for a clarified, actionable version of a task description.
+// Previous suggestions are passed to the model so that a regenerated variant differs from them.
+func RewriteTask(ctx context.Context, client Client, description string, previous ...string) (string, error) {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return "", fmt.Errorf("task description is empty")
+	}
+
+	prompt := "Описание задачи: " + description
+	temperature := rewriteTemperature
+	if len(previous) > 0 {
+		prompt += "\n\nПредложи другой вариант, не повторяя эти:\n- " + strings.Join(previous, "\n- ")
+		temperature = regenerateTemperature
+	}
+
+	resp, err := client.Complete(ctx, &Request{
+		Messages: []Message{
+			{Role: RoleSystem, Content: rewriteSystemPrompt},
+			{Role: RoleUser, Content: prompt},
+		},
+		Temperature: temperature,
+		MaxTokens:   rewriteMaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	rewrite := cleanRewrite(resp.Content)
+	if rewrite == "" {
+		return "", ErrEmptyResponse
+	}
+
+	return rewrite, nil
+}
+
+// cleanRewrite keeps the first non-empty line of the model output without
+// surrounding quotes and limits its length
+func cleanRewrite(content string) string {
+	var line string
+	for _, l := range strings.Split(content, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			line = l
+			break
+		}
+	}
+
+	line = strings.TrimSpace(strings.Trim(line, "\"'«»`"))
+
+	if runes := []rune(line); len(runes) > MaxRewriteLength {
+		line = strings.TrimSpace(string(runes[:MaxRewriteLength]))
+	}
+
+	return line
+}
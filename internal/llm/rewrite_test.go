@@ -0,0 +1,78 @@
+package llm_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"telegram-bot-assistente/internal/llm"
+	"telegram-bot-assistente/internal/llm/llmtest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteTask(t *testing.T) {
+	t.Run("rewrite", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(llmtest.Reply{Content: "«Купить молоко и хлеб в магазине у дома»\n\nПояснение: ..."})
+
+		rewrite, err := llm.RewriteTask(context.Background(), server.Client(t), "  молоко хлеб  ")
+		require.NoError(t, err)
+		assert.Equal(t, "Купить молоко и хлеб в магазине у дома", rewrite)
+
+		requests := server.Requests()
+		require.Len(t, requests, 1)
+		require.Len(t, requests[0].Messages, 2)
+		assert.Equal(t, llm.RoleSystem, requests[0].Messages[0].Role)
+		assert.Equal(t, "Описание задачи: молоко хлеб", requests[0].Messages[1].Content)
+	})
+
+	t.Run("regenerate mentions previous suggestions", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+
+		_, err := llm.RewriteTask(context.Background(), server.Client(t), "молоко", "Купить молоко")
+		require.NoError(t, err)
+
+		requests := server.Requests()
+		require.Len(t, requests, 1)
+		assert.Contains(t, requests[0].Messages[1].Content, "- Купить молоко")
+		assert.Greater(t, requests[0].Temperature, 0.5)
+	})
+
+	t.Run("long rewrite is truncated", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(llmtest.Reply{Content: strings.Repeat("я", llm.MaxRewriteLength+10)})
+
+		rewrite, err := llm.RewriteTask(context.Background(), server.Client(t), "задача")
+		require.NoError(t, err)
+		assert.Len(t, []rune(rewrite), llm.MaxRewriteLength)
+	})
+
+	t.Run("quotes only", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Enqueue(llmtest.Reply{Content: `""`})
+
+		_, err := llm.RewriteTask(context.Background(), server.Client(t), "задача")
+		assert.ErrorIs(t, err, llm.ErrEmptyResponse)
+	})
+
+	t.Run("empty description", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+
+		_, err := llm.RewriteTask(context.Background(), server.Client(t), "   ")
+		assert.Error(t, err)
+		assert.Empty(t, server.Requests())
+	})
+
+	t.Run("client error", func(t *testing.T) {
+		server := llmtest.NewServer(t)
+		server.Respond = func(llmtest.Request) llmtest.Reply {
+			return llmtest.Reply{StatusCode: http.StatusTooManyRequests}
+		}
+
+		_, err := llm.RewriteTask(context.Background(), server.Client(t), "задача")
+		assert.ErrorIs(t, err, llm.ErrRateLimited)
+	})
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Discussion represents a message attached to a task
+type Discussion struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	MessageID int       `json:"message_id"` // Telegram message ID
+	Text      string    `json:"text"`
+	Timestamp time.Time `json:"timestamp"` // When the original message was written
+}
+
+// Validate validates the discussion data
+func (d *Discussion) Validate() error {
+	if d.TaskID <= 0 {
+		return errors.New("task_id must be a positive integer")
+	}
+
+	if d.MessageID <= 0 {
+		return errors.New("message_id must be a positive integer")
+	}
+
+	if strings.TrimSpace(d.Text) == "" {
+		return errors.New("text cannot be empty")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the discussion
+func (d *Discussion) SetDefaults() {
+	if d.Timestamp.IsZero() {
+		d.Timestamp = time.Now()
+	}
+}
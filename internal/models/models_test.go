@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"testing"
 	"time"
 )
@@ -20,6 +21,61 @@ func TestTaskValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid priority",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Test task",
+				Priority:            PriorityLow,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid priority",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Test task",
+				Priority:            5,
+			},
+			wantErr: true,
+		},
+		{
+			name: "deadline in year 9999",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Test task",
+				Deadline:            time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: false,
+		},
+		{
+			name: "deadline after year 9999",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Test task",
+				Deadline:            time.Date(12026, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
+		{
+			name: "deadline after year 9999 in UTC",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Test task",
+				Deadline:            time.Date(9999, 12, 31, 23, 0, 0, 0, time.FixedZone("UTC-5", -5*60*60)),
+			},
+			wantErr: true,
+		},
+		{
+			name: "postponed after year 9999",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Test task",
+				Status:              StatusPostponed,
+				PostponedUntil:      time.Date(275816, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty description",
 			task: Task{
@@ -56,6 +112,35 @@ func TestTaskValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "recurring task",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Weekly report",
+				Deadline:            time.Date(2025, 7, 14, 23, 59, 59, 0, time.UTC),
+				Recurrence:          "FREQ=WEEKLY;BYDAY=MO",
+			},
+			wantErr: false,
+		},
+		{
+			name: "recurring task without deadline",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Weekly report",
+				Recurrence:          "FREQ=WEEKLY;BYDAY=MO",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid recurrence",
+			task: Task{
+				UserID:              123,
+				OriginalDescription: "Weekly report",
+				Deadline:            time.Date(2025, 7, 14, 23, 59, 59, 0, time.UTC),
+				Recurrence:          "FREQ=HOURLY",
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid status",
 			task: Task{
@@ -129,6 +214,20 @@ func TestTaskStatusMethods(t *testing.T) {
 	if !postponedTask.IsPostponed() {
 		t.Error("Postponed task should return true for IsPostponed()")
 	}
+
+	until := time.Date(2025, 7, 16, 9, 0, 0, 0, time.UTC)
+	activeTask.Postpone(until)
+	if !activeTask.IsPostponed() || !activeTask.PostponedUntil.Equal(until) {
+		t.Errorf("Postpone() = %s until %v, want postponed until %v", activeTask.Status, activeTask.PostponedUntil, until)
+	}
+
+	if doneTask.IsDeleted() {
+		t.Error("Task without deleted_at should return false for IsDeleted()")
+	}
+	doneTask.DeletedAt = until
+	if !doneTask.IsDeleted() {
+		t.Error("Task with deleted_at should return true for IsDeleted()")
+	}
 }
 
 func TestTaskDeadlineMethods(t *testing.T) {
@@ -245,6 +344,42 @@ func TestUserValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid time zone",
+			user: User{
+				ID:        123,
+				FirstName: "John",
+				TimeZone:  "Europe/Moscow",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown time zone",
+			user: User{
+				ID:        123,
+				FirstName: "John",
+				TimeZone:  "Europe/Atlantis",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid date order",
+			user: User{
+				ID:        123,
+				FirstName: "John",
+				DateOrder: DateOrderMDY,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown date order",
+			user: User{
+				ID:        123,
+				FirstName: "John",
+				DateOrder: "dm",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -257,6 +392,27 @@ func TestUserValidate(t *testing.T) {
 	}
 }
 
+func TestUserLocation(t *testing.T) {
+	user := User{ID: 123, FirstName: "John"}
+	if user.Location() != time.Local {
+		t.Error("User without a time zone should use the server's zone")
+	}
+
+	user.TimeZone = "Asia/Tokyo"
+	if got := user.Location().String(); got != "Asia/Tokyo" {
+		t.Errorf("Location() = %s, want Asia/Tokyo", got)
+	}
+
+	user.TimeZone = "Europe/Atlantis"
+	if user.Location() != time.Local {
+		t.Error("Unknown time zone should fall back to the server's zone")
+	}
+
+	if _, err := LoadTimeZone("Local"); err == nil {
+		t.Error("LoadTimeZone should reject the server-dependent Local zone")
+	}
+}
+
 func TestUserGetFullName(t *testing.T) {
 	userWithLastName := User{
 		FirstName: "John",
@@ -361,16 +517,18 @@ func TestAPILimitCanMakeRequest(t *testing.T) {
 		IsPremium:     false,
 	}
 
-	if !premiumUser.CanMakeRequest() {
+	policy := DefaultQuotaPolicy()
+
+	if !premiumUser.CanMakeRequest(policy) {
 		t.Error("Premium user should always be able to make requests")
 	}
-	if !regularUserUnderLimit.CanMakeRequest() {
+	if !regularUserUnderLimit.CanMakeRequest(policy) {
 		t.Error("Regular user under limit should be able to make requests")
 	}
-	if regularUserOverLimit.CanMakeRequest() {
+	if regularUserOverLimit.CanMakeRequest(policy) {
 		t.Error("Regular user over limit should not be able to make requests")
 	}
-	if !regularUserExpiredLimit.CanMakeRequest() {
+	if !regularUserExpiredLimit.CanMakeRequest(policy) {
 		t.Error("Regular user with expired limit should be able to make requests")
 	}
 }
@@ -387,11 +545,551 @@ func TestAPILimitGetRemainingRequests(t *testing.T) {
 		ResetDate:     time.Now().Add(24 * time.Hour),
 		IsPremium:     false,
 	}
+	policy := DefaultQuotaPolicy()
 
-	if premiumUser.GetRemainingRequests() != -1 {
+	if premiumUser.GetRemainingRequests(policy) != -1 {
 		t.Error("Premium user should have unlimited requests (-1)")
 	}
-	if regularUser.GetRemainingRequests() != 7 {
+	if regularUser.GetRemainingRequests(policy) != 7 {
 		t.Error("Regular user should have 7 remaining requests")
 	}
 }
+
+func TestDiffTasks(t *testing.T) {
+	deadline := time.Date(2025, 7, 15, 23, 59, 59, 0, time.UTC)
+	before := &Task{
+		ID:                  7,
+		OriginalDescription: "Old",
+		Deadline:            deadline,
+		Status:              StatusActive,
+	}
+
+	t.Run("no changes", func(t *testing.T) {
+		after := *before
+		if changes := DiffTasks(before, &after, 123); len(changes) != 0 {
+			t.Errorf("Expected no changes, got %d", len(changes))
+		}
+	})
+
+	t.Run("all fields changed", func(t *testing.T) {
+		after := *before
+		after.OriginalDescription = "New"
+		after.Deadline = time.Time{}
+		after.Status = StatusDone
+		after.Priority = PriorityHigh
+
+		changes := DiffTasks(before, &after, 123)
+		if len(changes) != 4 {
+			t.Fatalf("Expected 4 changes, got %d", len(changes))
+		}
+
+		if changes[0].Field != FieldDescription || changes[0].OldValue != "Old" || changes[0].NewValue != "New" {
+			t.Errorf("Unexpected description change: %+v", changes[0])
+		}
+		if changes[1].Field != FieldDeadline || changes[1].OldValue != "2025-07-15T23:59:59Z all-day" || changes[1].NewValue != "" {
+			t.Errorf("Unexpected deadline change: %+v", changes[1])
+		}
+		if changes[2].Field != FieldStatus || changes[2].NewValue != StatusDone {
+			t.Errorf("Unexpected status change: %+v", changes[2])
+		}
+		if changes[3].Field != FieldPriority || changes[3].OldValue != "" || changes[3].NewValue != "2" {
+			t.Errorf("Unexpected priority change: %+v", changes[3])
+		}
+		for _, change := range changes {
+			if change.TaskID != 7 || change.UserID != 123 {
+				t.Errorf("Change should reference task 7 and user 123: %+v", change)
+			}
+		}
+	})
+}
+
+func TestDeadlineValue(t *testing.T) {
+	deadline := time.Date(2025, 7, 15, 20, 59, 59, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		task    *Task
+		value   string
+		hasTime bool
+	}{
+		{
+			name:  "all-day deadline",
+			task:  &Task{ID: 1, Deadline: deadline},
+			value: "2025-07-15T20:59:59Z all-day",
+		},
+		{
+			name:    "deadline with time",
+			task:    &Task{ID: 1, Deadline: deadline, DeadlineHasTime: true},
+			value:   "2025-07-15T20:59:59Z",
+			hasTime: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := formatDeadline(tt.task)
+			if value != tt.value {
+				t.Errorf("formatDeadline() = %q, want %q", value, tt.value)
+			}
+
+			parsed, hasTime, err := ParseDeadlineValue(value)
+			if err != nil {
+				t.Fatalf("ParseDeadlineValue() error = %v", err)
+			}
+			if !parsed.Equal(deadline) || hasTime != tt.hasTime {
+				t.Errorf("ParseDeadlineValue() = %v, %v, want %v, %v", parsed, hasTime, deadline, tt.hasTime)
+			}
+		})
+	}
+
+	t.Run("the same moment in another zone is not a change", func(t *testing.T) {
+		before := &Task{ID: 1, Deadline: deadline, DeadlineHasTime: true}
+		after := *before
+		after.Deadline = deadline.In(time.FixedZone("MSK", 3*60*60))
+		if changes := DiffTasks(before, &after, 123); len(changes) != 0 {
+			t.Errorf("Expected no changes, got %+v", changes[0])
+		}
+		if value := formatDeadline(&after); value != "2025-07-15T20:59:59Z" {
+			t.Errorf("formatDeadline() = %q, want the UTC time", value)
+		}
+	})
+
+	t.Run("switching to a time at the same moment is a change", func(t *testing.T) {
+		before := &Task{ID: 1, Deadline: deadline}
+		after := *before
+		after.DeadlineHasTime = true
+		if changes := DiffTasks(before, &after, 123); len(changes) != 1 {
+			t.Errorf("Expected 1 change, got %d", len(changes))
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		if _, _, err := ParseDeadlineValue("tomorrow"); err == nil {
+			t.Error("Expected an error for an invalid value")
+		}
+	})
+}
+
+func TestTaskChangeValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  TaskChange
+		wantErr bool
+	}{
+		{
+			name:    "valid change",
+			change:  TaskChange{TaskID: 1, UserID: 123, Field: FieldStatus},
+			wantErr: false,
+		},
+		{
+			name:    "invalid task id",
+			change:  TaskChange{TaskID: 0, UserID: 123, Field: FieldStatus},
+			wantErr: true,
+		},
+		{
+			name:    "invalid user id",
+			change:  TaskChange{TaskID: 1, UserID: 0, Field: FieldStatus},
+			wantErr: true,
+		},
+		{
+			name:    "empty field",
+			change:  TaskChange{TaskID: 1, UserID: 123},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.change.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("TaskChange.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiscussionValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		discussion Discussion
+		wantErr    bool
+	}{
+		{
+			name:       "valid discussion",
+			discussion: Discussion{TaskID: 1, MessageID: 10, Text: "Hello"},
+			wantErr:    false,
+		},
+		{
+			name:       "invalid task id",
+			discussion: Discussion{TaskID: 0, MessageID: 10, Text: "Hello"},
+			wantErr:    true,
+		},
+		{
+			name:       "invalid message id",
+			discussion: Discussion{TaskID: 1, MessageID: 0, Text: "Hello"},
+			wantErr:    true,
+		},
+		{
+			name:       "empty text",
+			discussion: Discussion{TaskID: 1, MessageID: 10, Text: "  "},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.discussion.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Discussion.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPILimitResetAt(t *testing.T) {
+	now := time.Date(2025, 12, 15, 10, 0, 0, 0, time.UTC) // Monday
+	limit := APILimit{UserID: 123, RequestsCount: 7, ResetDate: now.Add(-time.Hour)}
+
+	if !limit.ShouldResetAt(now) {
+		t.Error("Limit with past reset date should be reset")
+	}
+
+	limit.ResetAt(DefaultQuotaPolicy(), now)
+
+	if limit.RequestsCount != 0 {
+		t.Errorf("Expected requests count 0 after reset, got %d", limit.RequestsCount)
+	}
+	expected := time.Date(2025, 12, 22, 0, 0, 0, 0, time.UTC)
+	if !limit.ResetDate.Equal(expected) {
+		t.Errorf("Expected reset date %v, got %v", expected, limit.ResetDate)
+	}
+	if limit.ShouldResetAt(now) {
+		t.Error("Limit should not be reset right after reset")
+	}
+}
+
+func TestQuotaRuleNextReset(t *testing.T) {
+	now := time.Date(2025, 7, 16, 15, 30, 0, 0, time.UTC) // Wednesday
+
+	tests := []struct {
+		name string
+		rule QuotaRule
+		want time.Time
+	}{
+		{"day", QuotaRule{Period: PeriodDay}, time.Date(2025, 7, 17, 0, 0, 0, 0, time.UTC)},
+		{"week", QuotaRule{Period: PeriodWeek}, time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC)},
+		{"month", QuotaRule{Period: PeriodMonth}, time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{"rolling", QuotaRule{Period: PeriodRolling, Window: 48 * time.Hour}, time.Date(2025, 7, 18, 15, 30, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.NextReset(now); !got.Equal(tt.want) {
+				t.Errorf("QuotaRule.NextReset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	sunday := time.Date(2025, 7, 20, 23, 0, 0, 0, time.UTC)
+	monday := time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC)
+	if got := (QuotaRule{Period: PeriodWeek}).NextReset(sunday); !got.Equal(monday) {
+		t.Errorf("Week starting on Sunday should reset on Monday, got %v", got)
+	}
+	if got := (QuotaRule{Period: PeriodWeek}).NextReset(monday); !got.Equal(monday.AddDate(0, 0, 7)) {
+		t.Errorf("Week starting on Monday should reset next Monday, got %v", got)
+	}
+}
+
+func TestQuotaPolicy(t *testing.T) {
+	policy := QuotaPolicy{
+		Default: QuotaRule{Period: PeriodDay, Limit: 3},
+		Tiers: map[string]QuotaRule{
+			TierPremium: {Period: PeriodMonth, Limit: 100},
+		},
+	}
+
+	if err := policy.Validate(); err != nil {
+		t.Errorf("QuotaPolicy.Validate() unexpected error = %v", err)
+	}
+	if got := policy.Rule(TierPremium).Limit; got != 100 {
+		t.Errorf("Expected premium limit 100, got %d", got)
+	}
+	if got := policy.Rule(TierAdmin).Limit; got != 3 {
+		t.Errorf("Tier without override should use the default limit, got %d", got)
+	}
+
+	limit := APILimit{UserID: 1, RequestsCount: 3, ResetDate: time.Now().Add(time.Hour), Tier: TierFree}
+	if limit.CanMakeRequest(policy) {
+		t.Error("Free user at the limit should not be able to make requests")
+	}
+	limit.Tier = TierPremium
+	if !limit.CanMakeRequest(policy) {
+		t.Error("Premium user under the premium limit should be able to make requests")
+	}
+	if got := limit.GetRemainingRequests(policy); got != 97 {
+		t.Errorf("Expected 97 remaining premium requests, got %d", got)
+	}
+
+	invalid := []QuotaPolicy{
+		{Default: QuotaRule{Period: "year", Limit: 1}},
+		{Default: QuotaRule{Period: PeriodRolling, Limit: 1}},
+		{Default: QuotaRule{Period: PeriodDay, Limit: 1}, Tiers: map[string]QuotaRule{"vip": {Period: PeriodDay}}},
+	}
+	for _, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("QuotaPolicy.Validate() expected error for %+v", p)
+		}
+	}
+}
+
+func TestAPILimitGetTier(t *testing.T) {
+	tests := []struct {
+		limit APILimit
+		want  string
+	}{
+		{APILimit{}, TierFree},
+		{APILimit{IsPremium: true}, TierPremium},
+		{APILimit{Tier: TierAdmin}, TierAdmin},
+	}
+
+	for _, tt := range tests {
+		if got := tt.limit.GetTier(); got != tt.want {
+			t.Errorf("APILimit.GetTier() = %v, want %v", got, tt.want)
+		}
+	}
+}
+
+func TestReminderSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		leadTimes []time.Duration
+		wantErr   bool
+	}{
+		{"defaults", DefaultReminderLeadTimes, false},
+		{"overdue only", nil, false},
+		{"too short", []time.Duration{time.Minute}, true},
+		{"too long", []time.Duration{30 * 24 * time.Hour}, true},
+		{"duplicates", []time.Duration{time.Hour, time.Hour}, true},
+		{"too many", []time.Duration{time.Hour, 2 * time.Hour, 3 * time.Hour, 4 * time.Hour, 5 * time.Hour, 6 * time.Hour}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := &ReminderSettings{UserID: 1, Enabled: true, LeadTimes: tt.leadTimes}
+			err := settings.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestReminderSettingsDueReminder(t *testing.T) {
+	deadline := time.Date(2025, 7, 15, 18, 0, 0, 0, time.UTC)
+	settings := DefaultReminderSettings(1)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		wantKind string
+		wantDue  bool
+	}{
+		{"two days before", deadline.Add(-48 * time.Hour), "", false},
+		{"one day before", deadline.Add(-24 * time.Hour), "lead:1440", true},
+		{"three hours before", deadline.Add(-3 * time.Hour), "lead:1440", true},
+		{"one hour before", deadline.Add(-time.Hour), "lead:60", true},
+		{"at the deadline", deadline, ReminderOverdue, true},
+		{"missed recently", deadline.Add(24 * time.Hour), ReminderOverdue, true},
+		{"missed long ago", deadline.Add(MaxOverdueNoticeAge + time.Hour), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kind, due := settings.DueReminder(deadline, tt.now)
+			if kind != tt.wantKind || due != tt.wantDue {
+				t.Errorf("DueReminder() = (%q, %v), want (%q, %v)", kind, due, tt.wantKind, tt.wantDue)
+			}
+		})
+	}
+
+	disabled := DefaultReminderSettings(1)
+	disabled.Enabled = false
+	if _, due := disabled.DueReminder(deadline, deadline); due {
+		t.Error("Disabled reminders should never be due")
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		rule    string
+		want    string
+		wantErr bool
+	}{
+		{rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{rule: "RRULE:FREQ=DAILY;INTERVAL=3", want: "FREQ=DAILY;INTERVAL=3"},
+		{rule: "freq=weekly;byday=fr,mo,fr", want: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{rule: "FREQ=WEEKLY;INTERVAL=1;BYDAY=SU,MO", want: "FREQ=WEEKLY;BYDAY=MO,SU"},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=-1", want: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{rule: "FREQ=DAILY;UNTIL=20251231", want: "FREQ=DAILY;UNTIL=20251231T235959Z"},
+		{rule: "", wantErr: true},
+		{rule: "FREQ=YEARLY", wantErr: true},
+		{rule: "FREQ=DAILY;COUNT=5", wantErr: true},
+		{rule: "FREQ=DAILY;BYDAY=MO", wantErr: true},
+		{rule: "FREQ=WEEKLY;BYDAY=1MO", wantErr: true},
+		{rule: "FREQ=MONTHLY;BYMONTHDAY=32", wantErr: true},
+		{rule: "FREQ=DAILY;INTERVAL=-2", wantErr: true},
+		{rule: "INTERVAL=2", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseRRule(%q) error = %v, wantErr %v", tt.rule, err, tt.wantErr)
+			}
+			if err == nil && rule.String() != tt.want {
+				t.Errorf("ParseRRule(%q).String() = %q, want %q", tt.rule, rule.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestRecurrenceNext(t *testing.T) {
+	// Wednesday
+	after := time.Date(2025, 7, 16, 23, 59, 59, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time {
+		return time.Date(2025, month, d, 23, 59, 59, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		rule  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "daily", rule: "FREQ=DAILY", after: after, want: day(time.July, 17)},
+		{name: "every 3 days", rule: "FREQ=DAILY;INTERVAL=3", after: after, want: day(time.July, 19)},
+		{name: "weekly on the same weekday", rule: "FREQ=WEEKLY", after: after, want: day(time.July, 23)},
+		{name: "later this week", rule: "FREQ=WEEKLY;BYDAY=MO,FR", after: after, want: day(time.July, 18)},
+		{name: "next week", rule: "FREQ=WEEKLY;BYDAY=MO,TU", after: after, want: day(time.July, 21)},
+		{name: "every other week later this week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE,FR", after: after, want: day(time.July, 18)},
+		{name: "every other week skips a week", rule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", after: after, want: day(time.July, 28)},
+		{name: "monthly by day", rule: "FREQ=MONTHLY;BYMONTHDAY=1", after: after, want: day(time.August, 1)},
+		{name: "monthly later this month", rule: "FREQ=MONTHLY;BYMONTHDAY=20", after: after, want: day(time.July, 20)},
+		{name: "every 3 months", rule: "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=16", after: after, want: day(time.October, 16)},
+		{name: "last day of the month", rule: "FREQ=MONTHLY;BYMONTHDAY=-1", after: after, want: day(time.July, 31)},
+		{
+			name:  "short months get their last day",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			after: day(time.January, 31),
+			want:  day(time.February, 28),
+		},
+		{
+			name:  "the day is kept after a short month",
+			rule:  "FREQ=MONTHLY;BYMONTHDAY=31",
+			after: day(time.February, 28),
+			want:  day(time.March, 31),
+		},
+		{name: "until ends the series", rule: "FREQ=DAILY;UNTIL=20250716", after: after, want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q) error = %v", tt.rule, err)
+			}
+			if got := rule.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%v) = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+
+	rule := &Recurrence{Freq: FreqWeekly, Weekdays: []time.Weekday{time.Wednesday}}
+	if got := rule.First(after); !got.Equal(after) {
+		t.Errorf("First() = %v, want the same day %v", got, after)
+	}
+}
+
+func TestTaskNextOccurrence(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Monday 23:59:59 in Moscow is still Monday evening in UTC
+	deadline := time.Date(2025, 7, 14, 23, 59, 59, 0, moscow).UTC()
+	task := &Task{
+		ID:                  5,
+		UserID:              123,
+		OriginalDescription: "Weekly report",
+		Deadline:            deadline,
+		Status:              StatusDone,
+		Recurrence:          "FREQ=WEEKLY;BYDAY=MO",
+	}
+
+	next, err := task.NextOccurrence(moscow, deadline)
+	if err != nil {
+		t.Fatalf("NextOccurrence() error = %v", err)
+	}
+	if want := time.Date(2025, 7, 21, 23, 59, 59, 0, moscow); !next.Deadline.Equal(want) {
+		t.Errorf("NextOccurrence().Deadline = %v, want %v", next.Deadline, want)
+	}
+	if next.SeriesID != 5 || next.Status != StatusActive || next.Recurrence != task.Recurrence {
+		t.Errorf("NextOccurrence() = %+v, want an active task of series 5", next)
+	}
+
+	// Occurrences already in the past are skipped
+	now := time.Date(2025, 8, 1, 12, 0, 0, 0, moscow)
+	next, err = task.NextOccurrence(moscow, now)
+	if err != nil {
+		t.Fatalf("NextOccurrence() error = %v", err)
+	}
+	if want := time.Date(2025, 8, 4, 23, 59, 59, 0, moscow); !next.Deadline.Equal(want) {
+		t.Errorf("NextOccurrence().Deadline = %v, want %v", next.Deadline, want)
+	}
+
+	oneOff := &Task{ID: 6, UserID: 123, OriginalDescription: "Once", Deadline: deadline}
+	if next, err := oneOff.NextOccurrence(moscow, deadline); next != nil || err != nil {
+		t.Errorf("NextOccurrence() of a one-off task = %v, %v, want nil, nil", next, err)
+	}
+}
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "#Работа", want: "работа"},
+		{input: "home_2-b", want: "home_2-b"},
+		{input: "#", wantErr: true},
+		{input: "two words", wantErr: true},
+		{input: "#a.b", wantErr: true},
+		{input: strings.Repeat("a", MaxTagLength+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := NormalizeTag(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("NormalizeTag(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("NormalizeTag(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+
+	tags, err := NormalizeTags([]string{"Work", "#home", "work"})
+	if err != nil {
+		t.Fatalf("NormalizeTags() error = %v", err)
+	}
+	if strings.Join(tags, " ") != "home work" {
+		t.Errorf("NormalizeTags() = %v, want [home work]", tags)
+	}
+
+	task := &Task{ID: 1, UserID: 123, OriginalDescription: "Report", Status: StatusActive, Tags: []string{"Work"}}
+	if err := task.Validate(); err == nil {
+		t.Error("Validate() accepted a tag that is not normalized")
+	}
+}
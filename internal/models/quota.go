@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// User tiers with separate LLM request limits
+const (
+	TierFree    = "free"
+	TierPremium = "premium"
+	TierAdmin   = "admin"
+)
+
+// Quota periods
+const (
+	PeriodDay     = "day"
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodRolling = "rolling"
+)
+
+// Unlimited is the request limit of tiers without restrictions
+const Unlimited = -1
+
+// DefaultRollingWindow is the length of a rolling window unless configured otherwise
+const DefaultRollingWindow = 7 * 24 * time.Hour
+
+// QuotaRule describes how many LLM requests a tier can make per period.
+// Calendar periods (day, week, month) reset at their start in the local zone;
+// a rolling window starts with the first request after the previous window expired.
+type QuotaRule struct {
+	Period string        `json:"period"`
+	Limit  int           `json:"limit"`  // Negative means unlimited
+	Window time.Duration `json:"window"` // Length of a rolling window
+}
+
+// QuotaPolicy holds the default rule and per-tier overrides
+type QuotaPolicy struct {
+	Default QuotaRule            `json:"default"`
+	Tiers   map[string]QuotaRule `json:"tiers"`
+}
+
+// DefaultQuotaPolicy returns the built-in policy: 10 requests per week for
+// regular users and no limits for premium users and admins
+func DefaultQuotaPolicy() QuotaPolicy {
+	return QuotaPolicy{
+		Default: QuotaRule{Period: PeriodWeek, Limit: DefaultRequestLimit, Window: DefaultRollingWindow},
+		Tiers: map[string]QuotaRule{
+			TierPremium: {Period: PeriodWeek, Limit: Unlimited, Window: DefaultRollingWindow},
+			TierAdmin:   {Period: PeriodWeek, Limit: Unlimited, Window: DefaultRollingWindow},
+		},
+	}
+}
+
+// Rule returns the rule of the tier, falling back to the default rule
+func (p QuotaPolicy) Rule(tier string) QuotaRule {
+	if rule, ok := p.Tiers[tier]; ok {
+		return rule
+	}
+	return p.Default
+}
+
+// Validate validates the default rule and all tier overrides
+func (p QuotaPolicy) Validate() error {
+	if err := p.Default.Validate(); err != nil {
+		return fmt.Errorf("default quota: %w", err)
+	}
+
+	for tier, rule := range p.Tiers {
+		if !IsValidTier(tier) {
+			return fmt.Errorf("unknown quota tier %q", tier)
+		}
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("%s quota: %w", tier, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the quota rule
+func (r QuotaRule) Validate() error {
+	switch r.Period {
+	case PeriodDay, PeriodWeek, PeriodMonth:
+	case PeriodRolling:
+		if r.Window <= 0 {
+			return errors.New("rolling window must be positive")
+		}
+	default:
+		return fmt.Errorf("period must be one of: day, week, month, rolling, got %q", r.Period)
+	}
+
+	return nil
+}
+
+// IsUnlimited reports whether the rule has no request limit
+func (r QuotaRule) IsUnlimited() bool {
+	return r.Limit < 0
+}
+
+// NextReset returns the moment when a period starting at now ends
+func (r QuotaRule) NextReset(now time.Time) time.Time {
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch r.Period {
+	case PeriodDay:
+		return midnight.AddDate(0, 0, 1)
+	case PeriodWeek:
+		// Weeks start on Monday
+		daysUntilMonday := (8 - int(now.Weekday())) % 7
+		if daysUntilMonday == 0 {
+			daysUntilMonday = 7
+		}
+		return midnight.AddDate(0, 0, daysUntilMonday)
+	case PeriodRolling:
+		return now.Add(r.Window)
+	default:
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	}
+}
+
+// IsValidTier checks if the tier is known
+func IsValidTier(tier string) bool {
+	switch tier {
+	case TierFree, TierPremium, TierAdmin:
+		return true
+	default:
+		return false
+	}
+}
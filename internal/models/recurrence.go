@@ -0,0 +1,285 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies, named as in RFC 5545
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+)
+
+// LastDayOfMonth is the BYMONTHDAY value for the last day of every month
+const LastDayOfMonth = -1
+
+// maxRecurrenceInterval keeps the search for the next occurrence bounded
+const maxRecurrenceInterval = 365
+
+// Recurrence describes how a task repeats. It supports the subset of RFC 5545 RRULE
+// used by the bot: FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, BYDAY (weekly),
+// BYMONTHDAY (monthly) and UNTIL.
+type Recurrence struct {
+	Freq     string
+	Interval int            // Every Interval days, weeks or months; 0 means 1
+	Weekdays []time.Weekday // Weekly: days of the week, empty means the weekday of the first occurrence
+	MonthDay int            // Monthly: day of the month or LastDayOfMonth, 0 means the day of the first occurrence
+	Until    time.Time      // Last possible occurrence, zero means forever
+}
+
+// rruleWeekdays maps RRULE weekday codes to weekdays
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+// rruleUntilFormats are the UNTIL forms accepted by ParseRRule
+var rruleUntilFormats = []string{"20060102T150405Z", "20060102"}
+
+// ParseRRule parses an RRULE such as "FREQ=WEEKLY;BYDAY=MO,FR" with an optional "RRULE:" prefix
+func ParseRRule(rule string) (*Recurrence, error) {
+	rule = strings.TrimSpace(rule)
+	rule = strings.TrimPrefix(strings.ToUpper(rule), "RRULE:")
+	if rule == "" {
+		return nil, errors.New("empty recurrence rule")
+	}
+
+	r := &Recurrence{}
+	for _, part := range strings.Split(rule, ";") {
+		name, value, found := strings.Cut(part, "=")
+		if !found || value == "" {
+			return nil, fmt.Errorf("invalid recurrence rule part %q", part)
+		}
+
+		switch name {
+		case "FREQ":
+			r.Freq = value
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid INTERVAL %q", value)
+			}
+			r.Interval = interval
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				weekday, ok := rruleWeekdays[code]
+				if !ok {
+					return nil, fmt.Errorf("unsupported BYDAY value %q", code)
+				}
+				r.Weekdays = append(r.Weekdays, weekday)
+			}
+		case "BYMONTHDAY":
+			day, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid BYMONTHDAY %q", value)
+			}
+			r.MonthDay = day
+		case "UNTIL":
+			until, err := parseRRuleUntil(value)
+			if err != nil {
+				return nil, err
+			}
+			r.Until = until
+		default:
+			return nil, fmt.Errorf("unsupported recurrence rule part %s", name)
+		}
+	}
+
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+
+	r.normalize()
+	return r, nil
+}
+
+// parseRRuleUntil parses UNTIL as a UTC time or a date, which includes the whole day
+func parseRRuleUntil(value string) (time.Time, error) {
+	for _, format := range rruleUntilFormats {
+		if until, err := time.Parse(format, value); err == nil {
+			if format == "20060102" {
+				until = until.Add(24*time.Hour - time.Second)
+			}
+			return until, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid UNTIL %q", value)
+}
+
+// Validate validates the recurrence
+func (r *Recurrence) Validate() error {
+	switch r.Freq {
+	case FreqDaily, FreqWeekly, FreqMonthly:
+	case "":
+		return errors.New("recurrence frequency is required")
+	default:
+		return fmt.Errorf("unsupported recurrence frequency %s", r.Freq)
+	}
+
+	if r.Interval < 0 || r.Interval > maxRecurrenceInterval {
+		return fmt.Errorf("recurrence interval must be between 1 and %d", maxRecurrenceInterval)
+	}
+
+	if len(r.Weekdays) > 0 && r.Freq != FreqWeekly {
+		return errors.New("BYDAY is only supported for weekly recurrence")
+	}
+
+	if r.MonthDay != 0 {
+		if r.Freq != FreqMonthly {
+			return errors.New("BYMONTHDAY is only supported for monthly recurrence")
+		}
+		if r.MonthDay != LastDayOfMonth && (r.MonthDay < 1 || r.MonthDay > 31) {
+			return errors.New("BYMONTHDAY must be between 1 and 31 or -1")
+		}
+	}
+
+	return nil
+}
+
+// normalize sorts and deduplicates weekdays starting from Monday so that equal rules print the same
+func (r *Recurrence) normalize() {
+	if r.Interval == 1 {
+		r.Interval = 0
+	}
+
+	seen := make(map[time.Weekday]bool)
+	weekdays := r.Weekdays[:0]
+	for _, weekday := range r.Weekdays {
+		if !seen[weekday] {
+			seen[weekday] = true
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	sort.Slice(weekdays, func(i, j int) bool {
+		return weekPosition(weekdays[i]) < weekPosition(weekdays[j])
+	})
+	r.Weekdays = weekdays
+}
+
+// String formats the recurrence as an RRULE without the "RRULE:" prefix
+func (r *Recurrence) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.Weekdays) > 0 {
+		codes := make([]string, len(r.Weekdays))
+		for i, weekday := range r.Weekdays {
+			codes[i] = strings.ToUpper(weekday.String()[:2])
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.MonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.MonthDay))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(rruleUntilFormats[0]))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Anchor fixes the weekday or the day of the month that the rule leaves open
+// ("every week", "every month") to those of the first occurrence
+func (r *Recurrence) Anchor(first time.Time) {
+	switch {
+	case r.Freq == FreqWeekly && len(r.Weekdays) == 0:
+		r.Weekdays = []time.Weekday{first.Weekday()}
+	case r.Freq == FreqMonthly && r.MonthDay == 0:
+		r.MonthDay = first.Day()
+	}
+}
+
+// First returns the first occurrence on or after the day of from, at the time of day of from.
+// The zero time means that the series ends before it.
+func (r *Recurrence) First(from time.Time) time.Time {
+	single := *r
+	single.Interval = 0
+	return single.Next(from.AddDate(0, 0, -1))
+}
+
+// Next returns the first occurrence after the given one, keeping its time of day and zone.
+// Occurrences are counted from the given one, so INTERVAL works relative to it.
+// Months without the requested day get the occurrence on their last day.
+// The zero time means that the series has ended.
+func (r *Recurrence) Next(after time.Time) time.Time {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var next time.Time
+	switch r.Freq {
+	case FreqDaily:
+		next = after.AddDate(0, 0, interval)
+	case FreqWeekly:
+		next = r.nextWeekly(after, interval)
+	case FreqMonthly:
+		next = r.nextMonthly(after, interval)
+	}
+
+	if next.IsZero() || (!r.Until.IsZero() && next.After(r.Until)) {
+		return time.Time{}
+	}
+	return next
+}
+
+// nextWeekly finds the next listed weekday in every interval-th week starting on Monday
+func (r *Recurrence) nextWeekly(after time.Time, interval int) time.Time {
+	if len(r.Weekdays) == 0 {
+		return after.AddDate(0, 0, 7*interval)
+	}
+
+	weekdays := make(map[time.Weekday]bool, len(r.Weekdays))
+	for _, weekday := range r.Weekdays {
+		weekdays[weekday] = true
+	}
+
+	weekStart := after.AddDate(0, 0, -weekPosition(after.Weekday()))
+	for days := 1; days <= 7*interval+7; days++ {
+		candidate := after.AddDate(0, 0, days)
+		weeks := int(candidate.Sub(weekStart).Hours()/24+0.5) / 7
+		if weekdays[candidate.Weekday()] && weeks%interval == 0 {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+// nextMonthly finds the requested day in the following interval-th month
+func (r *Recurrence) nextMonthly(after time.Time, interval int) time.Time {
+	day := r.MonthDay
+	if day == 0 {
+		day = after.Day()
+	}
+
+	for months := 0; months <= interval; months += interval {
+		candidate := monthDay(after, months, day)
+		if candidate.After(after) {
+			return candidate
+		}
+	}
+
+	return time.Time{}
+}
+
+// monthDay returns the day of the month that is months after t, clamped to the month's length
+func monthDay(t time.Time, months, day int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	if day == LastDayOfMonth || day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+// weekPosition returns the index of the weekday in a week starting on Monday
+func weekPosition(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
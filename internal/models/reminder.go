@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Reminder limits
+const (
+	MaxReminderLeadTimes = 5
+	MinReminderLeadTime  = 5 * time.Minute
+	MaxReminderLeadTime  = 7 * 24 * time.Hour
+
+	// MaxOverdueNoticeAge limits overdue notices to recently missed deadlines,
+	// so enabling reminders does not flood users with notices about old tasks
+	MaxOverdueNoticeAge = 7 * 24 * time.Hour
+)
+
+// ReminderOverdue is the kind of the notice sent once a deadline has passed
+const ReminderOverdue = "overdue"
+
+// DefaultReminderLeadTimes are used until the user configures their own: 1 day and 1 hour before the deadline
+var DefaultReminderLeadTimes = []time.Duration{24 * time.Hour, time.Hour}
+
+// ReminderSettings holds the deadline reminder preferences of a user
+type ReminderSettings struct {
+	UserID    int             `json:"user_id"`
+	Enabled   bool            `json:"enabled"`
+	LeadTimes []time.Duration `json:"lead_times"` // How long before the deadline to remind
+	TimeZone  string          `json:"time_zone"`  // Zone of the user for the reminder text; read-only, set with /tz
+}
+
+// Location returns the time zone reminders are rendered in
+func (s *ReminderSettings) Location() *time.Location {
+	return locationOrLocal(s.TimeZone)
+}
+
+// DefaultReminderSettings returns enabled reminders with the default lead times
+func DefaultReminderSettings(userID int) *ReminderSettings {
+	return &ReminderSettings{
+		UserID:    userID,
+		Enabled:   true,
+		LeadTimes: append([]time.Duration(nil), DefaultReminderLeadTimes...),
+	}
+}
+
+// Validate validates the reminder settings
+func (s *ReminderSettings) Validate() error {
+	if s.UserID <= 0 {
+		return errors.New("user_id must be a positive integer")
+	}
+
+	if len(s.LeadTimes) > MaxReminderLeadTimes {
+		return fmt.Errorf("at most %d lead times are allowed", MaxReminderLeadTimes)
+	}
+
+	seen := make(map[time.Duration]bool)
+	for _, lead := range s.LeadTimes {
+		if lead < MinReminderLeadTime || lead > MaxReminderLeadTime {
+			return fmt.Errorf("lead time must be between %v and %v, got %v", MinReminderLeadTime, MaxReminderLeadTime, lead)
+		}
+		if seen[lead] {
+			return fmt.Errorf("duplicate lead time %v", lead)
+		}
+		seen[lead] = true
+	}
+
+	return nil
+}
+
+// Normalize sorts lead times from the longest to the shortest
+func (s *ReminderSettings) Normalize() {
+	sort.Slice(s.LeadTimes, func(i, j int) bool { return s.LeadTimes[i] > s.LeadTimes[j] })
+}
+
+// DueReminder returns the reminder that should have been delivered by now for a deadline.
+// Only the closest one is returned: when several lead times have passed since the last
+// check, the user gets the most urgent reminder instead of all of them at once.
+func (s *ReminderSettings) DueReminder(deadline, now time.Time) (string, bool) {
+	if !s.Enabled || deadline.IsZero() {
+		return "", false
+	}
+
+	if !now.Before(deadline) {
+		if now.Sub(deadline) > MaxOverdueNoticeAge {
+			return "", false
+		}
+		return ReminderOverdue, true
+	}
+
+	var lead time.Duration
+	found := false
+	for _, candidate := range s.LeadTimes {
+		if !now.Before(deadline.Add(-candidate)) && (!found || candidate < lead) {
+			lead, found = candidate, true
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	return LeadReminderKind(lead), true
+}
+
+// LeadReminderKind returns the kind of the reminder sent lead before the deadline
+func LeadReminderKind(lead time.Duration) string {
+	return fmt.Sprintf("lead:%d", int(lead/time.Minute))
+}
@@ -0,0 +1,14 @@
+package models
+
+// Snippet highlight markers around the matched words in SearchResult.Snippet
+const (
+	SnippetMatchStart = "["
+	SnippetMatchEnd   = "]"
+)
+
+// SearchResult is a task found by a full-text search
+type SearchResult struct {
+	Task    *Task   `json:"task"`
+	Snippet string  `json:"snippet"` // Matching fragment with the found words highlighted
+	Rank    float64 `json:"rank"`    // Relevance, higher is better
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// MaxTagLength is the maximum length of a tag in bytes; it keeps tags short
+// enough to fit into Telegram callback data
+const MaxTagLength = 40
+
+// TagCount is a tag of a user with the number of tasks marked with it
+type TagCount struct {
+	Name   string `json:"name"`
+	Tasks  int    `json:"tasks"`
+	Active int    `json:"active"`
+}
+
+// NormalizeTag returns the canonical form of a tag: lower case without the leading "#".
+// Tags consist of letters, digits, "_" and "-".
+func NormalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" {
+		return "", errors.New("tag cannot be empty")
+	}
+
+	if len(tag) > MaxTagLength {
+		return "", errors.New("tag is too long")
+	}
+
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			return "", errors.New("tag may only contain letters, digits, _ and -")
+		}
+	}
+
+	return tag, nil
+}
+
+// NormalizeTags normalizes tags, drops duplicates and sorts them
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := NormalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
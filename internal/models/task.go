@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
@@ -13,7 +14,14 @@ type Task struct {
 	OriginalDescription string    `json:"original_description"`
 	LLMProcessedDesc    string    `json:"llm_processed_desc"`
 	Deadline            time.Time `json:"deadline"`
+	DeadlineHasTime     bool      `json:"deadline_has_time"` // False for all-day deadlines (end of the day)
 	Status              string    `json:"status"`
+	Priority            int       `json:"priority"`        // PriorityUrgent (1) to PriorityLow (4), PriorityNone (0) if not set
+	Tags                []string  `json:"tags"`            // Normalized tags without "#", sorted
+	PostponedUntil      time.Time `json:"postponed_until"` // When a postponed task becomes active again, zero means until changed by hand
+	Recurrence          string    `json:"recurrence"`      // RRULE of a recurring task, empty for one-off tasks
+	SeriesID            int       `json:"series_id"`       // ID of the first task of a recurring series, 0 for one-off tasks
+	DeletedAt           time.Time `json:"deleted_at"`      // When the task was moved to the trash, zero for tasks that are not deleted
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
@@ -25,6 +33,20 @@ const (
 	StatusPostponed = "postponed"
 )
 
+// Task priorities, from the most to the least important as in "!1".."!4"
+const (
+	PriorityNone   = 0
+	PriorityUrgent = 1
+	PriorityHigh   = 2
+	PriorityMedium = 3
+	PriorityLow    = 4
+)
+
+// IsValidPriority checks if the priority is PriorityNone or one of the levels
+func IsValidPriority(priority int) bool {
+	return priority >= PriorityNone && priority <= PriorityLow
+}
+
 // Validate validates the task data
 func (t *Task) Validate() error {
 	if t.UserID <= 0 {
@@ -43,9 +65,53 @@ func (t *Task) Validate() error {
 		return errors.New("status must be one of: active, done, postponed")
 	}
 
+	if !IsStorableTime(t.Deadline) {
+		return fmt.Errorf("deadline must be between years %d and %d", MinTimeYear, MaxTimeYear)
+	}
+
+	if !IsStorableTime(t.PostponedUntil) {
+		return fmt.Errorf("postponed_until must be between years %d and %d", MinTimeYear, MaxTimeYear)
+	}
+
+	if !IsValidPriority(t.Priority) {
+		return fmt.Errorf("priority must be between %d and %d", PriorityUrgent, PriorityLow)
+	}
+
+	for _, tag := range t.Tags {
+		if normalized, err := NormalizeTag(tag); err != nil {
+			return fmt.Errorf("invalid tag %q: %w", tag, err)
+		} else if normalized != tag {
+			return fmt.Errorf("tag %q is not normalized", tag)
+		}
+	}
+
+	if t.Recurrence != "" {
+		if _, err := ParseRRule(t.Recurrence); err != nil {
+			return fmt.Errorf("invalid recurrence: %w", err)
+		}
+		if !t.HasDeadline() {
+			return errors.New("a recurring task must have a deadline")
+		}
+	}
+
 	return nil
 }
 
+// Years of the timestamps every database can store and read back
+const (
+	MinTimeYear = 1
+	MaxTimeYear = 9999
+)
+
+// IsStorableTime checks if the time is zero (not set) or falls in MinTimeYear..MaxTimeYear in UTC
+func IsStorableTime(t time.Time) bool {
+	if t.IsZero() {
+		return true
+	}
+	year := t.UTC().Year()
+	return year >= MinTimeYear && year <= MaxTimeYear
+}
+
 // isValidStatus checks if the status is valid
 func isValidStatus(status string) bool {
 	switch status {
@@ -71,6 +137,32 @@ func (t *Task) IsPostponed() bool {
 	return t.Status == StatusPostponed
 }
 
+// Postpone postpones the task until the given time; the zero time postpones it indefinitely
+func (t *Task) Postpone(until time.Time) {
+	t.Status = StatusPostponed
+	t.PostponedUntil = until
+}
+
+// IsDeleted returns true if the task is in the trash
+func (t *Task) IsDeleted() bool {
+	return !t.DeletedAt.IsZero()
+}
+
+// HasPriority returns true if the task has a priority set
+func (t *Task) HasPriority() bool {
+	return t.Priority != PriorityNone
+}
+
+// HasTag returns true if the task is marked with the normalized tag
+func (t *Task) HasTag(tag string) bool {
+	for _, taskTag := range t.Tags {
+		if taskTag == tag {
+			return true
+		}
+	}
+	return false
+}
+
 // HasDeadline returns true if the task has a deadline set
 func (t *Task) HasDeadline() bool {
 	return !t.Deadline.IsZero()
@@ -84,6 +176,62 @@ func (t *Task) IsOverdue() bool {
 	return time.Now().After(t.Deadline)
 }
 
+// IsRecurring returns true if the task repeats
+func (t *Task) IsRecurring() bool {
+	return t.Recurrence != ""
+}
+
+// RecurrenceRule parses the recurrence of the task, nil for one-off tasks
+func (t *Task) RecurrenceRule() (*Recurrence, error) {
+	if !t.IsRecurring() {
+		return nil, nil
+	}
+	return ParseRRule(t.Recurrence)
+}
+
+// NextOccurrence creates the task for the occurrence after this one. loc is the zone the
+// recurrence is counted in, so that weekdays and days of the month match the user's calendar.
+// Occurrences that are already in the past at now are skipped. It returns nil for one-off
+// tasks and when the series has ended.
+func (t *Task) NextOccurrence(loc *time.Location, now time.Time) (*Task, error) {
+	rule, err := t.RecurrenceRule()
+	if err != nil || rule == nil {
+		return nil, err
+	}
+	if !t.HasDeadline() {
+		return nil, errors.New("a recurring task must have a deadline")
+	}
+
+	deadline := t.Deadline.In(loc)
+	for {
+		deadline = rule.Next(deadline)
+		if deadline.IsZero() {
+			return nil, nil
+		}
+		if deadline.After(now) {
+			break
+		}
+	}
+
+	seriesID := t.SeriesID
+	if seriesID == 0 {
+		seriesID = t.ID
+	}
+
+	return &Task{
+		UserID:              t.UserID,
+		OriginalDescription: t.OriginalDescription,
+		LLMProcessedDesc:    t.LLMProcessedDesc,
+		Deadline:            deadline,
+		DeadlineHasTime:     t.DeadlineHasTime,
+		Status:              StatusActive,
+		Priority:            t.Priority,
+		Tags:                append([]string(nil), t.Tags...),
+		Recurrence:          t.Recurrence,
+		SeriesID:            seriesID,
+	}, nil
+}
+
 // GetDescription returns the LLM processed description if available, otherwise original
 func (t *Task) GetDescription() string {
 	if t.LLMProcessedDesc != "" {
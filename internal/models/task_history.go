@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TaskChange represents a single change of a task field
+type TaskChange struct {
+	ID        int       `json:"id"`
+	TaskID    int       `json:"task_id"`
+	UserID    int       `json:"user_id"` // Who made the change
+	Field     string    `json:"field"`
+	OldValue  string    `json:"old_value"`
+	NewValue  string    `json:"new_value"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// Task fields tracked in the change history
+const (
+	FieldDescription = "description"
+	FieldDeadline    = "deadline"
+	FieldStatus      = "status"
+	FieldPriority    = "priority"
+	FieldTags        = "tags"
+)
+
+// deadlineAllDaySuffix follows the time of an all-day deadline in a history value
+const deadlineAllDaySuffix = " all-day"
+
+// Validate validates the task change data
+func (c *TaskChange) Validate() error {
+	if c.TaskID <= 0 {
+		return errors.New("task_id must be a positive integer")
+	}
+
+	if c.UserID <= 0 {
+		return errors.New("user_id must be a positive integer")
+	}
+
+	if c.Field == "" {
+		return errors.New("field cannot be empty")
+	}
+
+	return nil
+}
+
+// SetDefaults sets default values for the task change
+func (c *TaskChange) SetDefaults() {
+	if c.ChangedAt.IsZero() {
+		c.ChangedAt = time.Now()
+	}
+}
+
+// DiffTasks returns the changes between two versions of the same task made by userID
+func DiffTasks(before, after *Task, userID int) []*TaskChange {
+	var changes []*TaskChange
+
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, &TaskChange{
+				TaskID:   after.ID,
+				UserID:   userID,
+				Field:    field,
+				OldValue: oldValue,
+				NewValue: newValue,
+			})
+		}
+	}
+
+	add(FieldDescription, before.OriginalDescription, after.OriginalDescription)
+	add(FieldDeadline, formatDeadline(before), formatDeadline(after))
+	add(FieldStatus, before.Status, after.Status)
+	add(FieldPriority, formatPriority(before), formatPriority(after))
+	add(FieldTags, strings.Join(before.Tags, " "), strings.Join(after.Tags, " "))
+
+	return changes
+}
+
+// formatPriority returns the priority as a history value (empty if not set)
+func formatPriority(t *Task) string {
+	if !t.HasPriority() {
+		return ""
+	}
+	return strconv.Itoa(t.Priority)
+}
+
+// formatDeadline returns the deadline in UTC as a history value (empty if not set), so the
+// same moment gives the same value whatever zone the task carries
+func formatDeadline(t *Task) string {
+	if !t.HasDeadline() {
+		return ""
+	}
+	value := t.Deadline.UTC().Format(time.RFC3339)
+	if !t.DeadlineHasTime {
+		value += deadlineAllDaySuffix
+	}
+	return value
+}
+
+// ParseDeadlineValue parses a deadline history value and reports whether the deadline has a time
+func ParseDeadlineValue(value string) (time.Time, bool, error) {
+	value, allDay := strings.CutSuffix(value, deadlineAllDaySuffix)
+	deadline, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	return deadline, !allDay, nil
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -11,16 +12,39 @@ type User struct {
 	Username  string    `json:"username"`   // Telegram username (optional)
 	FirstName string    `json:"first_name"` // Telegram first name
 	LastName  string    `json:"last_name"`  // Telegram last name (optional)
+	TimeZone  string    `json:"time_zone"`  // IANA time zone, empty means the server's zone
+	DateOrder string    `json:"date_order"` // Order of day and month in dates, empty means not chosen
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Date orders for numeric dates
+const (
+	DateOrderDMY = "dmy" // 15.07.2025
+	DateOrderMDY = "mdy" // 07/15/2025
+	DateOrderYMD = "ymd" // 2025-07-15
+)
+
+// IsValidDateOrder checks if the date order is one of the supported ones
+func IsValidDateOrder(order string) bool {
+	switch order {
+	case DateOrderDMY, DateOrderMDY, DateOrderYMD:
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultRequestLimit is the number of LLM requests a regular user can make per week by default
+const DefaultRequestLimit = 10
+
 // APILimit represents API usage limits for a user
 type APILimit struct {
 	UserID        int       `json:"user_id"`
 	RequestsCount int       `json:"requests_count"`
 	ResetDate     time.Time `json:"reset_date"`
 	IsPremium     bool      `json:"is_premium"`
+	Tier          string    `json:"tier"` // free, premium or admin
 }
 
 // Validate validates the user data
@@ -33,9 +57,51 @@ func (u *User) Validate() error {
 		return errors.New("first name cannot be empty")
 	}
 
+	if u.TimeZone != "" {
+		if _, err := LoadTimeZone(u.TimeZone); err != nil {
+			return err
+		}
+	}
+
+	if u.DateOrder != "" && !IsValidDateOrder(u.DateOrder) {
+		return errors.New("date order must be one of: dmy, mdy, ymd")
+	}
+
 	return nil
 }
 
+// Location returns the time zone of the user, falling back to the server's zone
+func (u *User) Location() *time.Location {
+	return locationOrLocal(u.TimeZone)
+}
+
+// locationOrLocal loads a stored time zone; unset or unknown zones fall back to the server's zone
+func locationOrLocal(name string) *time.Location {
+	if name == "" {
+		return time.Local
+	}
+	loc, err := LoadTimeZone(name)
+	if err != nil {
+		return time.Local
+	}
+	return loc
+}
+
+// LoadTimeZone loads an IANA time zone such as "Europe/Moscow".
+// "Local" is rejected because it depends on the server the bot runs on.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+
+	return loc, nil
+}
+
 // GetFullName returns the full name of the user
 func (u *User) GetFullName() string {
 	if u.LastName != "" {
@@ -70,35 +136,63 @@ func (a *APILimit) Validate() error {
 		return errors.New("requests_count cannot be negative")
 	}
 
+	if a.Tier != "" && !IsValidTier(a.Tier) {
+		return errors.New("tier must be one of: free, premium, admin")
+	}
+
 	return nil
 }
 
-// CanMakeRequest checks if the user can make an API request
-func (a *APILimit) CanMakeRequest() bool {
+// GetTier returns the tier of the limit; legacy rows without a tier fall back to is_premium
+func (a *APILimit) GetTier() string {
+	if a.Tier != "" {
+		return a.Tier
+	}
 	if a.IsPremium {
+		return TierPremium
+	}
+	return TierFree
+}
+
+// Rule returns the quota rule that applies to the limit under the policy
+func (a *APILimit) Rule(policy QuotaPolicy) QuotaRule {
+	return policy.Rule(a.GetTier())
+}
+
+// CanMakeRequest checks if the user can make an API request
+func (a *APILimit) CanMakeRequest(policy QuotaPolicy) bool {
+	rule := a.Rule(policy)
+	if rule.IsUnlimited() {
 		return true
 	}
 
 	// Check if the limit period has expired
-	if time.Now().After(a.ResetDate) {
+	if a.ShouldReset() {
 		return true
 	}
 
-	// Check if under the limit (10 requests per month for regular users)
-	return a.RequestsCount < 10
+	return a.RequestsCount < rule.Limit
 }
 
 // ShouldReset checks if the limit should be reset
 func (a *APILimit) ShouldReset() bool {
-	return time.Now().After(a.ResetDate)
+	return a.ShouldResetAt(time.Now())
+}
+
+// ShouldResetAt checks if the limit should be reset at the given moment
+func (a *APILimit) ShouldResetAt(now time.Time) bool {
+	return now.After(a.ResetDate)
 }
 
 // Reset resets the API limit to the beginning of the new period
-func (a *APILimit) Reset() {
+func (a *APILimit) Reset(policy QuotaPolicy) {
+	a.ResetAt(policy, time.Now())
+}
+
+// ResetAt resets the API limit to the period starting at the given moment
+func (a *APILimit) ResetAt(policy QuotaPolicy, now time.Time) {
 	a.RequestsCount = 0
-	// Set reset date to the beginning of next month
-	now := time.Now()
-	a.ResetDate = time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
+	a.ResetDate = a.Rule(policy).NextReset(now)
 }
 
 // IncrementRequests increments the request count
@@ -106,17 +200,25 @@ func (a *APILimit) IncrementRequests() {
 	a.RequestsCount++
 }
 
-// GetRemainingRequests returns the number of remaining requests
-func (a *APILimit) GetRemainingRequests() int {
-	if a.IsPremium {
-		return -1 // Unlimited
+// RefundRequest returns a request that was counted but not served, never going below zero
+func (a *APILimit) RefundRequest() {
+	if a.RequestsCount > 0 {
+		a.RequestsCount--
+	}
+}
+
+// GetRemainingRequests returns the number of remaining requests, -1 means unlimited
+func (a *APILimit) GetRemainingRequests(policy QuotaPolicy) int {
+	rule := a.Rule(policy)
+	if rule.IsUnlimited() {
+		return Unlimited
 	}
 
 	if a.ShouldReset() {
-		return 10 // Full limit after reset
+		return rule.Limit // Full limit after reset
 	}
 
-	remaining := 10 - a.RequestsCount
+	remaining := rule.Limit - a.RequestsCount
 	if remaining < 0 {
 		return 0
 	}
@@ -0,0 +1,315 @@
+// Package reminder sends Telegram reminders about upcoming and missed task deadlines.
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+	"telegram-bot-assistente/internal/utils"
+
+	"gopkg.in/telebot.v3"
+)
+
+// DefaultInterval is how often the scheduler looks for due reminders
+const DefaultInterval = time.Minute
+
+// Sender delivers messages to Telegram users; *telebot.Bot implements it
+type Sender interface {
+	Send(to telebot.Recipient, what interface{}, opts ...interface{}) (*telebot.Message, error)
+}
+
+// TrashPurger permanently deletes tasks that were moved to the trash before a time;
+// repository.TaskRepository implements it
+type TrashPurger interface {
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+}
+
+// Scheduler periodically checks task deadlines and sends reminders.
+// Sent reminders are recorded in the store before delivery, so a restart
+// or a second instance never sends the same reminder twice.
+type Scheduler struct {
+	store    repository.ReminderRepository
+	sender   Sender
+	interval time.Duration
+	now      func() time.Time
+	markup   func(task *models.Task) *telebot.ReplyMarkup
+
+	purger         TrashPurger
+	trashRetention time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Option configures a Scheduler
+type Option func(*Scheduler)
+
+// WithInterval sets how often the scheduler checks deadlines
+func WithInterval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = interval
+	}
+}
+
+// WithClock replaces time.Now, so tests can move time without waiting
+func WithClock(now func() time.Time) Option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+// WithMarkup attaches a keyboard, such as snooze buttons, to the messages about a task
+func WithMarkup(markup func(task *models.Task) *telebot.ReplyMarkup) Option {
+	return func(s *Scheduler) {
+		s.markup = markup
+	}
+}
+
+// WithTrashPurge makes every check also delete the tasks that have been in the trash
+// for longer than retention
+func WithTrashPurge(purger TrashPurger, retention time.Duration) Option {
+	return func(s *Scheduler) {
+		s.purger = purger
+		s.trashRetention = retention
+	}
+}
+
+// NewScheduler creates a scheduler that is not running yet
+func NewScheduler(store repository.ReminderRepository, sender Sender, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		store:    store,
+		sender:   sender,
+		interval: DefaultInterval,
+		now:      time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Start runs the scheduler in the background until Stop is called or ctx is done.
+// The first check happens immediately.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done != nil {
+		return
+	}
+
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+
+	go s.run(ctx, s.done)
+}
+
+// Stop stops the scheduler and waits until the current check finishes
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if done == nil {
+		return
+	}
+
+	cancel()
+	<-done
+}
+
+// run checks deadlines on every tick until ctx is done
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx); err != nil {
+			log.Printf("Reminder check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick resumes postponed tasks that are due, purges the trash if WithTrashPurge is set and
+// sends all reminders that are due at the current time
+func (s *Scheduler) Tick(ctx context.Context) error {
+	now := s.now()
+	settings := make(map[int]*models.ReminderSettings)
+
+	if err := s.resumePostponed(ctx, now, settings); err != nil {
+		log.Printf("Failed to resume postponed tasks: %v", err)
+	}
+
+	if err := s.purgeTrash(ctx, now); err != nil {
+		log.Printf("Failed to purge trash: %v", err)
+	}
+
+	tasks, err := s.store.GetTasksWithDeadlineBetween(ctx, now.Add(-models.MaxOverdueNoticeAge), now.Add(models.MaxReminderLeadTime))
+	if err != nil {
+		return fmt.Errorf("failed to load tasks: %w", err)
+	}
+
+	for _, task := range tasks {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		userSettings, ok := s.userSettings(ctx, task.UserID, settings)
+		if !ok {
+			continue
+		}
+
+		kind, due := userSettings.DueReminder(task.Deadline, now)
+		if !due {
+			continue
+		}
+
+		if err := s.deliver(ctx, task, kind, now, userSettings.Location()); err != nil {
+			log.Printf("Failed to send %s reminder for task %d: %v", kind, task.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// resumePostponed makes postponed tasks active again once their time has come and tells
+// their owners. The tasks are resumed even if the message cannot be delivered.
+func (s *Scheduler) resumePostponed(ctx context.Context, now time.Time, settings map[int]*models.ReminderSettings) error {
+	tasks, err := s.store.ResumePostponedTasks(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		log.Printf("Postponed task %d of user %d is active again", task.ID, task.UserID)
+
+		userSettings, ok := s.userSettings(ctx, task.UserID, settings)
+		if !ok || !userSettings.Enabled {
+			continue
+		}
+
+		recipient := &telebot.User{ID: int64(task.UserID)}
+		if _, err := s.sender.Send(recipient, FormatResumed(task, userSettings.Location()), s.markupFor(task)...); err != nil {
+			log.Printf("Failed to notify user %d about resumed task %d: %v", task.UserID, task.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// purgeTrash deletes the tasks moved to the trash more than the retention period ago
+func (s *Scheduler) purgeTrash(ctx context.Context, now time.Time) error {
+	if s.purger == nil {
+		return nil
+	}
+
+	purged, err := s.purger.PurgeTrash(ctx, now.Add(-s.trashRetention))
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		log.Printf("Purged %d tasks from the trash", purged)
+	}
+	return nil
+}
+
+// userSettings loads the reminder settings of a user once per check
+func (s *Scheduler) userSettings(ctx context.Context, userID int, settings map[int]*models.ReminderSettings) (*models.ReminderSettings, bool) {
+	if userSettings, ok := settings[userID]; ok {
+		return userSettings, true
+	}
+
+	userSettings, err := s.store.GetReminderSettings(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load reminder settings of user %d: %v", userID, err)
+		return nil, false
+	}
+
+	settings[userID] = userSettings
+	return userSettings, true
+}
+
+// markupFor returns the send options with the keyboard for the task, if any
+func (s *Scheduler) markupFor(task *models.Task) []interface{} {
+	if s.markup == nil {
+		return nil
+	}
+	if markup := s.markup(task); markup != nil {
+		return []interface{}{markup}
+	}
+	return nil
+}
+
+// deliver records the reminder and sends it; the record is removed if sending fails
+// for a reason that may go away, so the next check retries it
+func (s *Scheduler) deliver(ctx context.Context, task *models.Task, kind string, now time.Time, loc *time.Location) error {
+	marked, err := s.store.MarkReminderSent(ctx, task.ID, kind, task.Deadline, now)
+	if err != nil {
+		return err
+	}
+	if !marked {
+		return nil
+	}
+
+	recipient := &telebot.User{ID: int64(task.UserID)}
+	_, err = s.sender.Send(recipient, FormatReminder(task, kind, now, loc), s.markupFor(task)...)
+	if err == nil {
+		log.Printf("Reminder %s sent for task %d to user %d", kind, task.ID, task.UserID)
+		return nil
+	}
+
+	// Retrying is pointless if the user blocked the bot or deleted the account
+	if errors.Is(err, telebot.ErrBlockedByUser) || errors.Is(err, telebot.ErrUserIsDeactivated) {
+		return err
+	}
+
+	// The record is removed even if the scheduler is stopping, or the reminder would be lost
+	if unmarkErr := s.store.UnmarkReminderSent(context.WithoutCancel(ctx), task.ID, kind, task.Deadline); unmarkErr != nil {
+		return fmt.Errorf("%w (and failed to retry later: %v)", err, unmarkErr)
+	}
+	return err
+}
+
+// FormatReminder formats the reminder text for a task as of now, with the deadline in the user's zone
+func FormatReminder(task *models.Task, kind string, now time.Time, loc *time.Location) string {
+	deadline := utils.FormatDeadline(task.Deadline.In(loc), task.DeadlineHasTime)
+	if kind == models.ReminderOverdue {
+		return fmt.Sprintf("⚠️ Срок задачи истек\n\n📝 %s (ID: %d)\n⏰ Срок: %s\n\nОтметить выполнение: /done %d",
+			task.GetDescription(), task.ID, deadline, task.ID)
+	}
+
+	return fmt.Sprintf("🔔 Напоминание: до срока осталось %s\n\n📝 %s (ID: %d)\n⏰ Срок: %s",
+		utils.FormatLeadTime(task.Deadline.Sub(now).Round(time.Minute)), task.GetDescription(), task.ID, deadline)
+}
+
+// FormatResumed formats the message about a postponed task that is active again
+func FormatResumed(task *models.Task, loc *time.Location) string {
+	text := fmt.Sprintf("▶️ Отложенная задача снова активна\n\n📝 %s (ID: %d)", task.GetDescription(), task.ID)
+	if task.HasDeadline() {
+		text += "\n⏰ Срок: " + utils.FormatDeadline(task.Deadline.In(loc), task.DeadlineHasTime)
+	}
+	return text + fmt.Sprintf("\n\nОтметить выполнение: /done %d", task.ID)
+}
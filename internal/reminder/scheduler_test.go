@@ -0,0 +1,377 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+	"telegram-bot-assistente/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/telebot.v3"
+)
+
+// fakeSender records sent messages instead of calling Telegram
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []sentMessage
+	err  error
+}
+
+type sentMessage struct {
+	userID int64
+	text   string
+	markup *telebot.ReplyMarkup
+}
+
+func (s *fakeSender) Send(to telebot.Recipient, what interface{}, opts ...interface{}) (*telebot.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	message := sentMessage{userID: to.(*telebot.User).ID, text: what.(string)}
+	for _, opt := range opts {
+		if markup, ok := opt.(*telebot.ReplyMarkup); ok {
+			message.markup = markup
+		}
+	}
+	s.sent = append(s.sent, message)
+	return &telebot.Message{}, nil
+}
+
+func (s *fakeSender) messages() []sentMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]sentMessage(nil), s.sent...)
+}
+
+// fakeClock is a manually advanced clock
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
+
+type testEnv struct {
+	db        *repository.Database
+	tasks     repository.TaskRepository
+	reminders repository.ReminderRepository
+	sender    *fakeSender
+	clock     *fakeClock
+	scheduler *Scheduler
+}
+
+var deadline = time.Date(2025, 7, 15, 18, 0, 0, 0, time.Local)
+
+func setupTestEnv(t *testing.T) *testEnv {
+	db, err := repository.NewDatabase(filepath.Join(t.TempDir(), "reminders.db"))
+	if errors.Is(err, repository.ErrNoFTS5) {
+		t.Skip(err)
+	}
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	users := repository.NewUserRepository(db)
+	for _, id := range []int{1, 2} {
+		_, err := users.UpsertUser(t.Context(), &models.User{ID: id, FirstName: "Test"})
+		require.NoError(t, err)
+	}
+
+	env := &testEnv{
+		db:        db,
+		tasks:     repository.NewTaskRepository(db),
+		reminders: repository.NewReminderRepository(db),
+		sender:    &fakeSender{},
+		clock:     &fakeClock{now: deadline.Add(-48 * time.Hour)},
+	}
+	env.scheduler = NewScheduler(env.reminders, env.sender, WithClock(env.clock.Now))
+	return env
+}
+
+func (e *testEnv) addTask(t *testing.T, userID int, deadline time.Time) *models.Task {
+	task := &models.Task{UserID: userID, OriginalDescription: "Сдать отчет", Deadline: deadline}
+	require.NoError(t, e.tasks.AddTask(t.Context(), task))
+	return task
+}
+
+func (e *testEnv) tickAt(t *testing.T, now time.Time) {
+	e.clock.Set(now)
+	require.NoError(t, e.scheduler.Tick(context.Background()))
+}
+
+func TestScheduler_Tick(t *testing.T) {
+	t.Run("lead reminders and overdue notice", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.addTask(t, 1, deadline)
+
+		env.tickAt(t, deadline.Add(-48*time.Hour))
+		assert.Empty(t, env.sender.messages())
+
+		env.tickAt(t, deadline.Add(-24*time.Hour))
+		require.Len(t, env.sender.messages(), 1)
+		assert.Equal(t, int64(1), env.sender.messages()[0].userID)
+		assert.Contains(t, env.sender.messages()[0].text, "до срока осталось 1 дн.")
+
+		// The same reminder is not repeated on the next checks
+		env.tickAt(t, deadline.Add(-23*time.Hour))
+		assert.Len(t, env.sender.messages(), 1)
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		require.Len(t, env.sender.messages(), 2)
+		assert.Contains(t, env.sender.messages()[1].text, "до срока осталось 1 ч.")
+
+		env.tickAt(t, deadline.Add(time.Minute))
+		require.Len(t, env.sender.messages(), 3)
+		assert.Contains(t, env.sender.messages()[2].text, "Срок задачи истек")
+		assert.Contains(t, env.sender.messages()[2].text, "/done")
+
+		env.tickAt(t, deadline.Add(2*time.Hour))
+		assert.Len(t, env.sender.messages(), 3)
+	})
+
+	t.Run("only the closest reminder after a late start", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.addTask(t, 1, deadline)
+
+		env.tickAt(t, deadline.Add(-30*time.Minute))
+		require.Len(t, env.sender.messages(), 1)
+		assert.Contains(t, env.sender.messages()[0].text, "до срока осталось 30 мин.")
+	})
+
+	t.Run("sent reminders survive a restart", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.addTask(t, 1, deadline)
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		require.Len(t, env.sender.messages(), 1)
+
+		restarted := NewScheduler(env.reminders, env.sender, WithClock(env.clock.Now))
+		require.NoError(t, restarted.Tick(context.Background()))
+		assert.Len(t, env.sender.messages(), 1)
+	})
+
+	t.Run("moving the deadline arms reminders again", func(t *testing.T) {
+		env := setupTestEnv(t)
+		task := env.addTask(t, 1, deadline)
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		require.Len(t, env.sender.messages(), 1)
+
+		task.Deadline = deadline.Add(24 * time.Hour)
+		require.NoError(t, env.tasks.UpdateTask(t.Context(), task))
+
+		env.tickAt(t, task.Deadline.Add(-time.Hour))
+		assert.Len(t, env.sender.messages(), 2)
+	})
+
+	t.Run("user settings", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.addTask(t, 1, deadline)
+		env.addTask(t, 2, deadline)
+
+		require.NoError(t, env.reminders.SaveReminderSettings(t.Context(), &models.ReminderSettings{UserID: 1, Enabled: false}))
+		require.NoError(t, env.reminders.SaveReminderSettings(t.Context(), &models.ReminderSettings{
+			UserID: 2, Enabled: true, LeadTimes: []time.Duration{3 * time.Hour},
+		}))
+
+		env.tickAt(t, deadline.Add(-24*time.Hour))
+		assert.Empty(t, env.sender.messages())
+
+		env.tickAt(t, deadline.Add(-3*time.Hour))
+		require.Len(t, env.sender.messages(), 1)
+		assert.Equal(t, int64(2), env.sender.messages()[0].userID)
+	})
+
+	t.Run("deadline is shown in the user's zone", func(t *testing.T) {
+		env := setupTestEnv(t)
+		require.NoError(t, repository.NewUserRepository(env.db).SetTimeZone(t.Context(), 1, "Asia/Tokyo"))
+
+		task := &models.Task{UserID: 1, OriginalDescription: "Созвон", Deadline: deadline, DeadlineHasTime: true}
+		require.NoError(t, env.tasks.AddTask(t.Context(), task))
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		require.Len(t, env.sender.messages(), 1)
+
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		assert.Contains(t, env.sender.messages()[0].text, "⏰ Срок: "+deadline.In(tokyo).Format("02.01.2006 15:04"))
+	})
+
+	t.Run("done tasks are not reminded", func(t *testing.T) {
+		env := setupTestEnv(t)
+		task := env.addTask(t, 1, deadline)
+		task.Status = models.StatusDone
+		require.NoError(t, env.tasks.UpdateTask(t.Context(), task))
+
+		env.tickAt(t, deadline.Add(time.Hour))
+		assert.Empty(t, env.sender.messages())
+	})
+
+	t.Run("failed delivery is retried", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.addTask(t, 1, deadline)
+
+		env.sender.err = errors.New("network is down")
+		env.tickAt(t, deadline.Add(-time.Hour))
+		assert.Empty(t, env.sender.messages())
+
+		env.sender.err = nil
+		env.tickAt(t, deadline.Add(-59*time.Minute))
+		assert.Len(t, env.sender.messages(), 1)
+	})
+
+	t.Run("blocked users are not retried", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.addTask(t, 1, deadline)
+
+		env.sender.err = telebot.ErrBlockedByUser
+		env.tickAt(t, deadline.Add(-time.Hour))
+
+		env.sender.err = nil
+		env.tickAt(t, deadline.Add(-59*time.Minute))
+		assert.Empty(t, env.sender.messages())
+	})
+}
+
+func TestScheduler_Postponed(t *testing.T) {
+	snooze := func(task *models.Task) *telebot.ReplyMarkup {
+		return &telebot.ReplyMarkup{InlineKeyboard: [][]telebot.InlineButton{{{Text: "snooze", Data: "snooze"}}}}
+	}
+
+	t.Run("postponed task comes back and is announced", func(t *testing.T) {
+		env := setupTestEnv(t)
+		env.scheduler = NewScheduler(env.reminders, env.sender, WithClock(env.clock.Now), WithMarkup(snooze))
+
+		task := env.addTask(t, 1, deadline)
+		task.Postpone(deadline.Add(-30 * time.Hour))
+		require.NoError(t, env.tasks.UpdateTask(t.Context(), task))
+
+		env.tickAt(t, deadline.Add(-31*time.Hour))
+		assert.Empty(t, env.sender.messages())
+
+		env.tickAt(t, deadline.Add(-30*time.Hour))
+		require.Len(t, env.sender.messages(), 1)
+		assert.Contains(t, env.sender.messages()[0].text, "снова активна")
+		assert.Contains(t, env.sender.messages()[0].text, "(ID: 1)")
+		assert.NotNil(t, env.sender.messages()[0].markup)
+
+		stored, err := env.tasks.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsActive())
+
+		// Reminders resume for the active task, with the keyboard
+		env.tickAt(t, deadline.Add(-24*time.Hour))
+		require.Len(t, env.sender.messages(), 2)
+		assert.Contains(t, env.sender.messages()[1].text, "до срока осталось 1 дн.")
+		assert.NotNil(t, env.sender.messages()[1].markup)
+	})
+
+	t.Run("no reminders while postponed", func(t *testing.T) {
+		env := setupTestEnv(t)
+		task := env.addTask(t, 1, deadline)
+		task.Postpone(time.Time{})
+		require.NoError(t, env.tasks.UpdateTask(t.Context(), task))
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		env.tickAt(t, deadline.Add(time.Minute))
+		assert.Empty(t, env.sender.messages())
+	})
+
+	t.Run("resumed silently when reminders are off", func(t *testing.T) {
+		env := setupTestEnv(t)
+		require.NoError(t, env.reminders.SaveReminderSettings(t.Context(), &models.ReminderSettings{UserID: 1, Enabled: false}))
+
+		task := env.addTask(t, 1, deadline)
+		task.Postpone(deadline.Add(-2 * time.Hour))
+		require.NoError(t, env.tasks.UpdateTask(t.Context(), task))
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		assert.Empty(t, env.sender.messages())
+
+		stored, err := env.tasks.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.IsActive())
+	})
+}
+
+func TestScheduler_Trash(t *testing.T) {
+	t.Run("no reminders for deleted tasks", func(t *testing.T) {
+		env := setupTestEnv(t)
+		task := env.addTask(t, 1, deadline)
+		require.NoError(t, env.tasks.TrashTask(t.Context(), task.ID))
+
+		env.tickAt(t, deadline.Add(-time.Hour))
+		env.tickAt(t, deadline.Add(time.Minute))
+		assert.Empty(t, env.sender.messages())
+	})
+
+	t.Run("tasks are purged after the retention period", func(t *testing.T) {
+		env := setupTestEnv(t)
+		retention := 7 * 24 * time.Hour
+		env.scheduler = NewScheduler(env.reminders, env.sender, WithClock(env.clock.Now), WithTrashPurge(env.tasks, retention))
+
+		trashed := env.addTask(t, 1, deadline)
+		kept := env.addTask(t, 1, deadline)
+		require.NoError(t, env.tasks.TrashTask(t.Context(), trashed.ID))
+		deletedAt := time.Now()
+
+		env.tickAt(t, deadline.Add(-48*time.Hour))
+		env.tickAt(t, deletedAt.Add(retention-time.Hour))
+		trash, err := env.tasks.GetTrashedTasks(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Len(t, trash, 1)
+
+		env.tickAt(t, deletedAt.Add(retention+time.Hour))
+		trash, err = env.tasks.GetTrashedTasks(t.Context(), 1)
+		require.NoError(t, err)
+		assert.Empty(t, trash)
+
+		_, err = env.tasks.GetTask(t.Context(), kept.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestScheduler_StartStop(t *testing.T) {
+	env := setupTestEnv(t)
+	env.addTask(t, 1, deadline)
+	env.clock.Set(deadline.Add(-time.Hour))
+
+	scheduler := NewScheduler(env.reminders, env.sender, WithClock(env.clock.Now), WithInterval(10*time.Millisecond))
+	scheduler.Start(context.Background())
+
+	assert.Eventually(t, func() bool { return len(env.sender.messages()) == 1 }, time.Second, 5*time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		scheduler.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Stop did not return")
+	}
+
+	// Stopping twice is safe
+	scheduler.Stop()
+}
@@ -0,0 +1,22 @@
+package repository
+
+import "io/fs"
+
+// dialect is the SQL database behind a Database. Queries of the repositories are shared
+// between the dialects; dialect covers the few places where they differ.
+type dialect string
+
+const (
+	dialectSQLite   dialect = "sqlite"
+	dialectPostgres dialect = "postgres"
+)
+
+// migrations returns the migration scripts of the dialect. Both dialects have the same
+// versions, so a version means the same schema in either database.
+func (d dialect) migrations() fs.FS {
+	fsys, err := fs.Sub(migrationFiles, "migrations/"+string(d))
+	if err != nil {
+		panic(err)
+	}
+	return fsys
+}
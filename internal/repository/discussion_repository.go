@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// ErrDiscussionNotFound is returned when a discussion with the requested ID does not exist
+var ErrDiscussionNotFound = errors.New("discussion not found")
+
+// DiscussionRepository defines the interface for operations on messages attached to tasks
+type DiscussionRepository interface {
+	AddDiscussion(ctx context.Context, discussion *models.Discussion) error
+	GetDiscussionsByTask(ctx context.Context, taskID int) ([]*models.Discussion, error)
+	DeleteDiscussion(ctx context.Context, id int) error
+}
+
+// SQLDiscussionRepository implements DiscussionRepository for SQLite and PostgreSQL databases
+type SQLDiscussionRepository struct {
+	db *sql.DB
+}
+
+// NewDiscussionRepository creates a new discussion repository instance
+func NewDiscussionRepository(database *Database) DiscussionRepository {
+	return &SQLDiscussionRepository{
+		db: database.GetDB(),
+	}
+}
+
+// AddDiscussion attaches a message to a task and touches the task's updated_at in one transaction
+func (r *SQLDiscussionRepository) AddDiscussion(ctx context.Context, discussion *models.Discussion) error {
+	if err := discussion.Validate(); err != nil {
+		return fmt.Errorf("discussion validation failed: %w", err)
+	}
+
+	discussion.SetDefaults()
+
+	query := `
+		INSERT INTO discussions (task_id, message_id, text, timestamp)
+		VALUES (?, ?, ?, ?)
+		RETURNING id
+	`
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, query,
+		discussion.TaskID,
+		discussion.MessageID,
+		discussion.Text,
+		discussion.Timestamp,
+	).Scan(&id)
+	if err != nil {
+		return fmt.Errorf("failed to insert discussion: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE tasks SET updated_at = ? WHERE id = ?`,
+		time.Now(), discussion.TaskID); err != nil {
+		return fmt.Errorf("failed to touch task: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit discussion: %w", err)
+	}
+
+	discussion.ID = id
+	return nil
+}
+
+// GetDiscussionsByTask retrieves all messages attached to a task in chronological order
+func (r *SQLDiscussionRepository) GetDiscussionsByTask(ctx context.Context, taskID int) ([]*models.Discussion, error) {
+	query := `
+		SELECT id, task_id, message_id, text, timestamp
+		FROM discussions
+		WHERE task_id = ?
+		ORDER BY timestamp ASC, id ASC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var discussions []*models.Discussion
+
+	for rows.Next() {
+		discussion := &models.Discussion{}
+
+		err := rows.Scan(
+			&discussion.ID,
+			&discussion.TaskID,
+			&discussion.MessageID,
+			&discussion.Text,
+			scanTime(&discussion.Timestamp),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan discussion: %w", err)
+		}
+
+		discussions = append(discussions, discussion)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return discussions, nil
+}
+
+// DeleteDiscussion deletes a discussion by ID
+func (r *SQLDiscussionRepository) DeleteDiscussion(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM discussions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete discussion: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id %d", ErrDiscussionNotFound, id)
+	}
+
+	return nil
+}
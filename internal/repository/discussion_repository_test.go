@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscussionRepository(t *testing.T) {
+	db, taskRepo := setupTestDB(t)
+	repo := NewDiscussionRepository(db)
+
+	task := createTestTask(123)
+	task.UpdatedAt = time.Now().Add(-time.Hour)
+	require.NoError(t, taskRepo.AddTask(t.Context(), task))
+
+	t.Run("add and list in chronological order", func(t *testing.T) {
+		later := &models.Discussion{
+			TaskID:    task.ID,
+			MessageID: 11,
+			Text:      "Second message",
+			Timestamp: time.Now().Add(-time.Minute),
+		}
+		earlier := &models.Discussion{
+			TaskID:    task.ID,
+			MessageID: 10,
+			Text:      "First message",
+			Timestamp: time.Now().Add(-10 * time.Minute),
+		}
+
+		require.NoError(t, repo.AddDiscussion(t.Context(), later))
+		require.NoError(t, repo.AddDiscussion(t.Context(), earlier))
+		assert.NotZero(t, later.ID)
+
+		discussions, err := repo.GetDiscussionsByTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		require.Len(t, discussions, 2)
+		assert.Equal(t, "First message", discussions[0].Text)
+		assert.Equal(t, 10, discussions[0].MessageID)
+		assert.Equal(t, "Second message", discussions[1].Text)
+		assert.WithinDuration(t, earlier.Timestamp, discussions[0].Timestamp, time.Second)
+	})
+
+	t.Run("attaching touches the task", func(t *testing.T) {
+		updatedTask, err := taskRepo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), updatedTask.UpdatedAt, time.Minute)
+	})
+
+	t.Run("invalid discussion", func(t *testing.T) {
+		err := repo.AddDiscussion(t.Context(), &models.Discussion{TaskID: task.ID, MessageID: 12})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "text cannot be empty")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		discussion := &models.Discussion{TaskID: task.ID, MessageID: 13, Text: "To delete"}
+		require.NoError(t, repo.AddDiscussion(t.Context(), discussion))
+
+		require.NoError(t, repo.DeleteDiscussion(t.Context(), discussion.ID))
+
+		err := repo.DeleteDiscussion(t.Context(), discussion.ID)
+		assert.ErrorIs(t, err, ErrDiscussionNotFound)
+
+		discussions, err := repo.GetDiscussionsByTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Len(t, discussions, 2)
+	})
+
+	t.Run("task without discussions", func(t *testing.T) {
+		discussions, err := repo.GetDiscussionsByTask(t.Context(), 999)
+		assert.NoError(t, err)
+		assert.Empty(t, discussions)
+	})
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew возвращается, если база данных мигрирована более новой версией бота
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// Migration описывает одну версию схемы со скриптами наката и отката
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// migrationFileName разбирает имена вида 0001_initial_schema.up.sql
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// migrationsLockID - ключ advisory-блокировки PostgreSQL, под которой мигрирует схему
+// только одна из одновременно запущенных копий бота
+const migrationsLockID = 7310401
+
+// loadMigrations читает миграции из корня файловой системы и проверяет,
+// что версии идут подряд с 1 и у каждой есть up- и down-скрипт
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration %d is missing", i+1)
+		}
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have up and down scripts", migration.Version, migration.Name)
+		}
+	}
+
+	return migrations, nil
+}
+
+// Migrate накатывает все миграции, которых еще нет в базе данных
+func (d *Database) Migrate() error {
+	return d.MigrateTo(-1)
+}
+
+// MigrateTo приводит схему к указанной версии, накатывая или откатывая миграции.
+// Отрицательная версия означает последнюю известную версию.
+func (d *Database) MigrateTo(target int) error {
+	return d.migrate(d.dialect.migrations(), target)
+}
+
+// migrate приводит схему к версии target по миграциям из fsys
+func (d *Database) migrate(fsys fs.FS, target int) error {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+	if target < 0 {
+		target = len(migrations)
+	}
+	if target > len(migrations) {
+		return fmt.Errorf("unknown schema version %d, latest is %d", target, len(migrations))
+	}
+
+	ctx := context.Background()
+
+	// Миграции выполняются на отдельном соединении: в SQLite на нем отключаются внешние
+	// ключи, чтобы миграции могли перестраивать таблицы, а целостность проверяется перед
+	// коммитом; в PostgreSQL на нем держится блокировка от одновременных миграций
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if d.dialect == dialectPostgres {
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock(?)", migrationsLockID); err != nil {
+			return fmt.Errorf("failed to lock migrations: %w", err)
+		}
+		defer conn.ExecContext(ctx, "SELECT pg_advisory_unlock(?)", migrationsLockID)
+	} else {
+		if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+			return fmt.Errorf("failed to disable foreign keys: %w", err)
+		}
+		defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON")
+	}
+
+	current, err := prepareSchemaMigrations(ctx, conn, d.dialect, migrations)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return fmt.Errorf("%w: database is at version %d, binary supports up to %d", ErrSchemaTooNew, current, len(migrations))
+	}
+
+	for version := current + 1; version <= target; version++ {
+		if err := applyMigration(ctx, conn, d.dialect, migrations[version-1], true); err != nil {
+			return err
+		}
+		log.Printf("Database schema migrated to version %d (%s)", version, migrations[version-1].Name)
+	}
+
+	for version := current; version > target; version-- {
+		if err := applyMigration(ctx, conn, d.dialect, migrations[version-1], false); err != nil {
+			return err
+		}
+		log.Printf("Database schema rolled back to version %d", version-1)
+	}
+
+	return nil
+}
+
+// SchemaVersion возвращает текущую версию схемы базы данных
+func (d *Database) SchemaVersion() (int, error) {
+	var version int
+	err := d.db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+	return version, nil
+}
+
+// prepareSchemaMigrations создает таблицу schema_migrations и возвращает текущую версию.
+// Базы SQLite, созданные до появления миграций, получают записи для уже существующих версий.
+func prepareSchemaMigrations(ctx context.Context, conn *sql.Conn, dialect dialect, migrations []Migration) (int, error) {
+	query := "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'"
+	appliedAt := "DATETIME"
+	if dialect == dialectPostgres {
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = 'schema_migrations'"
+		appliedAt = "TIMESTAMPTZ"
+	}
+
+	var exists int
+	if err := conn.QueryRowContext(ctx, query).Scan(&exists); err != nil {
+		return 0, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+
+	if exists == 0 {
+		baseline := 0
+		if dialect == dialectSQLite {
+			var err error
+			if baseline, err = detectLegacyVersion(ctx, conn); err != nil {
+				return 0, err
+			}
+		}
+
+		tx, err := conn.BeginTx(ctx, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		defer tx.Rollback()
+
+		_, err = tx.ExecContext(ctx, `
+		CREATE TABLE schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at `+appliedAt+` NOT NULL
+		);`)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+
+		for _, migration := range migrations[:baseline] {
+			if err := recordMigration(ctx, tx, migration); err != nil {
+				return 0, err
+			}
+		}
+
+		if err := tx.Commit(); err != nil {
+			return 0, fmt.Errorf("failed to commit schema_migrations: %w", err)
+		}
+
+		if baseline > 0 {
+			log.Printf("Existing database schema detected at version %d", baseline)
+		}
+	}
+
+	var version int
+	err := conn.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// detectLegacyVersion определяет версию схемы, созданной до появления миграций,
+// по наличию таблиц и колонок, которые добавляла каждая миграция.
+// Новые миграции сюда не добавляются: без schema_migrations их не применить.
+func detectLegacyVersion(ctx context.Context, conn *sql.Conn) (int, error) {
+	checks := []string{
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'tasks'",
+		"SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'task_history'",
+		"SELECT COUNT(*) FROM pragma_table_info('api_limits') WHERE name = 'tier'",
+		"SELECT COUNT(*) FROM pragma_foreign_key_list('tasks') WHERE \"table\" = 'users'",
+	}
+
+	for version, query := range checks {
+		var count int
+		if err := conn.QueryRowContext(ctx, query).Scan(&count); err != nil {
+			return 0, fmt.Errorf("failed to inspect schema: %w", err)
+		}
+		if count == 0 {
+			return version, nil
+		}
+	}
+
+	return len(checks), nil
+}
+
+// applyMigration выполняет up- или down-скрипт миграции в одной транзакции
+func applyMigration(ctx context.Context, conn *sql.Conn, dialect dialect, migration Migration, up bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	script := migration.Up
+	if !up {
+		script = migration.Down
+	}
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	// PostgreSQL проверяет внешние ключи при каждой команде
+	if dialect == dialectSQLite {
+		if err := checkForeignKeys(ctx, tx); err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	if up {
+		err = recordMigration(ctx, tx, migration)
+	} else {
+		_, err = tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = ?", migration.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", migration.Version, err)
+	}
+
+	return nil
+}
+
+// recordMigration сохраняет запись о примененной миграции
+func recordMigration(ctx context.Context, tx *sql.Tx, migration Migration) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)",
+		migration.Version, migration.Name, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// checkForeignKeys проверяет, что после миграции не осталось ссылок на несуществующие строки
+func checkForeignKeys(ctx context.Context, tx *sql.Tx) error {
+	rows, err := tx.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return fmt.Errorf("failed to check foreign keys: %w", err)
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table string
+		var rowID sql.NullInt64
+		var parent string
+		var fkID int
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return fmt.Errorf("failed to check foreign keys: %w", err)
+		}
+		return fmt.Errorf("foreign key violation in %s (row %d) referencing %s", table, rowID.Int64, parent)
+	}
+
+	return rows.Err()
+}
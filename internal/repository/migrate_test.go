@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func tableExists(t *testing.T, db *Database, name string) bool {
+	var count int
+	require.NoError(t, db.GetDB().QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&count))
+	return count > 0
+}
+
+func TestMigrations(t *testing.T) {
+	migrations, err := loadMigrations(dialectSQLite.migrations())
+	require.NoError(t, err)
+	latest := len(migrations)
+
+	t.Run("fresh database is at the latest version", func(t *testing.T) {
+		db, _ := setupTestDB(t)
+
+		version, err := db.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latest, version)
+
+		var recorded int
+		require.NoError(t, db.GetDB().QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE applied_at IS NOT NULL").Scan(&recorded))
+		assert.Equal(t, latest, recorded)
+	})
+
+	t.Run("down and up again", func(t *testing.T) {
+		db, _ := setupTestDB(t)
+
+		require.NoError(t, db.MigrateTo(0))
+		version, err := db.SchemaVersion()
+		require.NoError(t, err)
+		assert.Zero(t, version)
+		assert.False(t, tableExists(t, db, "tasks"))
+		assert.False(t, tableExists(t, db, "users"))
+
+		require.NoError(t, db.Migrate())
+		version, err = db.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latest, version)
+		assert.True(t, tableExists(t, db, "users"))
+	})
+
+	t.Run("rollback keeps data", func(t *testing.T) {
+		db, repo := setupTestDB(t)
+		task := createTestTask(123)
+		require.NoError(t, repo.AddTask(t.Context(), task))
+
+		require.NoError(t, db.MigrateTo(3))
+		assert.False(t, tableExists(t, db, "users"))
+
+		require.NoError(t, db.Migrate())
+		saved, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, task.OriginalDescription, saved.OriginalDescription)
+	})
+
+	t.Run("newer database is refused", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "newer.db")
+		db, err := NewDatabase(dbPath)
+		skipWithoutFTS5(t, err)
+		require.NoError(t, err)
+		_, err = db.GetDB().Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, 'future', '2030-01-01T00:00:00Z')", latest+1)
+		require.NoError(t, err)
+		require.NoError(t, db.Close())
+
+		_, err = NewDatabase(dbPath)
+		assert.ErrorIs(t, err, ErrSchemaTooNew)
+	})
+
+	t.Run("failed migration is rolled back", func(t *testing.T) {
+		db, err := NewDatabase(filepath.Join(t.TempDir(), "failed.db"))
+		skipWithoutFTS5(t, err)
+		require.NoError(t, err)
+		defer db.Close()
+
+		// The embedded migrations plus one that fails halfway through
+		fsys := fstest.MapFS{}
+		for _, migration := range migrations {
+			base := fmt.Sprintf("%04d_%s", migration.Version, migration.Name)
+			fsys[base+".up.sql"] = &fstest.MapFile{Data: []byte(migration.Up)}
+			fsys[base+".down.sql"] = &fstest.MapFile{Data: []byte(migration.Down)}
+		}
+		broken := fmt.Sprintf("%04d_broken", latest+1)
+		fsys[broken+".up.sql"] = &fstest.MapFile{Data: []byte("CREATE TABLE half_done (id INTEGER); INSERT INTO missing_table VALUES (1);")}
+		fsys[broken+".down.sql"] = &fstest.MapFile{Data: []byte("DROP TABLE half_done;")}
+
+		err = db.migrate(fsys, -1)
+		assert.Error(t, err)
+
+		version, err := db.SchemaVersion()
+		require.NoError(t, err)
+		assert.Equal(t, latest, version)
+		assert.False(t, tableExists(t, db, "half_done"))
+	})
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("embedded migrations are valid", func(t *testing.T) {
+		migrations, err := loadMigrations(dialectSQLite.migrations())
+		require.NoError(t, err)
+		require.NotEmpty(t, migrations)
+		assert.Equal(t, "initial_schema", migrations[0].Name)
+	})
+
+	t.Run("dialects have the same migrations", func(t *testing.T) {
+		sqlite, err := loadMigrations(dialectSQLite.migrations())
+		require.NoError(t, err)
+		postgres, err := loadMigrations(dialectPostgres.migrations())
+		require.NoError(t, err)
+
+		require.Len(t, postgres, len(sqlite))
+		for i := range sqlite {
+			assert.Equal(t, sqlite[i].Version, postgres[i].Version)
+			assert.Equal(t, sqlite[i].Name, postgres[i].Name)
+		}
+	})
+
+	t.Run("missing down script", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{
+			"0001_init.up.sql": {Data: []byte("CREATE TABLE t (id INTEGER);")},
+		})
+		assert.ErrorContains(t, err, "must have up and down scripts")
+	})
+
+	t.Run("gap in versions", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{
+			"0001_init.up.sql":   {Data: []byte("CREATE TABLE t (id INTEGER);")},
+			"0001_init.down.sql": {Data: []byte("DROP TABLE t;")},
+			"0003_next.up.sql":   {Data: []byte("CREATE TABLE u (id INTEGER);")},
+			"0003_next.down.sql": {Data: []byte("DROP TABLE u;")},
+		})
+		assert.ErrorContains(t, err, "migration 2 is missing")
+	})
+
+	t.Run("unexpected file", func(t *testing.T) {
+		_, err := loadMigrations(fstest.MapFS{
+			"README.md": {Data: []byte("notes")},
+		})
+		assert.ErrorContains(t, err, "unexpected migration file")
+	})
+}
+
+func TestMigrations_LegacyDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+
+	// Schema of databases created before versioned migrations and the users table
+	legacy, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = legacy.Exec(`
+		CREATE TABLE tasks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			user_id INTEGER NOT NULL,
+			original_description TEXT NOT NULL,
+			llm_processed_desc TEXT,
+			deadline DATETIME,
+			status TEXT CHECK(status IN ('active', 'done', 'postponed')) DEFAULT 'active',
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE discussions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			task_id INTEGER NOT NULL,
+			message_id INTEGER NOT NULL,
+			text TEXT NOT NULL,
+			timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+			FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+		);
+		CREATE TABLE api_limits (
+			user_id INTEGER PRIMARY KEY,
+			requests_count INTEGER DEFAULT 0,
+			reset_date DATETIME NOT NULL,
+			is_premium BOOLEAN DEFAULT 0
+		);
+		INSERT INTO tasks (user_id, original_description) VALUES (10, 'Old task'), (11, 'Another task');
+		INSERT INTO api_limits (user_id, requests_count, reset_date) VALUES (12, 3, '2025-08-01T00:00:00Z');
+	`)
+	require.NoError(t, err)
+	require.NoError(t, legacy.Close())
+
+	db, err := NewDatabase(dbPath)
+	skipWithoutFTS5(t, err)
+	require.NoError(t, err)
+
+	// Known user IDs are backfilled and existing rows are kept
+	users := NewUserRepository(db)
+	for _, id := range []int{10, 11, 12} {
+		_, err := users.GetUser(t.Context(), id)
+		assert.NoError(t, err, "user %d", id)
+	}
+
+	task, err := NewTaskRepository(db).GetTask(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, "Old task", task.OriginalDescription)
+
+	var tier string
+	var count int
+	require.NoError(t, db.GetDB().QueryRow("SELECT tier, requests_count FROM api_limits WHERE user_id = 12").Scan(&tier, &count))
+	assert.Equal(t, models.TierFree, tier)
+	assert.Equal(t, 3, count)
+
+	// Foreign keys are enforced after the rebuild
+	_, err = db.GetDB().Exec("INSERT INTO tasks (user_id, original_description) VALUES (99, 'Orphan')")
+	assert.Error(t, err)
+
+	migrations, err := loadMigrations(dialectSQLite.migrations())
+	require.NoError(t, err)
+	version, err := db.SchemaVersion()
+	require.NoError(t, err)
+	assert.Equal(t, len(migrations), version)
+
+	// A second start does not rebuild the tables again
+	require.NoError(t, db.Close())
+	db, err = NewDatabase(dbPath)
+	skipWithoutFTS5(t, err)
+	require.NoError(t, err)
+	defer db.Close()
+
+	task, err = NewTaskRepository(db).GetTask(t.Context(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, "Another task", task.OriginalDescription)
+}
+
+func TestMigrations_CanonicalTimestamps(t *testing.T) {
+	db, err := NewDatabase(filepath.Join(t.TempDir(), "timestamps.db"))
+	skipWithoutFTS5(t, err)
+	require.NoError(t, err)
+	defer db.Close()
+
+	// Rows written by versions that stored RFC 3339 strings with the offset of the server
+	require.NoError(t, db.MigrateTo(13))
+	_, err = db.GetDB().Exec(`
+		INSERT INTO users (id, first_name, created_at, updated_at) VALUES (1, 'Old', '2025-03-01T12:00:00+03:00', NULL);
+		INSERT INTO tasks (id, user_id, original_description, deadline, status, created_at, updated_at) VALUES
+			(1, 1, 'Passed', '2025-03-02T08:00:00+10:00', 'active', '2025-03-01T12:00:00+03:00', '2025-03-01T09:00:00Z'),
+			(2, 1, 'Later', '2025-03-01T23:30:00Z', 'active', '2025-03-01T12:00:00+03:00', '2025-03-01T09:00:00Z'),
+			(3, 1, 'Broken', 'someday', 'active', 'yesterday', '2025-03-01T09:00:00Z');
+		INSERT INTO sent_reminders (task_id, kind, deadline, sent_at) VALUES
+			(1, '1h', '2025-03-02T08:00:00+10:00', '2025-03-01T21:00:00Z'),
+			(1, '1h', '2025-03-01T22:00:00Z', '2025-03-01T21:00:00Z'),
+			(3, '1h', 'someday', '2025-03-01T21:00:00Z');
+	`)
+	require.NoError(t, err)
+
+	require.NoError(t, db.Migrate())
+
+	stored := func(query string) []string {
+		rows, err := db.GetDB().Query(query)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		var values []string
+		for rows.Next() {
+			var value string
+			require.NoError(t, rows.Scan(&value))
+			values = append(values, value)
+		}
+		require.NoError(t, rows.Err())
+		return values
+	}
+
+	assert.Equal(t, []string{"2025-03-01 22:00:00", "2025-03-01 23:30:00"},
+		stored("SELECT CAST(deadline AS TEXT) FROM tasks WHERE deadline IS NOT NULL ORDER BY deadline"))
+	assert.Equal(t, []string{"2025-03-01 09:00:00", "2025-03-01 09:00:00", "2025-03-01 09:00:00"},
+		stored("SELECT CAST(created_at AS TEXT) FROM tasks"))
+	assert.Equal(t, []string{"2025-03-01 09:00:00"}, stored("SELECT CAST(updated_at AS TEXT) FROM users"))
+	assert.Equal(t, []string{"2025-03-01 22:00:00"}, stored("SELECT CAST(deadline AS TEXT) FROM sent_reminders"))
+
+	task, err := NewTaskRepository(db).GetTask(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 22, 0, 0, 0, time.UTC), task.Deadline)
+	assert.Equal(t, time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC), task.CreatedAt)
+
+	// Unreadable times are dropped, so the task can be read and listed
+	task, err = NewTaskRepository(db).GetTask(t.Context(), 3)
+	require.NoError(t, err)
+	assert.False(t, task.HasDeadline())
+	tasks, err := NewTaskRepository(db).GetActiveTasks(t.Context(), 1)
+	require.NoError(t, err)
+	assert.Len(t, tasks, 3)
+
+	// Rolling back restores the strings the previous version reads
+	require.NoError(t, db.MigrateTo(13))
+	assert.Equal(t, []string{"2025-03-01T22:00:00Z", "2025-03-01T23:30:00Z"},
+		stored("SELECT CAST(deadline AS TEXT) FROM tasks WHERE deadline IS NOT NULL ORDER BY id"))
+}
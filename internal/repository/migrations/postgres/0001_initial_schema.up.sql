@@ -0,0 +1,32 @@
+CREATE TABLE tasks (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	original_description TEXT NOT NULL,
+	llm_processed_desc TEXT,
+	deadline TIMESTAMPTZ,
+	status TEXT CHECK(status IN ('active', 'done', 'postponed')) DEFAULT 'active',
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE discussions (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	task_id BIGINT NOT NULL,
+	message_id BIGINT NOT NULL,
+	text TEXT NOT NULL,
+	timestamp TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE api_limits (
+	user_id BIGINT PRIMARY KEY,
+	requests_count INTEGER DEFAULT 0,
+	reset_date TIMESTAMPTZ NOT NULL,
+	is_premium BOOLEAN DEFAULT FALSE
+);
+
+CREATE INDEX idx_tasks_user_id ON tasks(user_id);
+CREATE INDEX idx_tasks_status ON tasks(status);
+CREATE INDEX idx_tasks_deadline ON tasks(deadline);
+CREATE INDEX idx_discussions_task_id ON discussions(task_id);
+CREATE INDEX idx_discussions_message_id ON discussions(message_id);
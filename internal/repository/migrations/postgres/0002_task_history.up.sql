@@ -0,0 +1,12 @@
+CREATE TABLE task_history (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	task_id BIGINT NOT NULL,
+	user_id BIGINT NOT NULL,
+	field TEXT NOT NULL,
+	old_value TEXT,
+	new_value TEXT,
+	changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_history_task_id ON task_history(task_id);
@@ -0,0 +1 @@
+ALTER TABLE api_limits DROP COLUMN tier;
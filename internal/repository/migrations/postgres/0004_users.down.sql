@@ -0,0 +1,4 @@
+ALTER TABLE api_limits DROP CONSTRAINT api_limits_user_id_fkey;
+ALTER TABLE tasks DROP CONSTRAINT tasks_user_id_fkey;
+
+DROP TABLE users;
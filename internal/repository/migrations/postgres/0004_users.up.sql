@@ -0,0 +1,21 @@
+CREATE TABLE users (
+	id BIGINT PRIMARY KEY,
+	username TEXT,
+	first_name TEXT NOT NULL DEFAULT '',
+	last_name TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_users_username ON users(lower(username));
+
+-- Users seen before the registry existed; their profile is filled in on the next message
+INSERT INTO users (id)
+SELECT user_id FROM tasks
+UNION
+SELECT user_id FROM api_limits;
+
+ALTER TABLE tasks ADD CONSTRAINT tasks_user_id_fkey
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE api_limits ADD CONSTRAINT api_limits_user_id_fkey
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,14 @@
+-- Lead times in minutes separated by commas; NULL means the default lead times
+ALTER TABLE users ADD COLUMN reminder_lead_times TEXT;
+ALTER TABLE users ADD COLUMN reminders_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Reminders already delivered; the deadline is part of the key so that
+-- moving a deadline arms the reminders again
+CREATE TABLE sent_reminders (
+	task_id BIGINT NOT NULL,
+	kind TEXT NOT NULL,
+	deadline TIMESTAMPTZ NOT NULL,
+	sent_at TIMESTAMPTZ NOT NULL,
+	PRIMARY KEY (task_id, kind, deadline),
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+-- Deadlines with a time of day; existing deadlines are all-day (end of the day)
+ALTER TABLE tasks ADD COLUMN deadline_has_time BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- Recurring tasks: the RRULE of the series and a link to the first task of the series
+ALTER TABLE tasks ADD COLUMN recurrence TEXT;
+ALTER TABLE tasks ADD COLUMN series_id BIGINT REFERENCES tasks(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_series_id ON tasks(series_id);
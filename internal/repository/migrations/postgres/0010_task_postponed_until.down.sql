@@ -0,0 +1,3 @@
+DROP INDEX idx_tasks_postponed_until;
+
+ALTER TABLE tasks DROP COLUMN postponed_until;
@@ -0,0 +1,4 @@
+-- Postponed tasks: when the task becomes active again, NULL means until changed by hand
+ALTER TABLE tasks ADD COLUMN postponed_until TIMESTAMPTZ;
+
+CREATE INDEX idx_tasks_postponed_until ON tasks(postponed_until);
@@ -0,0 +1,4 @@
+DROP INDEX idx_task_tags_tag_id;
+
+DROP TABLE task_tags;
+DROP TABLE tags;
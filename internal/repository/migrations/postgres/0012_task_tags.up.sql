@@ -0,0 +1,18 @@
+-- Tags of a user and the many-to-many link between tasks and tags
+CREATE TABLE tags (
+	id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+	user_id BIGINT NOT NULL,
+	name TEXT NOT NULL,
+	UNIQUE(user_id, name),
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE TABLE task_tags (
+	task_id BIGINT NOT NULL,
+	tag_id BIGINT NOT NULL,
+	PRIMARY KEY(task_id, tag_id),
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE,
+	FOREIGN KEY(tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_tags_tag_id ON task_tags(tag_id);
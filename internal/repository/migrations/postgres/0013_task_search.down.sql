@@ -0,0 +1,14 @@
+DROP TRIGGER task_search_discussion_change ON discussions;
+DROP TRIGGER task_search_task_update ON tasks;
+DROP TRIGGER task_search_task_insert ON tasks;
+
+DROP FUNCTION task_search_discussion_changed();
+DROP FUNCTION task_search_task_changed();
+
+DROP TABLE task_search;
+
+DROP FUNCTION task_search_discussions(BIGINT);
+DROP FUNCTION task_search_text(TEXT);
+
+-- The unaccent extension is kept: other schemas of the database may use it
+DROP TEXT SEARCH CONFIGURATION unaccented;
@@ -0,0 +1,65 @@
+-- Full-text index over task descriptions and attached discussions: a row per task with its
+-- texts and a weighted tsvector of them. The unaccented configuration removes diacritics
+-- like unicode61 does in SQLite, and "ё" is stored as "е" as in the SQLite index.
+-- unaccent is a trusted extension, so the owner of the database can create it.
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+CREATE TEXT SEARCH CONFIGURATION unaccented (COPY = simple);
+ALTER TEXT SEARCH CONFIGURATION unaccented
+	ALTER MAPPING FOR hword, hword_part, word WITH unaccent, simple;
+
+CREATE TABLE task_search (
+	task_id BIGINT PRIMARY KEY,
+	description TEXT NOT NULL DEFAULT '',
+	processed TEXT NOT NULL DEFAULT '',
+	discussions TEXT NOT NULL DEFAULT '',
+	document TSVECTOR GENERATED ALWAYS AS (
+		setweight(to_tsvector('unaccented', description), 'A') ||
+		setweight(to_tsvector('unaccented', processed), 'A') ||
+		setweight(to_tsvector('unaccented', discussions), 'B')
+	) STORED,
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_search_document ON task_search USING GIN (document);
+
+CREATE FUNCTION task_search_text(value TEXT) RETURNS TEXT AS $$
+	SELECT translate(COALESCE(value, ''), 'ёЁ', 'еЕ')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE FUNCTION task_search_discussions(search_task_id BIGINT) RETURNS TEXT AS $$
+	SELECT task_search_text(string_agg(text, E'\n' ORDER BY id)) FROM discussions WHERE task_id = search_task_id
+$$ LANGUAGE SQL STABLE;
+
+INSERT INTO task_search (task_id, description, processed, discussions)
+SELECT id, task_search_text(original_description), task_search_text(llm_processed_desc), task_search_discussions(id)
+FROM tasks;
+
+CREATE FUNCTION task_search_task_changed() RETURNS TRIGGER AS $$
+BEGIN
+	INSERT INTO task_search (task_id, description, processed)
+	VALUES (NEW.id, task_search_text(NEW.original_description), task_search_text(NEW.llm_processed_desc))
+	ON CONFLICT (task_id) DO UPDATE
+	SET description = excluded.description, processed = excluded.processed;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER task_search_task_insert AFTER INSERT ON tasks
+	FOR EACH ROW EXECUTE FUNCTION task_search_task_changed();
+
+CREATE TRIGGER task_search_task_update AFTER UPDATE OF original_description, llm_processed_desc ON tasks
+	FOR EACH ROW EXECUTE FUNCTION task_search_task_changed();
+
+-- OLD is NULL on insert and NEW on delete, so one function covers all changes of discussions
+CREATE FUNCTION task_search_discussion_changed() RETURNS TRIGGER AS $$
+BEGIN
+	UPDATE task_search
+	SET discussions = task_search_discussions(task_search.task_id)
+	WHERE task_search.task_id IN (OLD.task_id, NEW.task_id);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER task_search_discussion_change AFTER INSERT OR UPDATE OR DELETE ON discussions
+	FOR EACH ROW EXECUTE FUNCTION task_search_discussion_changed();
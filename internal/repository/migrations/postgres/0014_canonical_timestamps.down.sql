@@ -0,0 +1,2 @@
+-- PostgreSQL has stored timestamps as TIMESTAMPTZ from the first version, so only
+-- SQLite databases need their timestamps converted
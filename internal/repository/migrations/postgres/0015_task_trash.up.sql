@@ -0,0 +1,5 @@
+-- Soft deletion: when the task was moved to the trash, NULL for tasks that are not deleted.
+-- Tasks stay in the trash with their tags and discussions until they are restored or purged.
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tasks_deleted_at ON tasks(deleted_at);
@@ -0,0 +1,3 @@
+DROP TABLE api_limits;
+DROP TABLE discussions;
+DROP TABLE tasks;
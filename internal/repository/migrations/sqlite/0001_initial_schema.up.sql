@@ -0,0 +1,32 @@
+CREATE TABLE tasks (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	original_description TEXT NOT NULL,
+	llm_processed_desc TEXT,
+	deadline DATETIME,
+	status TEXT CHECK(status IN ('active', 'done', 'postponed')) DEFAULT 'active',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE discussions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	message_id INTEGER NOT NULL,
+	text TEXT NOT NULL,
+	timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE TABLE api_limits (
+	user_id INTEGER PRIMARY KEY,
+	requests_count INTEGER DEFAULT 0,
+	reset_date DATETIME NOT NULL,
+	is_premium BOOLEAN DEFAULT 0
+);
+
+CREATE INDEX idx_tasks_user_id ON tasks(user_id);
+CREATE INDEX idx_tasks_status ON tasks(status);
+CREATE INDEX idx_tasks_deadline ON tasks(deadline);
+CREATE INDEX idx_discussions_task_id ON discussions(task_id);
+CREATE INDEX idx_discussions_message_id ON discussions(message_id);
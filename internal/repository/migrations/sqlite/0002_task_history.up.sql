@@ -0,0 +1,12 @@
+CREATE TABLE task_history (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	task_id INTEGER NOT NULL,
+	user_id INTEGER NOT NULL,
+	field TEXT NOT NULL,
+	old_value TEXT,
+	new_value TEXT,
+	changed_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(task_id) REFERENCES tasks(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_task_history_task_id ON task_history(task_id);
@@ -0,0 +1,4 @@
+ALTER TABLE api_limits ADD COLUMN tier TEXT CHECK(tier IN ('free', 'premium', 'admin')) DEFAULT 'free';
+
+-- Legacy premium flags become the premium tier
+UPDATE api_limits SET tier = 'premium' WHERE is_premium = 1;
@@ -0,0 +1,36 @@
+CREATE TABLE tasks_old (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	original_description TEXT NOT NULL,
+	llm_processed_desc TEXT,
+	deadline DATETIME,
+	status TEXT CHECK(status IN ('active', 'done', 'postponed')) DEFAULT 'active',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO tasks_old (id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at)
+SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_old RENAME TO tasks;
+
+CREATE INDEX idx_tasks_user_id ON tasks(user_id);
+CREATE INDEX idx_tasks_status ON tasks(status);
+CREATE INDEX idx_tasks_deadline ON tasks(deadline);
+
+CREATE TABLE api_limits_old (
+	user_id INTEGER PRIMARY KEY,
+	requests_count INTEGER DEFAULT 0,
+	reset_date DATETIME NOT NULL,
+	is_premium BOOLEAN DEFAULT 0,
+	tier TEXT CHECK(tier IN ('free', 'premium', 'admin')) DEFAULT 'free'
+);
+
+INSERT INTO api_limits_old (user_id, requests_count, reset_date, is_premium, tier)
+SELECT user_id, requests_count, reset_date, is_premium, tier FROM api_limits;
+
+DROP TABLE api_limits;
+ALTER TABLE api_limits_old RENAME TO api_limits;
+
+DROP TABLE users;
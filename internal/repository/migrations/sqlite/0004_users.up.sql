@@ -0,0 +1,54 @@
+CREATE TABLE users (
+	id INTEGER PRIMARY KEY,
+	username TEXT,
+	first_name TEXT NOT NULL DEFAULT '',
+	last_name TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_users_username ON users(username COLLATE NOCASE);
+
+-- Users seen before the registry existed; their profile is filled in on the next message
+INSERT INTO users (id)
+SELECT user_id FROM tasks
+UNION
+SELECT user_id FROM api_limits;
+
+-- SQLite cannot add a foreign key to an existing table, so tasks and api_limits are rebuilt
+CREATE TABLE tasks_new (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	user_id INTEGER NOT NULL,
+	original_description TEXT NOT NULL,
+	llm_processed_desc TEXT,
+	deadline DATETIME,
+	status TEXT CHECK(status IN ('active', 'done', 'postponed')) DEFAULT 'active',
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO tasks_new (id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at)
+SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at FROM tasks;
+
+DROP TABLE tasks;
+ALTER TABLE tasks_new RENAME TO tasks;
+
+CREATE INDEX idx_tasks_user_id ON tasks(user_id);
+CREATE INDEX idx_tasks_status ON tasks(status);
+CREATE INDEX idx_tasks_deadline ON tasks(deadline);
+
+CREATE TABLE api_limits_new (
+	user_id INTEGER PRIMARY KEY,
+	requests_count INTEGER DEFAULT 0,
+	reset_date DATETIME NOT NULL,
+	is_premium BOOLEAN DEFAULT 0,
+	tier TEXT CHECK(tier IN ('free', 'premium', 'admin')) DEFAULT 'free',
+	FOREIGN KEY(user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+INSERT INTO api_limits_new (user_id, requests_count, reset_date, is_premium, tier)
+SELECT user_id, requests_count, reset_date, is_premium, tier FROM api_limits;
+
+DROP TABLE api_limits;
+ALTER TABLE api_limits_new RENAME TO api_limits;
@@ -0,0 +1,4 @@
+DROP TABLE sent_reminders;
+
+ALTER TABLE users DROP COLUMN reminders_enabled;
+ALTER TABLE users DROP COLUMN reminder_lead_times;
@@ -0,0 +1,2 @@
+-- IANA time zone of the user; NULL means the server's zone
+ALTER TABLE users ADD COLUMN time_zone TEXT;
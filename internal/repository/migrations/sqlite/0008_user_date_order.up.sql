@@ -0,0 +1,2 @@
+-- Order of day and month in numeric dates: dmy, mdy or ymd; NULL means not chosen yet
+ALTER TABLE users ADD COLUMN date_order TEXT CHECK (date_order IN ('dmy', 'mdy', 'ymd'));
@@ -0,0 +1,2 @@
+-- Task priorities: 1 (urgent) to 4 (low), 0 when not set
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0 CHECK(priority BETWEEN 0 AND 4);
@@ -0,0 +1,8 @@
+DROP TRIGGER task_search_discussion_delete;
+DROP TRIGGER task_search_discussion_update;
+DROP TRIGGER task_search_discussion_insert;
+DROP TRIGGER task_search_task_delete;
+DROP TRIGGER task_search_task_update;
+DROP TRIGGER task_search_task_insert;
+
+DROP TABLE task_search;
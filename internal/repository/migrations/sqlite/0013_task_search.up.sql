@@ -0,0 +1,48 @@
+-- Full-text index over task descriptions and attached discussions. The rowid of a row
+-- is the task ID. FTS5 needs go-sqlite3 built with the sqlite_fts5 tag. unicode61 removes
+-- diacritics from Latin letters only, so "ё" is indexed as "е".
+CREATE VIRTUAL TABLE task_search USING fts5(
+	description,
+	processed,
+	discussions,
+	tokenize = "unicode61 remove_diacritics 2"
+);
+
+INSERT INTO task_search (rowid, description, processed, discussions)
+SELECT id, replace(replace(original_description, 'ё', 'е'), 'Ё', 'Е'), replace(replace(COALESCE(llm_processed_desc, ''), 'ё', 'е'), 'Ё', 'Е'),
+	replace(replace(COALESCE((SELECT group_concat(text, char(10)) FROM discussions WHERE discussions.task_id = tasks.id), ''), 'ё', 'е'), 'Ё', 'Е')
+FROM tasks;
+
+CREATE TRIGGER task_search_task_insert AFTER INSERT ON tasks BEGIN
+	INSERT INTO task_search (rowid, description, processed, discussions)
+	VALUES (new.id, replace(replace(new.original_description, 'ё', 'е'), 'Ё', 'Е'), replace(replace(COALESCE(new.llm_processed_desc, ''), 'ё', 'е'), 'Ё', 'Е'), '');
+END;
+
+CREATE TRIGGER task_search_task_update AFTER UPDATE OF original_description, llm_processed_desc ON tasks BEGIN
+	UPDATE task_search
+	SET description = replace(replace(new.original_description, 'ё', 'е'), 'Ё', 'Е'),
+		processed = replace(replace(COALESCE(new.llm_processed_desc, ''), 'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = new.id;
+END;
+
+CREATE TRIGGER task_search_task_delete AFTER DELETE ON tasks BEGIN
+	DELETE FROM task_search WHERE rowid = old.id;
+END;
+
+CREATE TRIGGER task_search_discussion_insert AFTER INSERT ON discussions BEGIN
+	UPDATE task_search
+	SET discussions = replace(replace(COALESCE((SELECT group_concat(text, char(10)) FROM discussions WHERE task_id = new.task_id), ''), 'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = new.task_id;
+END;
+
+CREATE TRIGGER task_search_discussion_update AFTER UPDATE OF text ON discussions BEGIN
+	UPDATE task_search
+	SET discussions = replace(replace(COALESCE((SELECT group_concat(text, char(10)) FROM discussions WHERE task_id = new.task_id), ''), 'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = new.task_id;
+END;
+
+CREATE TRIGGER task_search_discussion_delete AFTER DELETE ON discussions BEGIN
+	UPDATE task_search
+	SET discussions = replace(replace(COALESCE((SELECT group_concat(text, char(10)) FROM discussions WHERE task_id = old.task_id), ''), 'ё', 'е'), 'Ё', 'Е')
+	WHERE rowid = old.task_id;
+END;
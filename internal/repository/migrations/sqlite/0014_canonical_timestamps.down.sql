@@ -0,0 +1,22 @@
+-- Back to the RFC 3339 strings in UTC that earlier versions read
+UPDATE tasks SET
+	deadline = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', deadline), deadline),
+	postponed_until = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', postponed_until), postponed_until),
+	created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+	updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE discussions SET timestamp = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', timestamp), timestamp);
+
+UPDATE task_history SET changed_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', changed_at), changed_at);
+
+UPDATE users SET
+	created_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', created_at), created_at),
+	updated_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', updated_at), updated_at);
+
+UPDATE api_limits SET reset_date = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', reset_date), reset_date);
+
+UPDATE sent_reminders SET
+	deadline = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', deadline), deadline),
+	sent_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', sent_at), sent_at);
+
+UPDATE schema_migrations SET applied_at = COALESCE(strftime('%Y-%m-%dT%H:%M:%SZ', applied_at), applied_at);
@@ -0,0 +1,34 @@
+-- Timestamps are stored in one form: UTC text with second precision, as CURRENT_TIMESTAMP
+-- writes it. Earlier versions wrote RFC 3339 strings, some of them with a local offset, which
+-- do not compare chronologically as text. datetime() converts any of them to the canonical
+-- form. Values it cannot read would make every list with the row fail, so they are dropped:
+-- deadlines become empty and other times fall back to a related time or the current one.
+
+UPDATE tasks SET
+	deadline = datetime(deadline),
+	deadline_has_time = deadline_has_time AND datetime(deadline) IS NOT NULL,
+	postponed_until = datetime(postponed_until),
+	created_at = COALESCE(datetime(created_at), datetime(updated_at), CURRENT_TIMESTAMP),
+	updated_at = COALESCE(datetime(updated_at), datetime(created_at), CURRENT_TIMESTAMP);
+
+UPDATE discussions SET timestamp = COALESCE(datetime(timestamp), CURRENT_TIMESTAMP);
+
+UPDATE task_history SET changed_at = COALESCE(datetime(changed_at), CURRENT_TIMESTAMP);
+
+UPDATE users SET
+	created_at = COALESCE(datetime(created_at), datetime(updated_at), CURRENT_TIMESTAMP),
+	updated_at = COALESCE(datetime(updated_at), datetime(created_at), CURRENT_TIMESTAMP);
+
+-- A reset date in the past starts a new period at the next request
+UPDATE api_limits SET reset_date = COALESCE(datetime(reset_date), CURRENT_TIMESTAMP);
+
+-- The same deadline written with different offsets becomes one key, and a key with an
+-- unreadable deadline matches no deadline
+DELETE FROM sent_reminders WHERE datetime(deadline) IS NULL OR rowid NOT IN (
+	SELECT MIN(rowid) FROM sent_reminders GROUP BY task_id, kind, datetime(deadline)
+);
+UPDATE sent_reminders SET
+	deadline = datetime(deadline),
+	sent_at = COALESCE(datetime(sent_at), CURRENT_TIMESTAMP);
+
+UPDATE schema_migrations SET applied_at = COALESCE(datetime(applied_at), CURRENT_TIMESTAMP);
@@ -0,0 +1,4 @@
+-- Tasks in the trash become ordinary tasks again
+DROP INDEX idx_tasks_deleted_at;
+
+ALTER TABLE tasks DROP COLUMN deleted_at;
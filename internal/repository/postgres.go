@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// isPostgresURL reports whether a database URL points to PostgreSQL rather than to an SQLite file
+func isPostgresURL(databaseURL string) bool {
+	return strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://")
+}
+
+// openPostgres opens a pool of pgx connections for database/sql
+func openPostgres(databaseURL string) (*sql.DB, error) {
+	config, err := pgx.ParseConfig(databaseURL)
+	if err != nil {
+		return nil, err
+	}
+	return sql.OpenDB(postgresConnector{stdlib.GetConnector(*config)}), nil
+}
+
+// postgresConnector opens connections that accept the ? placeholders the repositories
+// are written with, so the same queries run on SQLite and PostgreSQL
+type postgresConnector struct {
+	driver.Connector
+}
+
+func (c postgresConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &postgresConn{Conn: conn.(*stdlib.Conn)}, nil
+}
+
+// postgresConn numbers the placeholders of every query before pgx sees it
+type postgresConn struct {
+	*stdlib.Conn
+}
+
+func (c *postgresConn) Prepare(query string) (driver.Stmt, error) {
+	return c.Conn.Prepare(rebind(query))
+}
+
+func (c *postgresConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.Conn.PrepareContext(ctx, rebind(query))
+}
+
+func (c *postgresConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.Conn.ExecContext(ctx, rebind(query), args)
+}
+
+func (c *postgresConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.Conn.QueryContext(ctx, rebind(query), args)
+}
+
+// rebind replaces ? placeholders with the numbered $1, $2... of PostgreSQL. Question marks
+// in string literals, dollar-quoted bodies such as $$...$$ or $fn$...$fn$, quoted identifiers
+// and comments are kept. Backslash escapes of E'...' strings are not recognized.
+func rebind(query string) string {
+	if !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	placeholders := 0
+
+	for i := 0; i < len(query); i++ {
+		end := i + 1
+		switch {
+		case query[i] == '\'' || query[i] == '"':
+			end = skipPast(query, i+1, query[i:i+1])
+		case query[i] == '$' && dollarQuoteTag(query, i) != "":
+			tag := dollarQuoteTag(query, i)
+			end = skipPast(query, i+len(tag), tag)
+		case strings.HasPrefix(query[i:], "--"):
+			end = skipPast(query, i+2, "\n")
+		case strings.HasPrefix(query[i:], "/*"):
+			end = skipPast(query, i+2, "*/")
+		case query[i] == '?':
+			placeholders++
+			b.WriteString("$" + strconv.Itoa(placeholders))
+			continue
+		}
+
+		b.WriteString(query[i:end])
+		i = end - 1
+	}
+
+	return b.String()
+}
+
+// skipPast returns the index after the first terminator at or after start, or the end of
+// the query if an unterminated literal or comment runs to it
+func skipPast(query string, start int, terminator string) int {
+	if index := strings.Index(query[start:], terminator); index >= 0 {
+		return start + index + len(terminator)
+	}
+	return len(query)
+}
+
+// dollarQuoteTag returns the opening $tag$ of a dollar-quoted string at index i, or an empty
+// string if the $ starts a numbered parameter or is part of an identifier
+func dollarQuoteTag(query string, i int) string {
+	if i > 0 && isIdentifierChar(query[i-1]) {
+		return ""
+	}
+
+	for j := i + 1; j < len(query); j++ {
+		switch {
+		case query[j] == '$':
+			return query[i : j+1]
+		case !isIdentifierChar(query[j]), j == i+1 && query[j] >= '0' && query[j] <= '9':
+			return ""
+		}
+	}
+	return ""
+}
+
+// isIdentifierChar reports whether c may appear in an unquoted identifier or a dollar-quote tag
+func isIdentifierChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
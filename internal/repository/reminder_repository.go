@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// ReminderRepository defines the interface for deadline reminder storage
+type ReminderRepository interface {
+	GetReminderSettings(ctx context.Context, userID int) (*models.ReminderSettings, error)
+	SaveReminderSettings(ctx context.Context, settings *models.ReminderSettings) error
+	// GetTasksWithDeadlineBetween returns active tasks of all users with a deadline in [from, until]
+	GetTasksWithDeadlineBetween(ctx context.Context, from, until time.Time) ([]*models.Task, error)
+	// MarkReminderSent records the reminder and reports false if it was already recorded
+	MarkReminderSent(ctx context.Context, taskID int, kind string, deadline, sentAt time.Time) (bool, error)
+	// UnmarkReminderSent removes the record so the reminder is sent again
+	UnmarkReminderSent(ctx context.Context, taskID int, kind string, deadline time.Time) error
+	// ResumePostponedTasks makes postponed tasks active once their postponed_until has passed
+	// and returns them. Every task is returned by one call only.
+	ResumePostponedTasks(ctx context.Context, now time.Time) ([]*models.Task, error)
+}
+
+// SQLReminderRepository implements ReminderRepository for SQLite and PostgreSQL databases
+type SQLReminderRepository struct {
+	db    *sql.DB
+	tasks *SQLTaskRepository
+}
+
+// NewReminderRepository creates a new reminder repository instance
+func NewReminderRepository(database *Database) ReminderRepository {
+	return &SQLReminderRepository{
+		db:    database.GetDB(),
+		tasks: &SQLTaskRepository{db: database.GetDB(), dialect: database.dialect},
+	}
+}
+
+// GetReminderSettings retrieves the reminder settings of a user, falling back to the defaults
+func (r *SQLReminderRepository) GetReminderSettings(ctx context.Context, userID int) (*models.ReminderSettings, error) {
+	var leadTimes, timeZone sql.NullString
+	var enabled bool
+
+	err := r.db.QueryRowContext(ctx, "SELECT reminder_lead_times, reminders_enabled, time_zone FROM users WHERE id = ?", userID).Scan(&leadTimes, &enabled, &timeZone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: id %d", ErrUserNotFound, userID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reminder settings: %w", err)
+	}
+
+	settings := models.DefaultReminderSettings(userID)
+	settings.Enabled = enabled
+	settings.TimeZone = timeZone.String
+
+	if leadTimes.Valid {
+		settings.LeadTimes, err = parseLeadTimes(leadTimes.String)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse reminder lead times of user %d: %w", userID, err)
+		}
+	}
+
+	return settings, nil
+}
+
+// SaveReminderSettings updates the reminder settings of a user
+func (r *SQLReminderRepository) SaveReminderSettings(ctx context.Context, settings *models.ReminderSettings) error {
+	if err := settings.Validate(); err != nil {
+		return fmt.Errorf("reminder settings validation failed: %w", err)
+	}
+
+	settings.Normalize()
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET reminder_lead_times = ?, reminders_enabled = ? WHERE id = ?",
+		formatLeadTimes(settings.LeadTimes), settings.Enabled, settings.UserID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save reminder settings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id %d", ErrUserNotFound, settings.UserID)
+	}
+
+	return nil
+}
+
+// GetTasksWithDeadlineBetween retrieves active tasks of all users with a deadline in [from, until]
+func (r *SQLReminderRepository) GetTasksWithDeadlineBetween(ctx context.Context, from, until time.Time) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE status = ? AND deleted_at IS NULL AND deadline IS NOT NULL AND deadline >= ? AND deadline <= ?
+		ORDER BY deadline ASC
+	`
+
+	return r.tasks.queryTasks(ctx, query, models.StatusActive, from, until)
+}
+
+// MarkReminderSent records that a reminder for the task deadline was sent
+func (r *SQLReminderRepository) MarkReminderSent(ctx context.Context, taskID int, kind string, deadline, sentAt time.Time) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		"INSERT INTO sent_reminders (task_id, kind, deadline, sent_at) VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING",
+		taskID, kind, deadline, sentAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark reminder as sent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// UnmarkReminderSent removes the record of a reminder that could not be delivered
+func (r *SQLReminderRepository) UnmarkReminderSent(ctx context.Context, taskID int, kind string, deadline time.Time) error {
+	_, err := r.db.ExecContext(ctx,
+		"DELETE FROM sent_reminders WHERE task_id = ? AND kind = ? AND deadline = ?",
+		taskID, kind, deadline,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to unmark reminder: %w", err)
+	}
+	return nil
+}
+
+// ResumePostponedTasks returns postponed tasks whose postponed_until is not after now to active.
+// The update and the read are one statement, so concurrent schedulers never resume a task twice.
+func (r *SQLReminderRepository) ResumePostponedTasks(ctx context.Context, now time.Time) ([]*models.Task, error) {
+	query := `
+		UPDATE tasks
+		SET status = ?, postponed_until = NULL, updated_at = ?
+		WHERE status = ? AND deleted_at IS NULL AND postponed_until IS NOT NULL
+			AND postponed_until <= ?
+		RETURNING ` + taskColumns
+
+	tasks, err := r.tasks.queryTasks(ctx, query,
+		models.StatusActive, now, models.StatusPostponed, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume postponed tasks: %w", err)
+	}
+
+	return tasks, nil
+}
+
+// formatLeadTimes stores lead times as minutes separated by commas
+func formatLeadTimes(leadTimes []time.Duration) string {
+	minutes := make([]string, len(leadTimes))
+	for i, lead := range leadTimes {
+		minutes[i] = strconv.Itoa(int(lead / time.Minute))
+	}
+	return strings.Join(minutes, ",")
+}
+
+// parseLeadTimes reads lead times stored by formatLeadTimes
+func parseLeadTimes(value string) ([]time.Duration, error) {
+	leadTimes := []time.Duration{}
+	if value == "" {
+		return leadTimes, nil
+	}
+
+	for _, part := range strings.Split(value, ",") {
+		minutes, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, err
+		}
+		leadTimes = append(leadTimes, time.Duration(minutes)*time.Minute)
+	}
+
+	return leadTimes, nil
+}
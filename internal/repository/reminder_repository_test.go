@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReminderRepository(t *testing.T) {
+	db, taskRepo := setupTestDB(t)
+	repo := NewReminderRepository(db)
+
+	t.Run("default settings", func(t *testing.T) {
+		settings, err := repo.GetReminderSettings(t.Context(), 123)
+		require.NoError(t, err)
+		assert.True(t, settings.Enabled)
+		assert.Equal(t, models.DefaultReminderLeadTimes, settings.LeadTimes)
+	})
+
+	t.Run("save and load settings", func(t *testing.T) {
+		require.NoError(t, repo.SaveReminderSettings(t.Context(), &models.ReminderSettings{
+			UserID: 123, Enabled: true, LeadTimes: []time.Duration{30 * time.Minute, 3 * time.Hour},
+		}))
+
+		settings, err := repo.GetReminderSettings(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []time.Duration{3 * time.Hour, 30 * time.Minute}, settings.LeadTimes)
+
+		require.NoError(t, repo.SaveReminderSettings(t.Context(), &models.ReminderSettings{UserID: 123, Enabled: false}))
+		settings, err = repo.GetReminderSettings(t.Context(), 123)
+		require.NoError(t, err)
+		assert.False(t, settings.Enabled)
+		assert.Empty(t, settings.LeadTimes)
+	})
+
+	t.Run("unknown user", func(t *testing.T) {
+		_, err := repo.GetReminderSettings(t.Context(), 999)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		err = repo.SaveReminderSettings(t.Context(), models.DefaultReminderSettings(999))
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("sent reminders are recorded once per deadline", func(t *testing.T) {
+		deadline := time.Now().Add(time.Hour).Truncate(time.Second)
+		task := createTestTask(123)
+		task.Deadline = deadline
+		require.NoError(t, taskRepo.AddTask(t.Context(), task))
+
+		marked, err := repo.MarkReminderSent(t.Context(), task.ID, "lead:60", deadline, time.Now())
+		require.NoError(t, err)
+		assert.True(t, marked)
+
+		marked, err = repo.MarkReminderSent(t.Context(), task.ID, "lead:60", deadline, time.Now())
+		require.NoError(t, err)
+		assert.False(t, marked)
+
+		marked, err = repo.MarkReminderSent(t.Context(), task.ID, "lead:60", deadline.Add(time.Hour), time.Now())
+		require.NoError(t, err)
+		assert.True(t, marked)
+
+		require.NoError(t, repo.UnmarkReminderSent(t.Context(), task.ID, "lead:60", deadline))
+		marked, err = repo.MarkReminderSent(t.Context(), task.ID, "lead:60", deadline, time.Now())
+		require.NoError(t, err)
+		assert.True(t, marked)
+
+		tasks, err := repo.GetTasksWithDeadlineBetween(t.Context(), time.Now(), time.Now().Add(2*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, task.ID, tasks[0].ID)
+	})
+	t.Run("postponed tasks are resumed once", func(t *testing.T) {
+		now := time.Now().Truncate(time.Second)
+
+		due := createTestTask(123)
+		due.Postpone(now.Add(-time.Minute))
+		require.NoError(t, taskRepo.AddTask(t.Context(), due))
+
+		later := createTestTask(123)
+		later.Postpone(now.Add(time.Hour))
+		require.NoError(t, taskRepo.AddTask(t.Context(), later))
+
+		indefinite := createTestTask(123)
+		indefinite.Postpone(time.Time{})
+		require.NoError(t, taskRepo.AddTask(t.Context(), indefinite))
+
+		stored, err := taskRepo.GetTask(t.Context(), later.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.PostponedUntil.Equal(now.Add(time.Hour)))
+
+		resumed, err := repo.ResumePostponedTasks(t.Context(), now)
+		require.NoError(t, err)
+		require.Len(t, resumed, 1)
+		assert.Equal(t, due.ID, resumed[0].ID)
+		assert.Equal(t, models.StatusActive, resumed[0].Status)
+		assert.True(t, resumed[0].PostponedUntil.IsZero())
+
+		stored, err = taskRepo.GetTask(t.Context(), due.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusActive, stored.Status)
+
+		resumed, err = repo.ResumePostponedTasks(t.Context(), now)
+		require.NoError(t, err)
+		assert.Empty(t, resumed)
+
+		stored, err = taskRepo.GetTask(t.Context(), indefinite.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusPostponed, stored.Status)
+	})
+
+	t.Run("postponed_until is dropped with the postponed status", func(t *testing.T) {
+		task := createTestTask(123)
+		task.Postpone(time.Now().Add(time.Hour))
+		require.NoError(t, taskRepo.AddTask(t.Context(), task))
+
+		task.Status = models.StatusActive
+		require.NoError(t, taskRepo.UpdateTask(t.Context(), task))
+
+		stored, err := taskRepo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.True(t, stored.PostponedUntil.IsZero())
+	})
+}
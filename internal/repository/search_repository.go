@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// ErrEmptySearchQuery is returned when a search query contains no words
+var ErrEmptySearchQuery = errors.New("search query is empty")
+
+// searchPrefixMinLength is the length from which a word is also matched as a prefix,
+// so "отчет" finds "отчета" and "отчетами"
+const searchPrefixMinLength = 3
+
+// searchSnippetTokens is the approximate number of words in a search snippet
+const searchSnippetTokens = 12
+
+// searchColumnWeights are the bm25() weights of the task_search columns: description,
+// processed description and discussions
+const searchColumnWeights = "1.0, 1.0, 0.5"
+
+// searchHeadlineWords is the largest number of words in a PostgreSQL search snippet
+const searchHeadlineWords = 12
+
+// SearchTasks finds at most limit tasks of a user in the task_search full-text index,
+// the most relevant first
+func (r *SQLTaskRepository) SearchTasks(ctx context.Context, userID int, query string, limit int) ([]models.SearchResult, error) {
+	if r.dialect == dialectPostgres {
+		return r.searchTSVector(ctx, userID, query, limit)
+	}
+	return r.searchFTS5(ctx, userID, query, limit)
+}
+
+// searchFTS5 searches the SQLite FTS5 index and ranks the tasks with its bm25(). bm25()
+// is lower for better matches, so the rank is negated to be higher for them.
+func (r *SQLTaskRepository) searchFTS5(ctx context.Context, userID int, query string, limit int) ([]models.SearchResult, error) {
+	match, err := matchQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.executor().QueryContext(ctx, `
+		SELECT `+taskColumns+`,
+			snippet(task_search, -1, ?, ?, '…', ?),
+			-bm25(task_search, `+searchColumnWeights+`) AS rank
+		FROM task_search
+		JOIN tasks ON tasks.id = task_search.rowid
+		WHERE task_search MATCH ? AND tasks.user_id = ? AND tasks.deleted_at IS NULL
+		ORDER BY rank DESC, tasks.id DESC
+		LIMIT ?
+	`, models.SnippetMatchStart, models.SnippetMatchEnd, searchSnippetTokens, match, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var result models.SearchResult
+		result.Task, err = scanTask(extraScanner{rows, []interface{}{&result.Snippet, &result.Rank}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan found task: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return results, nil
+}
+
+// searchTSVector searches the PostgreSQL tsvector index. The snippet is taken from the first
+// column that matches on its own: the description, the processed description or the discussions.
+func (r *SQLTaskRepository) searchTSVector(ctx context.Context, userID int, query string, limit int) ([]models.SearchResult, error) {
+	match, err := tsQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	headline := fmt.Sprintf(`StartSel="%s", StopSel="%s", MaxWords=%d, MinWords=%d`,
+		models.SnippetMatchStart, models.SnippetMatchEnd, searchHeadlineWords, searchHeadlineWords/2)
+
+	rows, err := r.executor().QueryContext(ctx, `
+		SELECT `+taskColumns+`,
+			ts_headline('unaccented',
+				CASE
+					WHEN to_tsvector('unaccented', task_search.description) @@ search_query THEN task_search.description
+					WHEN to_tsvector('unaccented', task_search.processed) @@ search_query THEN task_search.processed
+					ELSE task_search.discussions
+				END, search_query, ?),
+			ts_rank(task_search.document, search_query) AS rank
+		FROM task_search
+		JOIN tasks ON tasks.id = task_search.task_id
+		CROSS JOIN to_tsquery('unaccented', ?) AS search_query
+		WHERE task_search.document @@ search_query AND tasks.user_id = ? AND tasks.deleted_at IS NULL
+		ORDER BY rank DESC, tasks.id DESC
+		LIMIT ?
+	`, headline, match, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var results []models.SearchResult
+	for rows.Next() {
+		var result models.SearchResult
+		result.Task, err = scanTask(extraScanner{rows, []interface{}{&result.Snippet, &result.Rank}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan found task: %w", err)
+		}
+		results = append(results, result)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return results, nil
+}
+
+// extraScanner scans taskColumns followed by extra columns of a query
+type extraScanner struct {
+	row   rowScanner
+	extra []interface{}
+}
+
+func (s extraScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// searchTerm is a word or a phrase of a search query. The last word of a prefix term
+// also matches the words it starts.
+type searchTerm struct {
+	words  []string
+	prefix bool
+}
+
+// parseSearchQuery splits a user query into terms. Words in double quotes are searched as
+// a phrase, other words are matched as prefixes (see searchPrefixMinLength) or explicitly
+// with a trailing "*". Query operators are not passed through, so any user input gives
+// a valid expression.
+func parseSearchQuery(query string) ([]searchTerm, error) {
+	var terms []searchTerm
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			words := searchWords(part)
+			if len(words) > 0 {
+				terms = append(terms, searchTerm{words: words, prefix: strings.HasSuffix(strings.TrimSpace(part), "*")})
+			}
+			continue
+		}
+
+		for _, field := range strings.Fields(part) {
+			words := searchWords(field)
+			if len(words) == 0 {
+				continue
+			}
+
+			prefix := strings.HasSuffix(field, "*") || utf8.RuneCountInString(words[len(words)-1]) >= searchPrefixMinLength
+			terms = append(terms, searchTerm{words: words, prefix: prefix})
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, ErrEmptySearchQuery
+	}
+
+	return terms, nil
+}
+
+// matchQuery turns a user query into an FTS5 MATCH expression, in which all terms must match.
+// Every term is quoted, so its words are never read as FTS5 operators or column names.
+func matchQuery(query string) (string, error) {
+	terms, err := parseSearchQuery(query)
+	if err != nil {
+		return "", err
+	}
+
+	matches := make([]string, len(terms))
+	for i, term := range terms {
+		match := `"` + strings.Join(term.words, " ") + `"`
+		if term.prefix {
+			match += "*"
+		}
+		matches[i] = match
+	}
+
+	return strings.Join(matches, " "), nil
+}
+
+// tsQuery turns a user query into a PostgreSQL tsquery, in which all terms must match
+func tsQuery(query string) (string, error) {
+	terms, err := parseSearchQuery(query)
+	if err != nil {
+		return "", err
+	}
+
+	matches := make([]string, len(terms))
+	for i, term := range terms {
+		match := strings.Join(term.words, " <-> ")
+		if term.prefix {
+			match += ":*"
+		}
+		if len(term.words) > 1 {
+			match = "(" + match + ")"
+		}
+		matches[i] = match
+	}
+
+	return strings.Join(matches, " & "), nil
+}
+
+// searchWords splits text into lower case words the way the unicode61 tokenizer does.
+// Lower case also keeps words like "OR" and "NOT" from being read as operators.
+func searchWords(text string) []string {
+	return strings.FieldsFunc(foldYo(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsMark(r)
+	})
+}
+
+// foldYo replaces "ё" with "е" like the task_search triggers do: unicode61 removes
+// diacritics from Latin letters only
+func foldYo(text string) string {
+	return strings.NewReplacer("ё", "е", "Ё", "Е").Replace(text)
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"testing"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchIDs(t *testing.T, repo TaskRepository, userID int, query string) []int {
+	results, err := repo.SearchTasks(t.Context(), userID, query, 10)
+	require.NoError(t, err)
+
+	ids := make([]int, 0, len(results))
+	for _, result := range results {
+		ids = append(ids, result.Task.ID)
+	}
+	return ids
+}
+
+func TestSearchTasks(t *testing.T) {
+	t.Run("index follows tasks and discussions", func(t *testing.T) {
+		db, repo := setupTestDB(t)
+		discussions := NewDiscussionRepository(db)
+
+		task := addTaggedTask(t, repo, 123, "Подготовить квартальный отчет")
+		assert.Equal(t, []int{task.ID}, searchIDs(t, repo, 123, "квартальный"))
+
+		task.OriginalDescription = "Позвонить бухгалтеру"
+		require.NoError(t, repo.UpdateTask(t.Context(), task))
+		assert.Empty(t, searchIDs(t, repo, 123, "квартальный"))
+		assert.Equal(t, []int{task.ID}, searchIDs(t, repo, 123, "бухгалтеру"))
+
+		task.LLMProcessedDesc = "Обсудить налоговую декларацию"
+		require.NoError(t, repo.UpdateTask(t.Context(), task))
+		assert.Equal(t, []int{task.ID}, searchIDs(t, repo, 123, "декларацию"))
+
+		discussion := &models.Discussion{TaskID: task.ID, MessageID: 1, Text: "Номер телефона в подписи письма"}
+		require.NoError(t, discussions.AddDiscussion(t.Context(), discussion))
+		assert.Equal(t, []int{task.ID}, searchIDs(t, repo, 123, "телефона"))
+
+		require.NoError(t, discussions.DeleteDiscussion(t.Context(), discussion.ID))
+		assert.Empty(t, searchIDs(t, repo, 123, "телефона"))
+
+		require.NoError(t, repo.DeleteTask(t.Context(), task.ID))
+		assert.Empty(t, searchIDs(t, repo, 123, "бухгалтеру"))
+	})
+
+	t.Run("query syntax", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		report := addTaggedTask(t, repo, 123, "Квартальный отчёт для банка")
+		other := addTaggedTask(t, repo, 123, "Отчет о поездке, квартальный бюджет")
+		addTaggedTask(t, repo, 456, "Квартальный отчет другого пользователя")
+		cafe := addTaggedTask(t, repo, 123, "Встреча в Café Müller")
+
+		// Words are matched as prefixes, in any form and with ё read as е
+		assert.ElementsMatch(t, []int{report.ID, other.ID}, searchIDs(t, repo, 123, "отчет"))
+		assert.ElementsMatch(t, []int{report.ID, other.ID}, searchIDs(t, repo, 123, "КВАРТАЛ"))
+		assert.Equal(t, []int{report.ID}, searchIDs(t, repo, 123, "банк*"))
+		assert.Equal(t, []int{cafe.ID}, searchIDs(t, repo, 123, "cafe muller"))
+
+		// Phrases keep the word order, all terms must match
+		assert.Equal(t, []int{report.ID}, searchIDs(t, repo, 123, `"квартальный отчет"`))
+		assert.Equal(t, []int{other.ID}, searchIDs(t, repo, 123, "отчет бюджет"))
+
+		// Operators and stray quotes are not an error
+		assert.Empty(t, searchIDs(t, repo, 123, `NOT отчет OR "банк`))
+	})
+
+	t.Run("ranking and snippets", func(t *testing.T) {
+		db, repo := setupTestDB(t)
+		once := addTaggedTask(t, repo, 123, "Купить молоко, хлеб, сыр, яйца, масло, муку и сахар к празднику")
+		twice := addTaggedTask(t, repo, 123, "Молоко: проверить, осталось ли молоко")
+		inDiscussion := addTaggedTask(t, repo, 123, "Список покупок")
+		require.NoError(t, NewDiscussionRepository(db).AddDiscussion(t.Context(), &models.Discussion{
+			TaskID: inDiscussion.ID, MessageID: 1, Text: "не забыть молоко",
+		}))
+
+		results, err := repo.SearchTasks(t.Context(), 123, "молоко", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, twice.ID, results[0].Task.ID)
+		assert.Equal(t, once.ID, results[1].Task.ID)
+		assert.Equal(t, inDiscussion.ID, results[2].Task.ID)
+		assert.Greater(t, results[0].Rank, results[1].Rank)
+
+		assert.Contains(t, results[0].Snippet, "[Молоко]")
+		assert.Equal(t, "не забыть [молоко]", results[2].Snippet)
+	})
+
+	t.Run("limit keeps the most relevant", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		addTaggedTask(t, repo, 123, "Купить молоко, хлеб, сыр, яйца, масло, муку и сахар к празднику")
+		twice := addTaggedTask(t, repo, 123, "Молоко: проверить, осталось ли молоко")
+		addTaggedTask(t, repo, 123, "Отнести молоко соседке")
+
+		results, err := repo.SearchTasks(t.Context(), 123, "молоко", 1)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, twice.ID, results[0].Task.ID)
+	})
+
+	t.Run("empty query", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+
+		_, err := repo.SearchTasks(t.Context(), 123, ` "" * - `, 10)
+		assert.ErrorIs(t, err, ErrEmptySearchQuery)
+	})
+}
+
+func TestMatchQuery(t *testing.T) {
+	testCases := []struct {
+		query    string
+		expected string
+		tsQuery  string
+	}{
+		{query: "Отчет", expected: `"отчет"*`, tsQuery: "отчет:*"},
+		{query: "в банк", expected: `"в" "банк"*`, tsQuery: "в & банк:*"},
+		{query: "от*", expected: `"от"*`, tsQuery: "от:*"},
+		{query: `"Квартальный отчет" банк`, expected: `"квартальный отчет" "банк"*`, tsQuery: "(квартальный <-> отчет) & банк:*"},
+		{query: `"квартальный отч*"`, expected: `"квартальный отч"*`, tsQuery: "(квартальный <-> отч:*)"},
+		{query: "e-mail", expected: `"e mail"*`, tsQuery: "(e <-> mail:*)"},
+		{query: "NOT OR", expected: `"not"* "or"`, tsQuery: "not:* & or"},
+		{query: "!отчет & (банк | 'x')", expected: `"отчет"* "банк"* "x"`, tsQuery: "отчет:* & банк:* & x"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			match, err := matchQuery(tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, match)
+
+			query, err := tsQuery(tc.query)
+			require.NoError(t, err)
+			assert.Equal(t, tc.tsQuery, query)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// sqliteTimeFormat is the canonical form of timestamps in SQLite: UTC with second precision,
+// the same text CURRENT_TIMESTAMP and datetime() produce. Timestamps in one form compare
+// chronologically as strings, so queries need no conversion functions.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
+// openSQLite opens a pool of SQLite connections for database/sql
+func openSQLite(dsn string) *sql.DB {
+	return sql.OpenDB(sqliteConnector{dsn: dsn})
+}
+
+// ErrNoFTS5 is returned when go-sqlite3 is built without the FTS5 full-text index
+var ErrNoFTS5 = errors.New("SQLite is built without FTS5, build with -tags sqlite_fts5")
+
+// checkSQLiteFTS5 checks that the SQLite library has FTS5, which the task_search index needs
+func checkSQLiteFTS5(db *sql.DB) error {
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return fmt.Errorf("failed to check SQLite compile options: %w", err)
+	}
+	if !enabled {
+		return ErrNoFTS5
+	}
+	return nil
+}
+
+// sqliteConnector opens connections that store time.Time parameters in the canonical form.
+// The driver itself would keep the offset and the fractions of a second of every value.
+type sqliteConnector struct {
+	dsn string
+}
+
+func (c sqliteConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Driver().Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+func (c sqliteConnector) Driver() driver.Driver {
+	return &sqlite3.SQLiteDriver{}
+}
+
+// sqliteConn formats the timestamps of every query before the driver sees them
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+// CheckNamedValue implements driver.NamedValueChecker; values other than time.Time are left
+// to the default conversion. Times that would not be read back are refused.
+func (c *sqliteConn) CheckNamedValue(value *driver.NamedValue) error {
+	if t, ok := value.Value.(time.Time); ok {
+		if !models.IsStorableTime(t) {
+			return fmt.Errorf("%w: %s is outside years %d-%d", ErrInvalidTimestamp, t, models.MinTimeYear, models.MaxTimeYear)
+		}
+		value.Value = formatSQLiteTime(t)
+		return nil
+	}
+	return driver.ErrSkip
+}
+
+func formatSQLiteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeFormat)
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// ErrTagNotFound is returned when the user has no tag with the requested name
+var ErrTagNotFound = errors.New("tag not found")
+
+// saveTaskTags replaces the tags of a saved task. Tags are created per user on first
+// use, and tags that no task uses any more are removed.
+func saveTaskTags(ctx context.Context, db dbExecutor, task *models.Task) error {
+	if _, err := db.ExecContext(ctx, "DELETE FROM task_tags WHERE task_id = ?", task.ID); err != nil {
+		return fmt.Errorf("failed to clear task tags: %w", err)
+	}
+
+	for _, tag := range task.Tags {
+		if _, err := db.ExecContext(ctx, "INSERT INTO tags (user_id, name) VALUES (?, ?) ON CONFLICT (user_id, name) DO NOTHING", task.UserID, tag); err != nil {
+			return fmt.Errorf("failed to create tag %s: %w", tag, err)
+		}
+
+		_, err := db.ExecContext(ctx,
+			"INSERT INTO task_tags (task_id, tag_id) SELECT CAST(? AS BIGINT), id FROM tags WHERE user_id = ? AND name = ?",
+			task.ID, task.UserID, tag,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to add tag %s to task: %w", tag, err)
+		}
+	}
+
+	if err := deleteUnusedTags(ctx, db, task.UserID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// deleteUnusedTags removes the tags of the user that no task is marked with
+func deleteUnusedTags(ctx context.Context, db dbExecutor, userID int) error {
+	_, err := db.ExecContext(ctx,
+		"DELETE FROM tags WHERE user_id = ? AND NOT EXISTS (SELECT 1 FROM task_tags WHERE task_tags.tag_id = tags.id)",
+		userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete unused tags: %w", err)
+	}
+	return nil
+}
+
+// GetTasksByTag retrieves tasks of a user marked with the tag, the most important first
+func (r *SQLTaskRepository) GetTasksByTag(ctx context.Context, userID int, tag string) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE user_id = ? AND deleted_at IS NULL AND id IN (
+			SELECT task_tags.task_id FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+			WHERE tags.user_id = ? AND tags.name = ?
+		)
+		ORDER BY
+			CASE WHEN priority = 0 THEN 5 ELSE priority END ASC,
+			CASE
+				WHEN deadline IS NOT NULL THEN deadline
+				ELSE created_at
+			END ASC
+	`
+
+	return r.queryTasks(ctx, query, userID, userID, tag)
+}
+
+// ListTags retrieves the tags of a user with the number of all and of active tasks
+func (r *SQLTaskRepository) ListTags(ctx context.Context, userID int) ([]models.TagCount, error) {
+	query := `
+		SELECT tags.name, COUNT(*), COALESCE(SUM(CASE WHEN tasks.status = ? THEN 1 ELSE 0 END), 0)
+		FROM tags
+		JOIN task_tags ON task_tags.tag_id = tags.id
+		JOIN tasks ON tasks.id = task_tags.task_id
+		WHERE tags.user_id = ? AND tasks.deleted_at IS NULL
+		GROUP BY tags.id
+		ORDER BY COUNT(*) DESC, tags.name ASC
+	`
+
+	rows, err := r.executor().QueryContext(ctx, query, models.StatusActive, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []models.TagCount
+	for rows.Next() {
+		var tag models.TagCount
+		if err := rows.Scan(&tag.Name, &tag.Tasks, &tag.Active); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return tags, nil
+}
+
+// RenameTag renames the tag of a user or merges it into an existing one in a transaction
+func (r *SQLTaskRepository) RenameTag(ctx context.Context, userID int, from, to string) (bool, error) {
+	var merged bool
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		fromID, err := tagID(ctx, tx, userID, from)
+		if err != nil {
+			return err
+		}
+
+		toID, err := tagID(ctx, tx, userID, to)
+		merged = err == nil
+		switch {
+		case fromID == toID:
+			merged = false
+			return nil
+		case merged:
+			_, err = tx.ExecContext(ctx,
+				`INSERT INTO task_tags (task_id, tag_id) SELECT task_id, CAST(? AS BIGINT) FROM task_tags WHERE tag_id = ?
+				ON CONFLICT (task_id, tag_id) DO NOTHING`,
+				toID, fromID,
+			)
+			if err == nil {
+				_, err = tx.ExecContext(ctx, "DELETE FROM tags WHERE id = ?", fromID)
+			}
+		case errors.Is(err, ErrTagNotFound):
+			_, err = tx.ExecContext(ctx, "UPDATE tags SET name = ? WHERE id = ?", to, fromID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to rename tag %s: %w", from, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return merged, nil
+}
+
+// tagID returns the ID of the tag of a user
+func tagID(ctx context.Context, tx *sql.Tx, userID int, name string) (int, error) {
+	var id int
+	err := tx.QueryRowContext(ctx, "SELECT id FROM tags WHERE user_id = ? AND name = ?", userID, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w: %s", ErrTagNotFound, name)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get tag %s: %w", name, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"testing"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func addTaggedTask(t *testing.T, repo TaskRepository, userID int, description string, tags ...string) *models.Task {
+	task := createTestTask(userID)
+	task.OriginalDescription = description
+	task.Tags = tags
+	require.NoError(t, repo.AddTask(t.Context(), task))
+	return task
+}
+
+func TestTagRepository(t *testing.T) {
+	t.Run("tags are saved and loaded with the task", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		task := addTaggedTask(t, repo, 123, "Report", "home", "work")
+
+		saved, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"home", "work"}, saved.Tags)
+
+		saved.Tags = []string{"work"}
+		require.NoError(t, repo.UpdateTask(t.Context(), saved))
+
+		saved, err = repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"work"}, saved.Tags)
+
+		// The tag no task uses any more is removed
+		tags, err := repo.ListTags(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []models.TagCount{{Name: "work", Tasks: 1, Active: 1}}, tags)
+	})
+
+	t.Run("tasks by tag", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		report := addTaggedTask(t, repo, 123, "Report", "work")
+		urgent := addTaggedTask(t, repo, 123, "Call", "work")
+		urgent.Priority = models.PriorityUrgent
+		require.NoError(t, repo.UpdateTask(t.Context(), urgent))
+		addTaggedTask(t, repo, 123, "Dishes", "home")
+		addTaggedTask(t, repo, 456, "Other user", "work")
+
+		tasks, err := repo.GetTasksByTag(t.Context(), 123, "work")
+		require.NoError(t, err)
+		require.Len(t, tasks, 2)
+		assert.Equal(t, urgent.ID, tasks[0].ID)
+		assert.Equal(t, report.ID, tasks[1].ID)
+
+		tasks, err = repo.GetTasksByTag(t.Context(), 123, "missing")
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("tag counts", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		addTaggedTask(t, repo, 123, "Report", "work")
+		addTaggedTask(t, repo, 123, "Call", "work", "phone")
+		done := addTaggedTask(t, repo, 123, "Slides", "work")
+		_, err := repo.CompleteTask(t.Context(), done)
+		require.NoError(t, err)
+		addTaggedTask(t, repo, 456, "Other user", "gym")
+
+		tags, err := repo.ListTags(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []models.TagCount{
+			{Name: "work", Tasks: 3, Active: 2},
+			{Name: "phone", Tasks: 1, Active: 1},
+		}, tags)
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		task := addTaggedTask(t, repo, 123, "Report", "wrk")
+		addTaggedTask(t, repo, 456, "Other user", "wrk")
+
+		merged, err := repo.RenameTag(t.Context(), 123, "wrk", "work")
+		require.NoError(t, err)
+		assert.False(t, merged)
+
+		saved, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"work"}, saved.Tags)
+
+		// Tags of other users are not touched
+		tasks, err := repo.GetTasksByTag(t.Context(), 456, "wrk")
+		require.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		both := addTaggedTask(t, repo, 123, "Report", "job", "work")
+		job := addTaggedTask(t, repo, 123, "Call", "job")
+
+		merged, err := repo.RenameTag(t.Context(), 123, "job", "work")
+		require.NoError(t, err)
+		assert.True(t, merged)
+
+		for _, id := range []int{both.ID, job.ID} {
+			saved, err := repo.GetTask(t.Context(), id)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"work"}, saved.Tags)
+		}
+
+		tags, err := repo.ListTags(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []models.TagCount{{Name: "work", Tasks: 2, Active: 2}}, tags)
+	})
+
+	t.Run("unknown tag", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		addTaggedTask(t, repo, 456, "Other user", "work")
+
+		_, err := repo.RenameTag(t.Context(), 123, "work", "job")
+		assert.ErrorIs(t, err, ErrTagNotFound)
+	})
+
+	t.Run("deleting a task removes its tags", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		task := addTaggedTask(t, repo, 123, "Report", "work")
+		require.NoError(t, repo.DeleteTask(t.Context(), task.ID))
+
+		tasks, err := repo.GetTasksByTag(t.Context(), 123, "work")
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+}
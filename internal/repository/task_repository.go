@@ -1,154 +1,247 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"telegram-bot-assistente/internal/models"
 )
 
-// TaskRepository defines the interface for task operations
+// ErrTaskNotFound is returned when a task with the requested ID does not exist
+var ErrTaskNotFound = errors.New("task not found")
+
+// TaskRepository defines the interface for task operations. Every method takes the context
+// of the request it serves, so that a cancelled request stops its queries.
 type TaskRepository interface {
-	AddTask(task *models.Task) error
-	GetTask(id int) (*models.Task, error)
-	UpdateTask(task *models.Task) error
-	DeleteTask(id int) error
-	GetTasksByUser(userID int) ([]*models.Task, error)
-	GetActiveTasks(userID int) ([]*models.Task, error)
-	GetTasksByStatus(userID int, status string) ([]*models.Task, error)
-	GetOverdueTasks(userID int) ([]*models.Task, error)
+	AddTask(ctx context.Context, task *models.Task) error
+	GetTask(ctx context.Context, id int) (*models.Task, error)
+	UpdateTask(ctx context.Context, task *models.Task) error
+	// CompleteTask marks the task as done and, for a recurring task, adds the next occurrence
+	// of its series. next is nil for one-off tasks and when the series has ended.
+	CompleteTask(ctx context.Context, task *models.Task) (next *models.Task, err error)
+	DeleteTask(ctx context.Context, id int) error
+	// TrashTask moves the task to the trash, where no other query sees it. It returns
+	// ErrTaskNotFound if the task does not exist or is already in the trash.
+	TrashTask(ctx context.Context, id int) error
+	// RestoreTask takes the task out of the trash. It returns ErrTaskNotFound if the task
+	// is not in the trash.
+	RestoreTask(ctx context.Context, id int) error
+	// GetTrashedTasks returns the tasks of the user in the trash, the most recently deleted first
+	GetTrashedTasks(ctx context.Context, userID int) ([]*models.Task, error)
+	// PurgeTrash permanently deletes the tasks of all users moved to the trash before the
+	// given time and returns their number
+	PurgeTrash(ctx context.Context, before time.Time) (int, error)
+	GetTasksByUser(ctx context.Context, userID int) ([]*models.Task, error)
+	GetActiveTasks(ctx context.Context, userID int) ([]*models.Task, error)
+	GetTasksByStatus(ctx context.Context, userID int, status string) ([]*models.Task, error)
+	GetOverdueTasks(ctx context.Context, userID int) ([]*models.Task, error)
+	AddTaskChanges(ctx context.Context, changes []*models.TaskChange) error
+	GetTaskHistory(ctx context.Context, taskID int) ([]*models.TaskChange, error)
+	// GetTasksByTag returns tasks of the user marked with the normalized tag, ordered like GetActiveTasks
+	GetTasksByTag(ctx context.Context, userID int, tag string) ([]*models.Task, error)
+	// ListTags returns the tags of the user with the number of tasks, the most used first
+	ListTags(ctx context.Context, userID int) ([]models.TagCount, error)
+	// RenameTag renames a tag of the user. If the user already has the new tag, the tags
+	// are merged and merged is true. It returns ErrTagNotFound if the user has no such tag.
+	RenameTag(ctx context.Context, userID int, from, to string) (merged bool, err error)
+	// SearchTasks finds at most limit tasks of the user whose descriptions or discussions match
+	// the query, the most relevant first. It returns ErrEmptySearchQuery if the query has no words.
+	SearchTasks(ctx context.Context, userID int, query string, limit int) ([]models.SearchResult, error)
+	// WithTx runs fn in a transaction: the repository passed to fn works inside it, and all of
+	// its changes are committed if fn returns nil and rolled back otherwise. Calls nested in
+	// fn join the same transaction.
+	WithTx(ctx context.Context, fn func(repo TaskRepository) error) error
 }
 
-// SqliteTaskRepository implements TaskRepository for SQLite database
-type SqliteTaskRepository struct {
-	db *sql.DB
+// SQLTaskRepository implements TaskRepository for SQLite and PostgreSQL databases
+type SQLTaskRepository struct {
+	db      *sql.DB
+	tx      *sql.Tx // Transaction of the repository passed to a WithTx callback
+	dialect dialect
 }
 
 // NewTaskRepository creates a new task repository instance
 func NewTaskRepository(database *Database) TaskRepository {
-	return &SqliteTaskRepository{
-		db: database.GetDB(),
+	return &SQLTaskRepository{
+		db:      database.GetDB(),
+		dialect: database.dialect,
 	}
 }
 
-// AddTask adds a new task to the database
-func (r *SqliteTaskRepository) AddTask(task *models.Task) error {
+// taskColumns lists the columns read by scanTask, in order. Tags are read with the task
+// as a space-separated list. Queries exclude tasks in the trash with "deleted_at IS NULL".
+const taskColumns = `id, user_id, original_description, llm_processed_desc, deadline, deadline_has_time, status,
+		priority, postponed_until, recurrence, series_id, created_at, updated_at, deleted_at,
+		(SELECT string_agg(tags.name, ' ') FROM task_tags JOIN tags ON tags.id = task_tags.tag_id
+			WHERE task_tags.task_id = tasks.id) AS tags`
+
+// dbExecutor is implemented by both *sql.DB and *sql.Tx
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// executor returns the transaction of the repository, if there is one, or the database
+func (r *SQLTaskRepository) executor() dbExecutor {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// inTx runs fn in the transaction of the repository or, outside of WithTx, in a new one
+func (r *SQLTaskRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	if r.tx != nil {
+		return fn(r.tx)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// WithTx runs fn with a repository bound to one transaction
+func (r *SQLTaskRepository) WithTx(ctx context.Context, fn func(repo TaskRepository) error) error {
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return fn(&SQLTaskRepository{db: r.db, tx: tx, dialect: r.dialect})
+	})
+}
+
+// AddTask adds a new task to the database. The first task of a series is linked to itself
+// and tags are linked to the task in the same transaction.
+func (r *SQLTaskRepository) AddTask(ctx context.Context, task *models.Task) error {
 	if err := task.Validate(); err != nil {
 		return fmt.Errorf("task validation failed: %w", err)
 	}
 
 	task.SetDefaults()
 
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return insertTask(ctx, tx, task)
+	})
+}
+
+// insertTask inserts a validated task. A recurring task without a series starts its own.
+func insertTask(ctx context.Context, db dbExecutor, task *models.Task) error {
 	query := `
-		INSERT INTO tasks (user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO tasks (user_id, original_description, llm_processed_desc, deadline, deadline_has_time, status,
+			priority, postponed_until, recurrence, series_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING id
 	`
 
 	var deadline interface{}
 	if task.HasDeadline() {
-		deadline = task.Deadline.Format(time.RFC3339)
+		deadline = task.Deadline
 	}
 
-	result, err := r.db.Exec(query,
+	err := db.QueryRowContext(ctx, query,
 		task.UserID,
 		task.OriginalDescription,
 		task.LLMProcessedDesc,
 		deadline,
+		task.HasDeadline() && task.DeadlineHasTime,
 		task.Status,
-		task.CreatedAt.Format(time.RFC3339),
-		task.UpdatedAt.Format(time.RFC3339),
-	)
+		task.Priority,
+		postponedUntil(task),
+		nullString(task.Recurrence),
+		nullInt(task.SeriesID),
+		task.CreatedAt,
+		task.UpdatedAt,
+	).Scan(&task.ID)
 	if err != nil {
 		return fmt.Errorf("failed to insert task: %w", err)
 	}
 
-	id, err := result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %w", err)
+	if task.IsRecurring() && task.SeriesID == 0 {
+		if _, err := db.ExecContext(ctx, "UPDATE tasks SET series_id = id WHERE id = ?", task.ID); err != nil {
+			return fmt.Errorf("failed to start task series: %w", err)
+		}
+		task.SeriesID = task.ID
 	}
 
-	task.ID = int(id)
-	return nil
+	return saveTaskTags(ctx, db, task)
 }
 
 // GetTask retrieves a task by ID
-func (r *SqliteTaskRepository) GetTask(id int) (*models.Task, error) {
+func (r *SQLTaskRepository) GetTask(ctx context.Context, id int) (*models.Task, error) {
+	return getTask(ctx, r.executor(), id)
+}
+
+// getTask retrieves a task by ID within a transaction or outside of it; tasks in the trash are not found
+func getTask(ctx context.Context, db dbExecutor, id int) (*models.Task, error) {
 	query := `
-		SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE id = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
-	task := &models.Task{}
-	var deadline sql.NullString
-	var llmProcessedDesc sql.NullString
-	var createdAt, updatedAt string
-
-	err := r.db.QueryRow(query, id).Scan(
-		&task.ID,
-		&task.UserID,
-		&task.OriginalDescription,
-		&llmProcessedDesc,
-		&deadline,
-		&task.Status,
-		&createdAt,
-		&updatedAt,
-	)
+	task, err := scanTask(db.QueryRowContext(ctx, query, id))
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("task with id %d not found", id)
+			return nil, fmt.Errorf("%w: id %d", ErrTaskNotFound, id)
 		}
 		return nil, fmt.Errorf("failed to get task: %w", err)
 	}
 
-	// Parse optional fields
-	if llmProcessedDesc.Valid {
-		task.LLMProcessedDesc = llmProcessedDesc.String
-	}
-
-	if deadline.Valid {
-		if parsedDeadline, err := time.Parse(time.RFC3339, deadline.String); err == nil {
-			task.Deadline = parsedDeadline
-		}
-	}
-
-	if parsedCreatedAt, err := time.Parse(time.RFC3339, createdAt); err == nil {
-		task.CreatedAt = parsedCreatedAt
-	}
-
-	if parsedUpdatedAt, err := time.Parse(time.RFC3339, updatedAt); err == nil {
-		task.UpdatedAt = parsedUpdatedAt
-	}
-
 	return task, nil
 }
 
-// UpdateTask updates an existing task
-func (r *SqliteTaskRepository) UpdateTask(task *models.Task) error {
+// UpdateTask updates an existing task together with its tags
+func (r *SQLTaskRepository) UpdateTask(ctx context.Context, task *models.Task) error {
 	if err := task.Validate(); err != nil {
 		return fmt.Errorf("task validation failed: %w", err)
 	}
 
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		return updateTask(ctx, tx, task)
+	})
+}
+
+// updateTask saves a validated task with its tags. The series of a task is kept as is.
+func updateTask(ctx context.Context, db dbExecutor, task *models.Task) error {
 	task.UpdatedAt = time.Now()
 
 	query := `
 		UPDATE tasks
-		SET original_description = ?, llm_processed_desc = ?, deadline = ?, status = ?, updated_at = ?
-		WHERE id = ?
+		SET original_description = ?, llm_processed_desc = ?, deadline = ?, deadline_has_time = ?, status = ?,
+			priority = ?, postponed_until = ?, recurrence = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
 	`
 
 	var deadline interface{}
 	if task.HasDeadline() {
-		deadline = task.Deadline.Format(time.RFC3339)
+		deadline = task.Deadline
 	}
 
-	result, err := r.db.Exec(query,
+	result, err := db.ExecContext(ctx, query,
 		task.OriginalDescription,
 		task.LLMProcessedDesc,
 		deadline,
+		task.HasDeadline() && task.DeadlineHasTime,
 		task.Status,
-		task.UpdatedAt.Format(time.RFC3339),
+		task.Priority,
+		postponedUntil(task),
+		nullString(task.Recurrence),
+		task.UpdatedAt,
 		task.ID,
 	)
 	if err != nil {
@@ -161,17 +254,61 @@ func (r *SqliteTaskRepository) UpdateTask(task *models.Task) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("task with id %d not found", task.ID)
+		return fmt.Errorf("%w: id %d", ErrTaskNotFound, task.ID)
 	}
 
-	return nil
+	return saveTaskTags(ctx, db, task)
+}
+
+// CompleteTask marks the task as done and adds the next occurrence of a recurring task.
+// The occurrence is counted in the owner's time zone, so that "every monday" stays on
+// the owner's Monday, and both changes are saved in one transaction.
+func (r *SQLTaskRepository) CompleteTask(ctx context.Context, task *models.Task) (*models.Task, error) {
+	if err := task.Validate(); err != nil {
+		return nil, fmt.Errorf("task validation failed: %w", err)
+	}
+
+	var next *models.Task
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		task.Status = models.StatusDone
+		if err := updateTask(ctx, tx, task); err != nil {
+			return err
+		}
+
+		if !task.IsRecurring() {
+			return nil
+		}
+
+		var timeZone sql.NullString
+		err := tx.QueryRowContext(ctx, "SELECT time_zone FROM users WHERE id = ?", task.UserID).Scan(&timeZone)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("failed to get time zone of user %d: %w", task.UserID, err)
+		}
+		owner := models.User{ID: task.UserID, TimeZone: timeZone.String}
+
+		next, err = task.NextOccurrence(owner.Location(), time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to get next occurrence: %w", err)
+		}
+		if next == nil {
+			return nil
+		}
+
+		next.SetDefaults()
+		return insertTask(ctx, tx, next)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return next, nil
 }
 
 // DeleteTask deletes a task by ID
-func (r *SqliteTaskRepository) DeleteTask(id int) error {
+func (r *SQLTaskRepository) DeleteTask(ctx context.Context, id int) error {
 	query := `DELETE FROM tasks WHERE id = ?`
 
-	result, err := r.db.Exec(query, id)
+	result, err := r.executor().ExecContext(ctx, query, id)
 	if err != nil {
 		return fmt.Errorf("failed to delete task: %w", err)
 	}
@@ -182,112 +319,170 @@ func (r *SqliteTaskRepository) DeleteTask(id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("task with id %d not found", id)
+		return fmt.Errorf("%w: id %d", ErrTaskNotFound, id)
 	}
 
 	return nil
 }
 
 // GetTasksByUser retrieves all tasks for a specific user
-func (r *SqliteTaskRepository) GetTasksByUser(userID int) ([]*models.Task, error) {
+func (r *SQLTaskRepository) GetTasksByUser(ctx context.Context, userID int) ([]*models.Task, error) {
 	query := `
-		SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE user_id = ?
+		WHERE user_id = ? AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	return r.queryTasks(query, userID)
+	return r.queryTasks(ctx, query, userID)
 }
 
-// GetActiveTasks retrieves all active tasks for a specific user
-func (r *SqliteTaskRepository) GetActiveTasks(userID int) ([]*models.Task, error) {
+// GetActiveTasks retrieves all active tasks for a specific user, the most important first.
+// Tasks of the same priority are ordered by deadline; tasks without a priority come last.
+func (r *SQLTaskRepository) GetActiveTasks(ctx context.Context, userID int) ([]*models.Task, error) {
 	query := `
-		SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE user_id = ? AND status = ?
+		WHERE user_id = ? AND status = ? AND deleted_at IS NULL
 		ORDER BY 
+			CASE WHEN priority = 0 THEN 5 ELSE priority END ASC,
 			CASE 
-				WHEN deadline IS NOT NULL THEN deadline 
-				ELSE created_at 
+				WHEN deadline IS NOT NULL THEN deadline
+				ELSE created_at
 			END ASC
 	`
 
-	return r.queryTasks(query, userID, models.StatusActive)
+	return r.queryTasks(ctx, query, userID, models.StatusActive)
 }
 
 // GetTasksByStatus retrieves tasks by status for a specific user
-func (r *SqliteTaskRepository) GetTasksByStatus(userID int, status string) ([]*models.Task, error) {
+func (r *SQLTaskRepository) GetTasksByStatus(ctx context.Context, userID int, status string) ([]*models.Task, error) {
 	query := `
-		SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE user_id = ? AND status = ?
+		WHERE user_id = ? AND status = ? AND deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
 
-	return r.queryTasks(query, userID, status)
+	return r.queryTasks(ctx, query, userID, status)
 }
 
 // GetOverdueTasks retrieves overdue tasks for a specific user
-func (r *SqliteTaskRepository) GetOverdueTasks(userID int) ([]*models.Task, error) {
+func (r *SQLTaskRepository) GetOverdueTasks(ctx context.Context, userID int) ([]*models.Task, error) {
 	query := `
-		SELECT id, user_id, original_description, llm_processed_desc, deadline, status, created_at, updated_at
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE user_id = ? AND status = ? AND deadline IS NOT NULL AND deadline < ?
+		WHERE user_id = ? AND status = ? AND deleted_at IS NULL AND deadline IS NOT NULL AND deadline < ?
 		ORDER BY deadline ASC
 	`
 
-	now := time.Now().Format(time.RFC3339)
-	return r.queryTasks(query, userID, models.StatusActive, now)
+	return r.queryTasks(ctx, query, userID, models.StatusActive, time.Now())
 }
 
-// queryTasks is a helper method to execute queries that return multiple tasks
-func (r *SqliteTaskRepository) queryTasks(query string, args ...interface{}) ([]*models.Task, error) {
-	rows, err := r.db.Query(query, args...)
+// AddTaskChanges records changes of a task in the history; either all of them or none are saved
+func (r *SQLTaskRepository) AddTaskChanges(ctx context.Context, changes []*models.TaskChange) error {
+	query := `
+		INSERT INTO task_history (task_id, user_id, field, old_value, new_value, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		RETURNING id
+	`
+
+	for _, change := range changes {
+		if err := change.Validate(); err != nil {
+			return fmt.Errorf("task change validation failed: %w", err)
+		}
+	}
+
+	return r.inTx(ctx, func(tx *sql.Tx) error {
+		for _, change := range changes {
+			change.SetDefaults()
+
+			err := tx.QueryRowContext(ctx, query,
+				change.TaskID,
+				change.UserID,
+				change.Field,
+				change.OldValue,
+				change.NewValue,
+				change.ChangedAt,
+			).Scan(&change.ID)
+			if err != nil {
+				return fmt.Errorf("failed to insert task change: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetTaskHistory retrieves the change history of a task in chronological order
+func (r *SQLTaskRepository) GetTaskHistory(ctx context.Context, taskID int) ([]*models.TaskChange, error) {
+	query := `
+		SELECT id, task_id, user_id, field, old_value, new_value, changed_at
+		FROM task_history
+		WHERE task_id = ?
+		ORDER BY changed_at ASC, id ASC
+	`
+
+	rows, err := r.executor().QueryContext(ctx, query, taskID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to execute query: %w", err)
 	}
 	defer rows.Close()
 
-	var tasks []*models.Task
+	var changes []*models.TaskChange
 
 	for rows.Next() {
-		task := &models.Task{}
-		var deadline sql.NullString
-		var llmProcessedDesc sql.NullString
-		var createdAt, updatedAt string
+		change := &models.TaskChange{}
+		var oldValue, newValue sql.NullString
 
 		err := rows.Scan(
-			&task.ID,
-			&task.UserID,
-			&task.OriginalDescription,
-			&llmProcessedDesc,
-			&deadline,
-			&task.Status,
-			&createdAt,
-			&updatedAt,
+			&change.ID,
+			&change.TaskID,
+			&change.UserID,
+			&change.Field,
+			&oldValue,
+			&newValue,
+			scanTime(&change.ChangedAt),
 		)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan task: %w", err)
+			return nil, fmt.Errorf("failed to scan task change: %w", err)
 		}
 
-		// Parse optional fields
-		if llmProcessedDesc.Valid {
-			task.LLMProcessedDesc = llmProcessedDesc.String
-		}
+		change.OldValue = oldValue.String
+		change.NewValue = newValue.String
 
-		if deadline.Valid {
-			if parsedDeadline, err := time.Parse(time.RFC3339, deadline.String); err == nil {
-				task.Deadline = parsedDeadline
-			}
-		}
+		changes = append(changes, change)
+	}
 
-		if parsedCreatedAt, err := time.Parse(time.RFC3339, createdAt); err == nil {
-			task.CreatedAt = parsedCreatedAt
-		}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", err)
+	}
+
+	return changes, nil
+}
+
+// postponedUntil returns the stored postponed_until: only postponed tasks keep it
+func postponedUntil(task *models.Task) interface{} {
+	if task.Status != models.StatusPostponed || task.PostponedUntil.IsZero() {
+		return nil
+	}
+	return task.PostponedUntil
+}
+
+// queryTasks is a helper method to execute queries that return multiple tasks
+func (r *SQLTaskRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*models.Task, error) {
+	rows, err := r.executor().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []*models.Task
 
-		if parsedUpdatedAt, err := time.Parse(time.RFC3339, updatedAt); err == nil {
-			task.UpdatedAt = parsedUpdatedAt
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan task: %w", err)
 		}
 
 		tasks = append(tasks, task)
@@ -299,3 +494,49 @@ func (r *SqliteTaskRepository) queryTasks(query string, args ...interface{}) ([]
 
 	return tasks, nil
 }
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTask reads a task selected with taskColumns
+func scanTask(row rowScanner) (*models.Task, error) {
+	task := &models.Task{}
+	var tags sql.NullString
+	var llmProcessedDesc sql.NullString
+	var recurrence sql.NullString
+	var seriesID sql.NullInt64
+
+	err := row.Scan(
+		&task.ID,
+		&task.UserID,
+		&task.OriginalDescription,
+		&llmProcessedDesc,
+		scanNullTime(&task.Deadline),
+		&task.DeadlineHasTime,
+		&task.Status,
+		&task.Priority,
+		scanNullTime(&task.PostponedUntil),
+		&recurrence,
+		&seriesID,
+		scanTime(&task.CreatedAt),
+		scanTime(&task.UpdatedAt),
+		scanNullTime(&task.DeletedAt),
+		&tags,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse optional fields
+	task.LLMProcessedDesc = llmProcessedDesc.String
+	task.Recurrence = recurrence.String
+	task.SeriesID = int(seriesID.Int64)
+	if tags.Valid {
+		task.Tags = strings.Fields(tags.String)
+		sort.Strings(task.Tags)
+	}
+
+	return task, nil
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -11,6 +13,14 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// skipWithoutFTS5 skips a test when go-sqlite3 is built without the sqlite_fts5 tag
+func skipWithoutFTS5(t *testing.T, err error) {
+	t.Helper()
+	if errors.Is(err, ErrNoFTS5) {
+		t.Skip(err)
+	}
+}
+
 func setupTestDB(t *testing.T) (*Database, TaskRepository) {
 	// Create temporary database file
 	dbPath := "test_tasks.db"
@@ -19,10 +29,18 @@ func setupTestDB(t *testing.T) (*Database, TaskRepository) {
 	os.Remove(dbPath)
 
 	db, err := NewDatabase(dbPath)
+	skipWithoutFTS5(t, err)
 	require.NoError(t, err)
 
 	repo := NewTaskRepository(db)
 
+	// Tasks reference users, so test users must exist
+	users := NewUserRepository(db)
+	for _, id := range []int{123, 456} {
+		_, err := users.UpsertUser(t.Context(), &models.User{ID: id, FirstName: "Test"})
+		require.NoError(t, err)
+	}
+
 	// Clean up function
 	t.Cleanup(func() {
 		db.Close()
@@ -47,7 +65,7 @@ func TestTaskRepository_AddTask(t *testing.T) {
 	t.Run("valid task", func(t *testing.T) {
 		task := createTestTask(123)
 
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		assert.NoError(t, err)
 		assert.NotZero(t, task.ID)
 		assert.NotZero(t, task.CreatedAt)
@@ -61,7 +79,7 @@ func TestTaskRepository_AddTask(t *testing.T) {
 			OriginalDescription: "",
 		}
 
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "original_description cannot be empty")
 	})
@@ -72,7 +90,7 @@ func TestTaskRepository_AddTask(t *testing.T) {
 			OriginalDescription: "Test task",
 		}
 
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "user_id must be a positive integer")
 	})
@@ -86,11 +104,11 @@ func TestTaskRepository_GetTask(t *testing.T) {
 		originalTask := createTestTask(123)
 		originalTask.LLMProcessedDesc = "Enhanced description"
 
-		err := repo.AddTask(originalTask)
+		err := repo.AddTask(t.Context(), originalTask)
 		require.NoError(t, err)
 
 		// Get the task
-		retrievedTask, err := repo.GetTask(originalTask.ID)
+		retrievedTask, err := repo.GetTask(t.Context(), originalTask.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, originalTask.ID, retrievedTask.ID)
 		assert.Equal(t, originalTask.UserID, retrievedTask.UserID)
@@ -100,13 +118,43 @@ func TestTaskRepository_GetTask(t *testing.T) {
 
 		// Check deadline (with tolerance for time precision)
 		assert.WithinDuration(t, originalTask.Deadline, retrievedTask.Deadline, time.Second)
+		assert.False(t, retrievedTask.DeadlineHasTime)
+	})
+
+	t.Run("deadline with time", func(t *testing.T) {
+		task := &models.Task{
+			UserID:              123,
+			OriginalDescription: "Team call",
+			Deadline:            time.Date(2025, 7, 15, 15, 30, 0, 0, time.Local),
+			DeadlineHasTime:     true,
+		}
+		require.NoError(t, repo.AddTask(t.Context(), task))
+
+		retrievedTask, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.True(t, retrievedTask.DeadlineHasTime)
+		assert.True(t, task.Deadline.Equal(retrievedTask.Deadline))
+
+		// Clearing the deadline also clears the flag
+		retrievedTask.Deadline = time.Time{}
+		require.NoError(t, repo.UpdateTask(t.Context(), retrievedTask))
+
+		tasks, err := repo.GetTasksByUser(t.Context(), 123)
+		require.NoError(t, err)
+		for _, listed := range tasks {
+			if listed.ID == task.ID {
+				assert.False(t, listed.HasDeadline())
+				assert.False(t, listed.DeadlineHasTime)
+			}
+		}
 	})
 
 	t.Run("non-existing task", func(t *testing.T) {
-		task, err := repo.GetTask(999)
+		task, err := repo.GetTask(t.Context(), 999)
 		assert.Error(t, err)
 		assert.Nil(t, task)
 		assert.Contains(t, err.Error(), "not found")
+		assert.ErrorIs(t, err, ErrTaskNotFound)
 	})
 }
 
@@ -116,7 +164,7 @@ func TestTaskRepository_UpdateTask(t *testing.T) {
 	t.Run("existing task", func(t *testing.T) {
 		// Add a task first
 		task := createTestTask(123)
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		require.NoError(t, err)
 
 		// Update the task
@@ -124,11 +172,11 @@ func TestTaskRepository_UpdateTask(t *testing.T) {
 		task.LLMProcessedDesc = "Updated enhanced description"
 		task.Status = models.StatusDone
 
-		err = repo.UpdateTask(task)
+		err = repo.UpdateTask(t.Context(), task)
 		assert.NoError(t, err)
 
 		// Verify the update
-		updatedTask, err := repo.GetTask(task.ID)
+		updatedTask, err := repo.GetTask(t.Context(), task.ID)
 		require.NoError(t, err)
 		assert.Equal(t, "Updated description", updatedTask.OriginalDescription)
 		assert.Equal(t, "Updated enhanced description", updatedTask.LLMProcessedDesc)
@@ -139,20 +187,20 @@ func TestTaskRepository_UpdateTask(t *testing.T) {
 		task := createTestTask(123)
 		task.ID = 999
 
-		err := repo.UpdateTask(task)
+		err := repo.UpdateTask(t.Context(), task)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 	})
 
 	t.Run("invalid task data", func(t *testing.T) {
 		task := createTestTask(123)
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		require.NoError(t, err)
 
 		// Make task invalid
 		task.OriginalDescription = ""
 
-		err = repo.UpdateTask(task)
+		err = repo.UpdateTask(t.Context(), task)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "validation failed")
 	})
@@ -164,21 +212,21 @@ func TestTaskRepository_DeleteTask(t *testing.T) {
 	t.Run("existing task", func(t *testing.T) {
 		// Add a task first
 		task := createTestTask(123)
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		require.NoError(t, err)
 
 		// Delete the task
-		err = repo.DeleteTask(task.ID)
+		err = repo.DeleteTask(t.Context(), task.ID)
 		assert.NoError(t, err)
 
 		// Verify deletion
-		_, err = repo.GetTask(task.ID)
+		_, err = repo.GetTask(t.Context(), task.ID)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 	})
 
 	t.Run("non-existing task", func(t *testing.T) {
-		err := repo.DeleteTask(999)
+		err := repo.DeleteTask(t.Context(), 999)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 	})
@@ -193,21 +241,21 @@ func TestTaskRepository_GetTasksByUser(t *testing.T) {
 	// Add tasks for different users
 	task1 := createTestTask(userID1)
 	task1.OriginalDescription = "Task 1"
-	err := repo.AddTask(task1)
+	err := repo.AddTask(t.Context(), task1)
 	require.NoError(t, err)
 
 	task2 := createTestTask(userID1)
 	task2.OriginalDescription = "Task 2"
-	err = repo.AddTask(task2)
+	err = repo.AddTask(t.Context(), task2)
 	require.NoError(t, err)
 
 	task3 := createTestTask(userID2)
 	task3.OriginalDescription = "Task 3"
-	err = repo.AddTask(task3)
+	err = repo.AddTask(t.Context(), task3)
 	require.NoError(t, err)
 
 	t.Run("user with tasks", func(t *testing.T) {
-		tasks, err := repo.GetTasksByUser(userID1)
+		tasks, err := repo.GetTasksByUser(t.Context(), userID1)
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 2)
 
@@ -216,7 +264,7 @@ func TestTaskRepository_GetTasksByUser(t *testing.T) {
 	})
 
 	t.Run("user without tasks", func(t *testing.T) {
-		tasks, err := repo.GetTasksByUser(999)
+		tasks, err := repo.GetTasksByUser(t.Context(), 999)
 		assert.NoError(t, err)
 		assert.Empty(t, tasks)
 	})
@@ -231,28 +279,62 @@ func TestTaskRepository_GetActiveTasks(t *testing.T) {
 	activeTask := createTestTask(userID)
 	activeTask.OriginalDescription = "Active task"
 	activeTask.Status = models.StatusActive
-	err := repo.AddTask(activeTask)
+	err := repo.AddTask(t.Context(), activeTask)
 	require.NoError(t, err)
 
 	doneTask := createTestTask(userID)
 	doneTask.OriginalDescription = "Done task"
 	doneTask.Status = models.StatusDone
-	err = repo.AddTask(doneTask)
+	err = repo.AddTask(t.Context(), doneTask)
 	require.NoError(t, err)
 
 	postponedTask := createTestTask(userID)
 	postponedTask.OriginalDescription = "Postponed task"
 	postponedTask.Status = models.StatusPostponed
-	err = repo.AddTask(postponedTask)
+	err = repo.AddTask(t.Context(), postponedTask)
 	require.NoError(t, err)
 
 	t.Run("get only active tasks", func(t *testing.T) {
-		tasks, err := repo.GetActiveTasks(userID)
+		tasks, err := repo.GetActiveTasks(t.Context(), userID)
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 1)
 		assert.Equal(t, models.StatusActive, tasks[0].Status)
 		assert.Equal(t, "Active task", tasks[0].OriginalDescription)
 	})
+
+	t.Run("ordered by priority, then by deadline", func(t *testing.T) {
+		otherUserID := 456
+		now := time.Now()
+		add := func(description string, priority int, deadline time.Time) {
+			task := createTestTask(otherUserID)
+			task.OriginalDescription = description
+			task.Priority = priority
+			task.Deadline = deadline
+			require.NoError(t, repo.AddTask(t.Context(), task))
+		}
+
+		add("No priority, soon", models.PriorityNone, now.Add(time.Hour))
+		add("Low", models.PriorityLow, now.Add(time.Hour))
+		add("High, later", models.PriorityHigh, now.Add(48*time.Hour))
+		add("Urgent", models.PriorityUrgent, now.Add(72*time.Hour))
+		add("High, sooner", models.PriorityHigh, now.Add(24*time.Hour))
+
+		tasks, err := repo.GetActiveTasks(t.Context(), otherUserID)
+		require.NoError(t, err)
+
+		var descriptions []string
+		for _, task := range tasks {
+			descriptions = append(descriptions, task.OriginalDescription)
+		}
+		assert.Equal(t, []string{"Urgent", "High, sooner", "High, later", "Low", "No priority, soon"}, descriptions)
+		assert.Equal(t, models.PriorityUrgent, tasks[0].Priority)
+	})
+
+	t.Run("invalid priority is rejected", func(t *testing.T) {
+		task := createTestTask(userID)
+		task.Priority = 5
+		assert.Error(t, repo.AddTask(t.Context(), task))
+	})
 }
 
 func TestTaskRepository_GetTasksByStatus(t *testing.T) {
@@ -263,30 +345,30 @@ func TestTaskRepository_GetTasksByStatus(t *testing.T) {
 	// Add tasks with different statuses
 	activeTask := createTestTask(userID)
 	activeTask.Status = models.StatusActive
-	err := repo.AddTask(activeTask)
+	err := repo.AddTask(t.Context(), activeTask)
 	require.NoError(t, err)
 
 	doneTask := createTestTask(userID)
 	doneTask.Status = models.StatusDone
-	err = repo.AddTask(doneTask)
+	err = repo.AddTask(t.Context(), doneTask)
 	require.NoError(t, err)
 
 	t.Run("get done tasks", func(t *testing.T) {
-		tasks, err := repo.GetTasksByStatus(userID, models.StatusDone)
+		tasks, err := repo.GetTasksByStatus(t.Context(), userID, models.StatusDone)
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 1)
 		assert.Equal(t, models.StatusDone, tasks[0].Status)
 	})
 
 	t.Run("get active tasks", func(t *testing.T) {
-		tasks, err := repo.GetTasksByStatus(userID, models.StatusActive)
+		tasks, err := repo.GetTasksByStatus(t.Context(), userID, models.StatusActive)
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 1)
 		assert.Equal(t, models.StatusActive, tasks[0].Status)
 	})
 
 	t.Run("get tasks with non-existing status", func(t *testing.T) {
-		tasks, err := repo.GetTasksByStatus(userID, "non-existing")
+		tasks, err := repo.GetTasksByStatus(t.Context(), userID, "non-existing")
 		assert.NoError(t, err)
 		assert.Empty(t, tasks)
 	})
@@ -302,7 +384,7 @@ func TestTaskRepository_GetOverdueTasks(t *testing.T) {
 	overdueTask.OriginalDescription = "Overdue task"
 	overdueTask.Deadline = time.Now().Add(-24 * time.Hour) // Yesterday
 	overdueTask.Status = models.StatusActive
-	err := repo.AddTask(overdueTask)
+	err := repo.AddTask(t.Context(), overdueTask)
 	require.NoError(t, err)
 
 	// Add future task
@@ -310,7 +392,7 @@ func TestTaskRepository_GetOverdueTasks(t *testing.T) {
 	futureTask.OriginalDescription = "Future task"
 	futureTask.Deadline = time.Now().Add(24 * time.Hour) // Tomorrow
 	futureTask.Status = models.StatusActive
-	err = repo.AddTask(futureTask)
+	err = repo.AddTask(t.Context(), futureTask)
 	require.NoError(t, err)
 
 	// Add done overdue task (should not be included)
@@ -318,11 +400,11 @@ func TestTaskRepository_GetOverdueTasks(t *testing.T) {
 	doneOverdueTask.OriginalDescription = "Done overdue task"
 	doneOverdueTask.Deadline = time.Now().Add(-48 * time.Hour) // Two days ago
 	doneOverdueTask.Status = models.StatusDone
-	err = repo.AddTask(doneOverdueTask)
+	err = repo.AddTask(t.Context(), doneOverdueTask)
 	require.NoError(t, err)
 
 	t.Run("get overdue tasks", func(t *testing.T) {
-		tasks, err := repo.GetOverdueTasks(userID)
+		tasks, err := repo.GetOverdueTasks(t.Context(), userID)
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 1)
 		assert.Equal(t, "Overdue task", tasks[0].OriginalDescription)
@@ -331,6 +413,104 @@ func TestTaskRepository_GetOverdueTasks(t *testing.T) {
 	})
 }
 
+func TestTaskRepository_DeadlineTimeZones(t *testing.T) {
+	db, repo := setupTestDB(t)
+	userID := 456
+
+	vladivostok := time.FixedZone("UTC+10", 10*60*60)
+	losAngeles := time.FixedZone("UTC-7", -7*60*60)
+
+	// An hour ago in a zone far ahead of UTC: its local date string is "later" than now in UTC
+	passed := createTestTask(userID)
+	passed.OriginalDescription = "Passed in Vladivostok"
+	passed.Deadline = time.Now().Add(-time.Hour).Truncate(time.Second).In(vladivostok)
+	require.NoError(t, repo.AddTask(t.Context(), passed))
+
+	// In an hour in a zone behind UTC: its local date string is "earlier" than now in UTC
+	upcoming := createTestTask(userID)
+	upcoming.OriginalDescription = "Upcoming in Los Angeles"
+	upcoming.Deadline = time.Now().Add(time.Hour).In(losAngeles)
+	require.NoError(t, repo.AddTask(t.Context(), upcoming))
+
+	t.Run("deadlines are stored in UTC", func(t *testing.T) {
+		var stored string
+		require.NoError(t, db.GetDB().QueryRow("SELECT CAST(deadline AS TEXT) FROM tasks WHERE id = ?", passed.ID).Scan(&stored))
+		assert.Equal(t, passed.Deadline.UTC().Format("2006-01-02 15:04:05"), stored)
+
+		task, err := repo.GetTask(t.Context(), passed.ID)
+		require.NoError(t, err)
+		assert.True(t, passed.Deadline.Equal(task.Deadline))
+	})
+
+	t.Run("overdue regardless of zone", func(t *testing.T) {
+		tasks, err := repo.GetOverdueTasks(t.Context(), userID)
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "Passed in Vladivostok", tasks[0].OriginalDescription)
+	})
+
+	t.Run("times outside years 1-9999 are refused", func(t *testing.T) {
+		task := createTestTask(userID)
+		require.NoError(t, repo.AddTask(t.Context(), task))
+
+		// Validate refuses such a task, the connection refuses the value itself
+		_, err := db.GetDB().Exec("UPDATE tasks SET deadline = ? WHERE id = ?", time.Date(12026, 1, 1, 0, 0, 0, 0, time.UTC), task.ID)
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+
+		require.NoError(t, repo.DeleteTask(t.Context(), task.ID))
+	})
+
+	t.Run("invalid timestamps are reported", func(t *testing.T) {
+		_, err := db.GetDB().Exec("UPDATE tasks SET created_at = 'yesterday' WHERE id = ?", upcoming.ID)
+		require.NoError(t, err)
+
+		_, err = repo.GetTask(t.Context(), upcoming.ID)
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+		_, err = repo.GetActiveTasks(t.Context(), userID)
+		assert.ErrorIs(t, err, ErrInvalidTimestamp)
+	})
+}
+
+func TestTaskRepository_TaskHistory(t *testing.T) {
+	_, repo := setupTestDB(t)
+
+	task := createTestTask(123)
+	require.NoError(t, repo.AddTask(t.Context(), task))
+
+	t.Run("record and read changes", func(t *testing.T) {
+		changes := []*models.TaskChange{
+			{TaskID: task.ID, UserID: 123, Field: models.FieldDescription, OldValue: "Old", NewValue: "New"},
+			{TaskID: task.ID, UserID: 456, Field: models.FieldStatus, OldValue: models.StatusActive, NewValue: models.StatusDone},
+		}
+
+		err := repo.AddTaskChanges(t.Context(), changes)
+		require.NoError(t, err)
+		assert.NotZero(t, changes[0].ID)
+		assert.NotZero(t, changes[0].ChangedAt)
+
+		history, err := repo.GetTaskHistory(t.Context(), task.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 2)
+		assert.Equal(t, models.FieldDescription, history[0].Field)
+		assert.Equal(t, "Old", history[0].OldValue)
+		assert.Equal(t, "New", history[0].NewValue)
+		assert.Equal(t, 456, history[1].UserID)
+		assert.WithinDuration(t, time.Now(), history[1].ChangedAt, time.Minute)
+	})
+
+	t.Run("task without history", func(t *testing.T) {
+		history, err := repo.GetTaskHistory(t.Context(), 999)
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("invalid change", func(t *testing.T) {
+		err := repo.AddTaskChanges(t.Context(), []*models.TaskChange{{TaskID: task.ID, UserID: 123}})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "field cannot be empty")
+	})
+}
+
 func TestTaskRepository_Integration(t *testing.T) {
 	_, repo := setupTestDB(t)
 
@@ -341,12 +521,12 @@ func TestTaskRepository_Integration(t *testing.T) {
 		task := createTestTask(userID)
 		task.OriginalDescription = "Integration test task"
 
-		err := repo.AddTask(task)
+		err := repo.AddTask(t.Context(), task)
 		require.NoError(t, err)
 		originalID := task.ID
 
 		// Retrieve task
-		retrievedTask, err := repo.GetTask(originalID)
+		retrievedTask, err := repo.GetTask(t.Context(), originalID)
 		require.NoError(t, err)
 		assert.Equal(t, "Integration test task", retrievedTask.OriginalDescription)
 		assert.Equal(t, models.StatusActive, retrievedTask.Status)
@@ -356,45 +536,202 @@ func TestTaskRepository_Integration(t *testing.T) {
 		retrievedTask.LLMProcessedDesc = "AI enhanced description"
 		retrievedTask.Status = models.StatusDone
 
-		err = repo.UpdateTask(retrievedTask)
+		err = repo.UpdateTask(t.Context(), retrievedTask)
 		require.NoError(t, err)
 
 		// Verify update
-		updatedTask, err := repo.GetTask(originalID)
+		updatedTask, err := repo.GetTask(t.Context(), originalID)
 		require.NoError(t, err)
 		assert.Equal(t, "Updated integration test task", updatedTask.OriginalDescription)
 		assert.Equal(t, "AI enhanced description", updatedTask.LLMProcessedDesc)
 		assert.Equal(t, models.StatusDone, updatedTask.Status)
 
 		// Check that task appears in user's tasks
-		userTasks, err := repo.GetTasksByUser(userID)
+		userTasks, err := repo.GetTasksByUser(t.Context(), userID)
 		require.NoError(t, err)
 		assert.Len(t, userTasks, 1)
 		assert.Equal(t, originalID, userTasks[0].ID)
 
 		// Check that task appears in done tasks
-		doneTasks, err := repo.GetTasksByStatus(userID, models.StatusDone)
+		doneTasks, err := repo.GetTasksByStatus(t.Context(), userID, models.StatusDone)
 		require.NoError(t, err)
 		assert.Len(t, doneTasks, 1)
 		assert.Equal(t, originalID, doneTasks[0].ID)
 
 		// Check that task does NOT appear in active tasks
-		activeTasks, err := repo.GetActiveTasks(userID)
+		activeTasks, err := repo.GetActiveTasks(t.Context(), userID)
 		require.NoError(t, err)
 		assert.Empty(t, activeTasks)
 
 		// Delete task
-		err = repo.DeleteTask(originalID)
+		err = repo.DeleteTask(t.Context(), originalID)
 		require.NoError(t, err)
 
 		// Verify deletion
-		_, err = repo.GetTask(originalID)
+		_, err = repo.GetTask(t.Context(), originalID)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "not found")
 
 		// Check that user has no tasks
-		userTasks, err = repo.GetTasksByUser(userID)
+		userTasks, err = repo.GetTasksByUser(t.Context(), userID)
 		require.NoError(t, err)
 		assert.Empty(t, userTasks)
 	})
 }
+
+func TestTaskRepository_CompleteTask(t *testing.T) {
+	db, repo := setupTestDB(t)
+
+	t.Run("one-off task", func(t *testing.T) {
+		task := createTestTask(123)
+		require.NoError(t, repo.AddTask(t.Context(), task))
+		assert.Zero(t, task.SeriesID)
+
+		next, err := repo.CompleteTask(t.Context(), task)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+
+		stored, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusDone, stored.Status)
+	})
+
+	t.Run("recurring task gets the next occurrence in the owner's zone", func(t *testing.T) {
+		require.NoError(t, NewUserRepository(db).SetTimeZone(t.Context(), 456, "Asia/Tokyo"))
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+
+		// Monday in Tokyo, but still Sunday in UTC
+		deadline := time.Date(2030, 7, 15, 8, 0, 0, 0, tokyo)
+		task := &models.Task{
+			UserID:              456,
+			OriginalDescription: "Weekly report",
+			Deadline:            deadline,
+			DeadlineHasTime:     true,
+			Recurrence:          "FREQ=WEEKLY;BYDAY=MO",
+		}
+		require.NoError(t, repo.AddTask(t.Context(), task))
+		assert.Equal(t, task.ID, task.SeriesID)
+
+		next, err := repo.CompleteTask(t.Context(), task)
+		require.NoError(t, err)
+		require.NotNil(t, next)
+		assert.NotEqual(t, task.ID, next.ID)
+
+		stored, err := repo.GetTask(t.Context(), next.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusActive, stored.Status)
+		assert.Equal(t, task.ID, stored.SeriesID)
+		assert.Equal(t, task.Recurrence, stored.Recurrence)
+		assert.True(t, stored.DeadlineHasTime)
+		assert.True(t, deadline.AddDate(0, 0, 7).Equal(stored.Deadline), "got %v", stored.Deadline)
+
+		// The second occurrence stays in the same series
+		third, err := repo.CompleteTask(t.Context(), stored)
+		require.NoError(t, err)
+		require.NotNil(t, third)
+		assert.Equal(t, task.ID, third.SeriesID)
+
+		completed, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, models.StatusDone, completed.Status)
+	})
+
+	t.Run("ended series", func(t *testing.T) {
+		task := createTestTask(123)
+		task.Recurrence = "FREQ=DAILY;UNTIL=20000101"
+		require.NoError(t, repo.AddTask(t.Context(), task))
+
+		next, err := repo.CompleteTask(t.Context(), task)
+		require.NoError(t, err)
+		assert.Nil(t, next)
+	})
+
+	t.Run("missing task", func(t *testing.T) {
+		task := createTestTask(123)
+		task.ID = 99999
+		task.Recurrence = "FREQ=DAILY"
+
+		_, err := repo.CompleteTask(t.Context(), task)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+
+		tasks, err := repo.GetTasksByUser(t.Context(), 123)
+		require.NoError(t, err)
+		for _, stored := range tasks {
+			assert.NotEqual(t, "FREQ=DAILY", stored.Recurrence, "no occurrence is added for a missing task")
+		}
+	})
+}
+
+func TestTaskRepository_WithTx(t *testing.T) {
+	t.Run("changes are committed together", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		task := createTestTask(123)
+		require.NoError(t, repo.AddTask(t.Context(), task))
+
+		err := repo.WithTx(t.Context(), func(tx TaskRepository) error {
+			task.OriginalDescription = "Changed in a transaction"
+			if err := tx.UpdateTask(t.Context(), task); err != nil {
+				return err
+			}
+			return tx.AddTaskChanges(t.Context(), []*models.TaskChange{{
+				TaskID: task.ID, UserID: 123, Field: models.FieldDescription, NewValue: task.OriginalDescription,
+			}})
+		})
+		require.NoError(t, err)
+
+		saved, err := repo.GetTask(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Changed in a transaction", saved.OriginalDescription)
+
+		history, err := repo.GetTaskHistory(t.Context(), task.ID)
+		require.NoError(t, err)
+		assert.Len(t, history, 1)
+	})
+
+	t.Run("error rolls everything back", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		task := createTestTask(123)
+		require.NoError(t, repo.AddTask(t.Context(), task))
+
+		failure := errors.New("failure")
+		err := repo.WithTx(t.Context(), func(tx TaskRepository) error {
+			// Nested calls join the outer transaction
+			err := tx.WithTx(t.Context(), func(nested TaskRepository) error {
+				return nested.DeleteTask(t.Context(), task.ID)
+			})
+			require.NoError(t, err)
+
+			_, err = tx.GetTask(t.Context(), task.ID)
+			require.ErrorIs(t, err, ErrTaskNotFound)
+
+			added := createTestTask(123)
+			if err := tx.AddTask(t.Context(), added); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		tasks, err := repo.GetTasksByUser(t.Context(), 123)
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, task.ID, tasks[0].ID)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		err := repo.AddTask(ctx, createTestTask(123))
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = repo.GetTasksByUser(ctx, 123)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		tasks, err := repo.GetTasksByUser(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+}
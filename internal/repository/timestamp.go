@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidTimestamp is returned when a timestamp column holds a value that is not a time
+var ErrInvalidTimestamp = errors.New("invalid timestamp")
+
+// timeScanner reads a timestamp column into a time.Time in UTC. The drivers return timestamp
+// columns as time.Time; computed columns of SQLite come as text in the canonical form.
+type timeScanner struct {
+	dest     *time.Time
+	nullable bool
+}
+
+// scanTime reads a required timestamp: NULL is an error
+func scanTime(dest *time.Time) sql.Scanner {
+	return &timeScanner{dest: dest}
+}
+
+// scanNullTime reads an optional timestamp: NULL leaves the zero time
+func scanNullTime(dest *time.Time) sql.Scanner {
+	return &timeScanner{dest: dest, nullable: true}
+}
+
+func (s *timeScanner) Scan(src interface{}) error {
+	switch value := src.(type) {
+	case nil:
+		if !s.nullable {
+			return fmt.Errorf("%w: NULL", ErrInvalidTimestamp)
+		}
+		*s.dest = time.Time{}
+	case time.Time:
+		// go-sqlite3 turns text it cannot parse into the zero time instead of failing
+		if value.IsZero() {
+			return fmt.Errorf("%w: unparsable value", ErrInvalidTimestamp)
+		}
+		*s.dest = value.UTC()
+	case string:
+		return s.parse(value)
+	case []byte:
+		return s.parse(string(value))
+	default:
+		return fmt.Errorf("%w: unexpected %T", ErrInvalidTimestamp, src)
+	}
+	return nil
+}
+
+func (s *timeScanner) parse(value string) error {
+	parsed, err := time.ParseInLocation(sqliteTimeFormat, value, time.UTC)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidTimestamp, value)
+	}
+	*s.dest = parsed
+	return nil
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeScanner(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	expected := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	t.Run("driver times are converted to UTC", func(t *testing.T) {
+		var value time.Time
+		require.NoError(t, scanTime(&value).Scan(expected.In(moscow)))
+		assert.Equal(t, expected, value)
+	})
+
+	t.Run("canonical text", func(t *testing.T) {
+		var value time.Time
+		require.NoError(t, scanTime(&value).Scan("2025-03-01 09:30:00"))
+		assert.Equal(t, expected, value)
+		require.NoError(t, scanTime(&value).Scan([]byte("2025-03-01 09:30:00")))
+		assert.Equal(t, expected, value)
+	})
+
+	t.Run("NULL", func(t *testing.T) {
+		value := expected
+		require.NoError(t, scanNullTime(&value).Scan(nil))
+		assert.True(t, value.IsZero())
+		assert.ErrorIs(t, scanTime(&value).Scan(nil), ErrInvalidTimestamp)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		var value time.Time
+		assert.ErrorIs(t, scanTime(&value).Scan("2025-03-01T09:30:00+03:00"), ErrInvalidTimestamp)
+		assert.ErrorIs(t, scanNullTime(&value).Scan(time.Time{}), ErrInvalidTimestamp)
+		assert.ErrorIs(t, scanTime(&value).Scan(int64(1740821400)), ErrInvalidTimestamp)
+	})
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// TrashTask marks the task as deleted; the row is kept until the trash is purged
+func (r *SQLTaskRepository) TrashTask(ctx context.Context, id int) error {
+	now := time.Now()
+	result, err := r.executor().ExecContext(ctx,
+		"UPDATE tasks SET deleted_at = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL",
+		now, now, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to move task to trash: %w", err)
+	}
+
+	return requireTrashRow(result, id)
+}
+
+// RestoreTask clears the deletion mark of a task in the trash
+func (r *SQLTaskRepository) RestoreTask(ctx context.Context, id int) error {
+	result, err := r.executor().ExecContext(ctx,
+		"UPDATE tasks SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL",
+		time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	return requireTrashRow(result, id)
+}
+
+// GetTrashedTasks retrieves the tasks of a user in the trash, the most recently deleted first
+func (r *SQLTaskRepository) GetTrashedTasks(ctx context.Context, userID int) ([]*models.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE user_id = ? AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+	`
+
+	return r.queryTasks(ctx, query, userID)
+}
+
+// PurgeTrash deletes the tasks moved to the trash before the given time. Their discussions,
+// history and tag links go with them, and tags left without tasks are removed.
+func (r *SQLTaskRepository) PurgeTrash(ctx context.Context, before time.Time) (int, error) {
+	var purged int
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx,
+			"DELETE FROM tasks WHERE deleted_at IS NOT NULL AND deleted_at < ? RETURNING user_id",
+			before,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to purge trash: %w", err)
+		}
+
+		users := make(map[int]bool)
+		for rows.Next() {
+			var userID int
+			if err := rows.Scan(&userID); err != nil {
+				rows.Close()
+				return fmt.Errorf("failed to scan purged task: %w", err)
+			}
+			users[userID] = true
+			purged++
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return fmt.Errorf("error during rows iteration: %w", err)
+		}
+		if err := rows.Close(); err != nil {
+			return fmt.Errorf("failed to purge trash: %w", err)
+		}
+
+		for userID := range users {
+			if err := deleteUnusedTags(ctx, tx, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// requireTrashRow returns ErrTaskNotFound if the statement changed no task
+func requireTrashRow(result sql.Result, id int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id %d", ErrTaskNotFound, id)
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrashRepository(t *testing.T) {
+	t.Run("trashed tasks are hidden from queries", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		kept := addTaggedTask(t, repo, 123, "Quarterly report", "work")
+		trashed := addTaggedTask(t, repo, 123, "Quarterly budget", "work", "money")
+		trashed.Deadline = time.Now().Add(-time.Hour)
+		require.NoError(t, repo.UpdateTask(t.Context(), trashed))
+
+		require.NoError(t, repo.TrashTask(t.Context(), trashed.ID))
+
+		_, err := repo.GetTask(t.Context(), trashed.ID)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+
+		for name, query := range map[string]func() ([]*models.Task, error){
+			"by user":   func() ([]*models.Task, error) { return repo.GetTasksByUser(t.Context(), 123) },
+			"active":    func() ([]*models.Task, error) { return repo.GetActiveTasks(t.Context(), 123) },
+			"by status": func() ([]*models.Task, error) { return repo.GetTasksByStatus(t.Context(), 123, models.StatusActive) },
+			"by tag":    func() ([]*models.Task, error) { return repo.GetTasksByTag(t.Context(), 123, "work") },
+		} {
+			tasks, err := query()
+			require.NoError(t, err, name)
+			assert.Equal(t, []int{kept.ID}, taskIDs(tasks), name)
+		}
+
+		overdue, err := repo.GetOverdueTasks(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Empty(t, overdue)
+
+		assert.Equal(t, []int{kept.ID}, searchIDs(t, repo, 123, "quarterly"))
+
+		tags, err := repo.ListTags(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []models.TagCount{{Name: "work", Tasks: 1, Active: 1}}, tags)
+
+		// A trashed task cannot be changed or trashed again
+		trashed.Status = models.StatusDone
+		assert.ErrorIs(t, repo.UpdateTask(t.Context(), trashed), ErrTaskNotFound)
+		assert.ErrorIs(t, repo.TrashTask(t.Context(), trashed.ID), ErrTaskNotFound)
+	})
+
+	t.Run("trash lists and restores tasks", func(t *testing.T) {
+		_, repo := setupTestDB(t)
+		first := addTaggedTask(t, repo, 123, "First", "work")
+		second := addTaggedTask(t, repo, 123, "Second")
+		other := addTaggedTask(t, repo, 456, "Other user")
+
+		require.NoError(t, repo.TrashTask(t.Context(), first.ID))
+		require.NoError(t, repo.TrashTask(t.Context(), second.ID))
+		require.NoError(t, repo.TrashTask(t.Context(), other.ID))
+
+		trash, err := repo.GetTrashedTasks(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []int{second.ID, first.ID}, taskIDs(trash))
+		assert.True(t, trash[1].IsDeleted())
+		assert.Equal(t, []string{"work"}, trash[1].Tags)
+
+		require.NoError(t, repo.RestoreTask(t.Context(), first.ID))
+		assert.ErrorIs(t, repo.RestoreTask(t.Context(), first.ID), ErrTaskNotFound)
+
+		restored, err := repo.GetTask(t.Context(), first.ID)
+		require.NoError(t, err)
+		assert.False(t, restored.IsDeleted())
+		assert.Equal(t, []string{"work"}, restored.Tags)
+
+		trash, err = repo.GetTrashedTasks(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []int{second.ID}, taskIDs(trash))
+	})
+
+	t.Run("purge deletes old trash with its discussions and tags", func(t *testing.T) {
+		db, repo := setupTestDB(t)
+		discussions := NewDiscussionRepository(db)
+
+		old := addTaggedTask(t, repo, 123, "Old", "archive")
+		require.NoError(t, discussions.AddDiscussion(t.Context(), &models.Discussion{TaskID: old.ID, MessageID: 1, Text: "note"}))
+		recent := addTaggedTask(t, repo, 123, "Recent")
+		active := addTaggedTask(t, repo, 123, "Active")
+
+		require.NoError(t, repo.TrashTask(t.Context(), old.ID))
+		_, err := db.GetDB().Exec("UPDATE tasks SET deleted_at = ? WHERE id = ?", time.Now().Add(-40*24*time.Hour), old.ID)
+		require.NoError(t, err)
+		require.NoError(t, repo.TrashTask(t.Context(), recent.ID))
+
+		purged, err := repo.PurgeTrash(t.Context(), time.Now().Add(-30*24*time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, 1, purged)
+
+		trash, err := repo.GetTrashedTasks(t.Context(), 123)
+		require.NoError(t, err)
+		assert.Equal(t, []int{recent.ID}, taskIDs(trash))
+
+		_, err = repo.GetTask(t.Context(), active.ID)
+		assert.NoError(t, err)
+
+		var count int
+		require.NoError(t, db.GetDB().QueryRow("SELECT COUNT(*) FROM discussions WHERE task_id = ?", old.ID).Scan(&count))
+		assert.Zero(t, count)
+		require.NoError(t, db.GetDB().QueryRow("SELECT COUNT(*) FROM tags WHERE name = 'archive'").Scan(&count))
+		assert.Zero(t, count)
+
+		purged, err = repo.PurgeTrash(t.Context(), time.Now().Add(-30*24*time.Hour))
+		require.NoError(t, err)
+		assert.Zero(t, purged)
+	})
+
+	t.Run("rollback returns trashed tasks", func(t *testing.T) {
+		db, repo := setupTestDB(t)
+		task := createTestTask(123)
+		require.NoError(t, repo.AddTask(t.Context(), task))
+		require.NoError(t, repo.TrashTask(t.Context(), task.ID))
+
+		require.NoError(t, db.MigrateTo(14))
+		require.NoError(t, db.Migrate())
+
+		_, err := repo.GetTask(t.Context(), task.ID)
+		assert.NoError(t, err)
+	})
+}
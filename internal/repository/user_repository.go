@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// ErrUserNotFound is returned when a user with the requested ID or username does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// UserRepository defines the interface for the registry of Telegram users
+type UserRepository interface {
+	// UpsertUser creates the user or updates the Telegram profile and reports whether the user is new
+	UpsertUser(ctx context.Context, user *models.User) (bool, error)
+	GetUser(ctx context.Context, id int) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
+	// SetTimeZone stores the IANA time zone of the user; an empty zone resets it to the server's zone
+	SetTimeZone(ctx context.Context, userID int, timeZone string) error
+	// SetDateOrder stores the order of day and month in dates; an empty order resets the choice
+	SetDateOrder(ctx context.Context, userID int, order string) error
+}
+
+// SQLUserRepository implements UserRepository for SQLite and PostgreSQL databases
+type SQLUserRepository struct {
+	db *sql.DB
+}
+
+// NewUserRepository creates a new user repository instance
+func NewUserRepository(database *Database) UserRepository {
+	return &SQLUserRepository{
+		db: database.GetDB(),
+	}
+}
+
+// UpsertUser creates the user or updates the Telegram profile if it changed
+func (r *SQLUserRepository) UpsertUser(ctx context.Context, user *models.User) (bool, error) {
+	user.Username = normalizeUsername(user.Username)
+	if err := user.Validate(); err != nil {
+		return false, fmt.Errorf("user validation failed: %w", err)
+	}
+
+	user.SetDefaults()
+
+	result, err := r.db.ExecContext(ctx, `
+		INSERT INTO users (id, username, first_name, last_name, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING
+	`,
+		user.ID,
+		nullString(user.Username),
+		user.FirstName,
+		nullString(user.LastName),
+		user.CreatedAt,
+		user.UpdatedAt,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to insert user: %w", err)
+	}
+
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if inserted > 0 {
+		return true, nil
+	}
+
+	// The row is only rewritten when the profile actually changed
+	_, err = r.db.ExecContext(ctx, `
+		UPDATE users SET username = ?, first_name = ?, last_name = ?, updated_at = ?
+		WHERE id = ? AND (username IS DISTINCT FROM ? OR first_name IS DISTINCT FROM ? OR last_name IS DISTINCT FROM ?)
+	`,
+		nullString(user.Username), user.FirstName, nullString(user.LastName), user.UpdatedAt,
+		user.ID, nullString(user.Username), user.FirstName, nullString(user.LastName),
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	return false, nil
+}
+
+// GetUser retrieves a user by Telegram ID
+func (r *SQLUserRepository) GetUser(ctx context.Context, id int) (*models.User, error) {
+	user, err := scanUser(r.db.QueryRowContext(ctx, `
+		SELECT id, username, first_name, last_name, time_zone, date_order, created_at, updated_at
+		FROM users WHERE id = ?
+	`, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: id %d", ErrUserNotFound, id)
+	}
+	return user, err
+}
+
+// GetUserByUsername retrieves a user by Telegram username, with or without the leading @.
+// Usernames are compared case-insensitively; if a username moved to another account,
+// the most recently seen user is returned.
+func (r *SQLUserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	username = normalizeUsername(username)
+	if username == "" {
+		return nil, fmt.Errorf("%w: empty username", ErrUserNotFound)
+	}
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, `
+		SELECT id, username, first_name, last_name, time_zone, date_order, created_at, updated_at
+		FROM users WHERE lower(username) = lower(?)
+		ORDER BY updated_at DESC, id DESC
+		LIMIT 1
+	`, username))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: @%s", ErrUserNotFound, username)
+	}
+	return user, err
+}
+
+// SetTimeZone stores the IANA time zone of the user
+func (r *SQLUserRepository) SetTimeZone(ctx context.Context, userID int, timeZone string) error {
+	if timeZone != "" {
+		if _, err := models.LoadTimeZone(timeZone); err != nil {
+			return err
+		}
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET time_zone = ?, updated_at = ? WHERE id = ?",
+		nullString(timeZone), time.Now(), userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set time zone: %w", err)
+	}
+
+	return requireUserUpdated(result, userID)
+}
+
+// SetDateOrder stores the order of day and month in dates of the user
+func (r *SQLUserRepository) SetDateOrder(ctx context.Context, userID int, order string) error {
+	if order != "" && !models.IsValidDateOrder(order) {
+		return fmt.Errorf("unknown date order %q", order)
+	}
+
+	result, err := r.db.ExecContext(ctx,
+		"UPDATE users SET date_order = ?, updated_at = ? WHERE id = ?",
+		nullString(order), time.Now(), userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to set date order: %w", err)
+	}
+
+	return requireUserUpdated(result, userID)
+}
+
+// requireUserUpdated returns ErrUserNotFound if the update did not match the user
+func requireUserUpdated(result sql.Result, userID int) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w: id %d", ErrUserNotFound, userID)
+	}
+
+	return nil
+}
+
+// scanUser reads a users row
+func scanUser(row *sql.Row) (*models.User, error) {
+	var user models.User
+	var username, lastName, timeZone, dateOrder sql.NullString
+
+	if err := row.Scan(&user.ID, &username, &user.FirstName, &lastName, &timeZone, &dateOrder,
+		scanTime(&user.CreatedAt), scanTime(&user.UpdatedAt)); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	user.Username = username.String
+	user.LastName = lastName.String
+	user.TimeZone = timeZone.String
+	user.DateOrder = dateOrder.String
+
+	return &user, nil
+}
+
+// normalizeUsername strips whitespace and the leading @ from a Telegram username
+func normalizeUsername(username string) string {
+	return strings.TrimPrefix(strings.TrimSpace(username), "@")
+}
+
+// nullString stores empty optional strings as NULL
+func nullString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullTime stores unset optional timestamps as NULL
+func nullTime(value time.Time) interface{} {
+	if value.IsZero() {
+		return nil
+	}
+	return value
+}
+
+// nullInt stores unset optional IDs as NULL
+func nullInt(value int) interface{} {
+	if value == 0 {
+		return nil
+	}
+	return value
+}
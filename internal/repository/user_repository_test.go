@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"testing"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserRepository(t *testing.T) {
+	db, taskRepo := setupTestDB(t)
+	repo := NewUserRepository(db)
+
+	t.Run("create and update", func(t *testing.T) {
+		created, err := repo.UpsertUser(t.Context(), &models.User{ID: 777, Username: "@Alice", FirstName: "Alice"})
+		require.NoError(t, err)
+		assert.True(t, created)
+
+		user, err := repo.GetUser(t.Context(), 777)
+		require.NoError(t, err)
+		assert.Equal(t, "Alice", user.Username)
+		assert.Empty(t, user.LastName)
+		assert.False(t, user.CreatedAt.IsZero())
+
+		created, err = repo.UpsertUser(t.Context(), &models.User{ID: 777, Username: "alice_new", FirstName: "Alice", LastName: "Smith"})
+		require.NoError(t, err)
+		assert.False(t, created)
+
+		user, err = repo.GetUser(t.Context(), 777)
+		require.NoError(t, err)
+		assert.Equal(t, "alice_new", user.Username)
+		assert.Equal(t, "Alice Smith", user.GetFullName())
+	})
+
+	t.Run("lookup by username", func(t *testing.T) {
+		user, err := repo.GetUserByUsername(t.Context(), "@ALICE_NEW")
+		require.NoError(t, err)
+		assert.Equal(t, 777, user.ID)
+
+		_, err = repo.GetUserByUsername(t.Context(), "alice")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+
+		_, err = repo.GetUserByUsername(t.Context(), "@")
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		_, err := repo.GetUser(t.Context(), 999)
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("time zone", func(t *testing.T) {
+		require.NoError(t, repo.SetTimeZone(t.Context(), 777, "Europe/Moscow"))
+
+		// Profile updates keep the time zone
+		_, err := repo.UpsertUser(t.Context(), &models.User{ID: 777, Username: "alice_new", FirstName: "Alicia"})
+		require.NoError(t, err)
+
+		user, err := repo.GetUser(t.Context(), 777)
+		require.NoError(t, err)
+		assert.Equal(t, "Europe/Moscow", user.TimeZone)
+		assert.Equal(t, "Europe/Moscow", user.Location().String())
+
+		require.NoError(t, repo.SetTimeZone(t.Context(), 777, ""))
+		user, err = repo.GetUser(t.Context(), 777)
+		require.NoError(t, err)
+		assert.Empty(t, user.TimeZone)
+
+		assert.Error(t, repo.SetTimeZone(t.Context(), 777, "Mars/Olympus"))
+		assert.ErrorIs(t, repo.SetTimeZone(t.Context(), 999, "UTC"), ErrUserNotFound)
+	})
+
+	t.Run("date order", func(t *testing.T) {
+		require.NoError(t, repo.SetDateOrder(t.Context(), 777, models.DateOrderMDY))
+
+		// Profile updates keep the date order
+		_, err := repo.UpsertUser(t.Context(), &models.User{ID: 777, Username: "alice_new", FirstName: "Alicia"})
+		require.NoError(t, err)
+
+		user, err := repo.GetUser(t.Context(), 777)
+		require.NoError(t, err)
+		assert.Equal(t, models.DateOrderMDY, user.DateOrder)
+
+		require.NoError(t, repo.SetDateOrder(t.Context(), 777, ""))
+		user, err = repo.GetUser(t.Context(), 777)
+		require.NoError(t, err)
+		assert.Empty(t, user.DateOrder)
+
+		assert.Error(t, repo.SetDateOrder(t.Context(), 777, "dm"))
+		assert.ErrorIs(t, repo.SetDateOrder(t.Context(), 999, models.DateOrderDMY), ErrUserNotFound)
+	})
+
+	t.Run("invalid user", func(t *testing.T) {
+		_, err := repo.UpsertUser(t.Context(), &models.User{ID: 778})
+		assert.Error(t, err)
+	})
+
+	t.Run("tasks require a registered user", func(t *testing.T) {
+		err := taskRepo.AddTask(t.Context(), createTestTask(999))
+		assert.Error(t, err)
+	})
+
+	t.Run("deleting a user removes their tasks", func(t *testing.T) {
+		task := createTestTask(777)
+		require.NoError(t, taskRepo.AddTask(t.Context(), task))
+
+		_, err := db.GetDB().Exec("DELETE FROM users WHERE id = ?", 777)
+		require.NoError(t, err)
+
+		_, err = taskRepo.GetTask(t.Context(), task.ID)
+		assert.ErrorIs(t, err, ErrTaskNotFound)
+	})
+}
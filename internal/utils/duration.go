@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationPart matches one number with a unit, e.g. 1д, 3ч, 30мин, 2h
+var durationPart = regexp.MustCompile(`^(\d+)\s*(дней|дня|день|дн|д|d|часов|часа|час|ч|h|минуты|минут|мин|м|min|m)`)
+
+// durationUnits maps unit spellings to their length
+var durationUnits = map[string]time.Duration{
+	"дней": 24 * time.Hour, "дня": 24 * time.Hour, "день": 24 * time.Hour, "дн": 24 * time.Hour, "д": 24 * time.Hour, "d": 24 * time.Hour,
+	"часов": time.Hour, "часа": time.Hour, "час": time.Hour, "ч": time.Hour, "h": time.Hour,
+	"минут": time.Minute, "минуты": time.Minute, "мин": time.Minute, "м": time.Minute, "min": time.Minute, "m": time.Minute,
+}
+
+// ParseLeadTime parses a reminder lead time such as 1д, 3ч, 30мин or 1д12ч
+func ParseLeadTime(text string) (time.Duration, error) {
+	rest := strings.ToLower(strings.TrimSpace(text))
+	if rest == "" {
+		return 0, errors.New("empty lead time")
+	}
+
+	var total time.Duration
+	for rest != "" {
+		match := durationPart.FindStringSubmatch(rest)
+		if match == nil {
+			return 0, fmt.Errorf("invalid lead time %q, use e.g. 1д, 3ч or 30мин", text)
+		}
+
+		value, err := strconv.Atoi(match[1])
+		if err != nil {
+			return 0, fmt.Errorf("invalid lead time %q: %w", text, err)
+		}
+
+		total += time.Duration(value) * durationUnits[match[2]]
+		rest = strings.TrimSpace(rest[len(match[0]):])
+	}
+
+	if total <= 0 {
+		return 0, fmt.Errorf("lead time %q must be positive", text)
+	}
+
+	return total, nil
+}
+
+// FormatLeadTime formats a lead time in days, hours and minutes, e.g. "1 дн. 12 ч."
+func FormatLeadTime(lead time.Duration) string {
+	days := int(lead / (24 * time.Hour))
+	hours := int(lead % (24 * time.Hour) / time.Hour)
+	minutes := int(lead % time.Hour / time.Minute)
+
+	var parts []string
+	if days > 0 {
+		parts = append(parts, fmt.Sprintf("%d дн.", days))
+	}
+	if hours > 0 {
+		parts = append(parts, fmt.Sprintf("%d ч.", hours))
+	}
+	if minutes > 0 || len(parts) == 0 {
+		parts = append(parts, fmt.Sprintf("%d мин.", minutes))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,50 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLeadTime(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+	}{
+		{"1д", 24 * time.Hour},
+		{"2 дня", 48 * time.Hour},
+		{"3ч", 3 * time.Hour},
+		{"1 час", time.Hour},
+		{"30мин", 30 * time.Minute},
+		{"15 минуты", 15 * time.Minute},
+		{"45m", 45 * time.Minute},
+		{"2h", 2 * time.Hour},
+		{"1д12ч", 36 * time.Hour},
+		{"1Ч 30М", 90 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			lead, err := ParseLeadTime(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, lead)
+		})
+	}
+
+	for _, input := range []string{"", "завтра", "10", "1w", "0ч", "1д потом"} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := ParseLeadTime(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFormatLeadTime(t *testing.T) {
+	assert.Equal(t, "1 дн.", FormatLeadTime(24*time.Hour))
+	assert.Equal(t, "1 ч.", FormatLeadTime(time.Hour))
+	assert.Equal(t, "1 дн. 12 ч.", FormatLeadTime(36*time.Hour))
+	assert.Equal(t, "1 ч. 30 мин.", FormatLeadTime(90*time.Minute))
+	assert.Equal(t, "0 мин.", FormatLeadTime(0))
+}
@@ -0,0 +1,347 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errUnrecognizedDate means the text is not a natural-language date at all
+var errUnrecognizedDate = errors.New("unrecognized date")
+
+// AmbiguousDateError is returned when a date can be read in several ways
+type AmbiguousDateError struct {
+	Input   string
+	Options []time.Time
+	HasTime bool // The options carry the time of day given with the date
+}
+
+// Error lists the possible interpretations of the input
+func (e *AmbiguousDateError) Error() string {
+	options := make([]string, len(e.Options))
+	for i, option := range e.Options {
+		options[i] = FormatDeadline(option, e.HasTime)
+	}
+	return fmt.Sprintf("ambiguous date %q, it may mean: %s", e.Input, strings.Join(options, ", "))
+}
+
+// weekdayStems maps the stems of Russian weekday names in any case ("пятница", "пятницу", "пятницы")
+var weekdayStems = []struct {
+	stem    string
+	weekday time.Weekday
+}{
+	{"понедельник", time.Monday},
+	{"вторник", time.Tuesday},
+	{"сред", time.Wednesday},
+	{"четверг", time.Thursday},
+	{"пятниц", time.Friday},
+	{"суббот", time.Saturday},
+	{"воскресень", time.Sunday},
+}
+
+// weekdayNames holds abbreviations and English weekday names
+var weekdayNames = map[string]time.Weekday{
+	"пн": time.Monday, "вт": time.Tuesday, "ср": time.Wednesday, "чт": time.Thursday,
+	"пт": time.Friday, "сб": time.Saturday, "вс": time.Sunday,
+	"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+	"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+// monthStems maps the stems of Russian month names in any case ("июль", "июля")
+var monthStems = []struct {
+	stem  string
+	month time.Month
+}{
+	{"январ", time.January},
+	{"феврал", time.February},
+	{"март", time.March},
+	{"апрел", time.April},
+	{"июн", time.June},
+	{"июл", time.July},
+	{"август", time.August},
+	{"сентябр", time.September},
+	{"октябр", time.October},
+	{"ноябр", time.November},
+	{"декабр", time.December},
+}
+
+// monthNames holds forms that are not covered by monthStems and English month names
+var monthNames = map[string]time.Month{
+	"май": time.May, "мая": time.May,
+	"янв": time.January, "фев": time.February, "мар": time.March, "апр": time.April,
+	"авг": time.August, "сен": time.September, "сент": time.September, "окт": time.October,
+	"ноя": time.November, "дек": time.December,
+	"january": time.January, "february": time.February, "march": time.March, "april": time.April,
+	"may": time.May, "june": time.June, "july": time.July, "august": time.August,
+	"september": time.September, "october": time.October, "november": time.November, "december": time.December,
+	"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+	"jun": time.June, "jul": time.July, "aug": time.August, "sep": time.September,
+	"oct": time.October, "nov": time.November, "dec": time.December,
+}
+
+// numberWords holds small numbers written as words ("через два дня")
+var numberWords = map[string]int{
+	"один": 1, "одну": 1, "одна": 1, "два": 2, "две": 2, "три": 3, "четыре": 4, "пять": 5,
+	"шесть": 6, "семь": 7, "восемь": 8, "девять": 9, "десять": 10,
+	"one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "six": 6, "seven": 7,
+}
+
+var (
+	// relativeRegex matches "через 3 дня", "через неделю" and "in 2 weeks"
+	relativeRegex = regexp.MustCompile(`^(?:через|in)(?:\s+(\S+))?\s+(\S+)$`)
+	// monthDayRegex matches "15 июля" and "15 июля 2026"
+	monthDayRegex = regexp.MustCompile(`^(\d{1,2})\s+(\p{L}+)(?:\s+(\d{4}))?$`)
+	// englishMonthDayRegex matches "july 15" and "july 15 2026"
+	englishMonthDayRegex = regexp.MustCompile(`^(\p{L}+)\s+(\d{1,2})(?:\s+(\d{4}))?$`)
+)
+
+// ParseNaturalDate parses dates written in words, mostly in Russian:
+// "сегодня", "завтра", "послезавтра", "через 3 дня", "через неделю", "в пятницу",
+// "в следующую пятницу", "до конца недели", "до конца месяца", "15 июля", "next monday".
+// now is the current time in the user's time zone. The result is the end of the day in
+// that zone. If the text may mean several dates, an *AmbiguousDateError lists them.
+func ParseNaturalDate(text string, now time.Time) (time.Time, error) {
+	input := strings.TrimSpace(text)
+	phrase := normalizeDatePhrase(input)
+	if phrase == "" {
+		return time.Time{}, errors.New("empty date string")
+	}
+
+	today := endOfDay(now)
+
+	// "до пятницы", "к понедельнику", "by friday" mean the same as the date itself
+	for _, prefix := range []string{"до ", "к ", "by ", "until "} {
+		phrase = strings.TrimPrefix(phrase, prefix)
+	}
+
+	switch phrase {
+	case "сегодня", "today":
+		return today, nil
+	case "завтра", "tomorrow":
+		return today.AddDate(0, 0, 1), nil
+	case "послезавтра", "day after tomorrow", "the day after tomorrow":
+		return today.AddDate(0, 0, 2), nil
+	case "конца недели", "конец недели", "end of week", "end of the week", "end of this week":
+		return today.AddDate(0, 0, (7-int(now.Weekday()))%7), nil
+	case "конца месяца", "конец месяца", "end of month", "end of the month", "end of this month":
+		return time.Date(now.Year(), now.Month()+1, 0, 23, 59, 59, 0, now.Location()), nil
+	}
+
+	if matches := relativeRegex.FindStringSubmatch(phrase); matches != nil {
+		return parseRelativeDate(matches[1], matches[2], today)
+	}
+
+	if date, err := parseWeekdayDate(input, phrase, now); !errors.Is(err, errUnrecognizedDate) {
+		return date, err
+	}
+
+	if matches := monthDayRegex.FindStringSubmatch(phrase); matches != nil {
+		if month, ok := parseMonthName(matches[2]); ok {
+			return monthDayDate(matches[1], month, matches[3], now)
+		}
+	}
+	if matches := englishMonthDayRegex.FindStringSubmatch(phrase); matches != nil {
+		if month, ok := parseMonthName(matches[1]); ok {
+			return monthDayDate(matches[2], month, matches[3], now)
+		}
+	}
+
+	return time.Time{}, errUnrecognizedDate
+}
+
+// normalizeDatePhrase lowercases the text, replaces ё and drops commas, dots and extra spaces
+func normalizeDatePhrase(text string) string {
+	text = strings.ToLower(text)
+	text = strings.ReplaceAll(text, "ё", "е")
+	text = strings.NewReplacer(",", " ", ".", " ").Replace(text)
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// endOfDay returns the last second of the day of t in its zone
+func endOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 23, 59, 59, 0, t.Location())
+}
+
+// maxRelativeYears limits how far ahead "через <count> <unit>" may reach
+const maxRelativeYears = 100
+
+// parseRelativeDate resolves "через <count> <unit>" from today. Dates more than
+// maxRelativeYears ahead are not recognized.
+func parseRelativeDate(countStr, unit string, today time.Time) (time.Time, error) {
+	count := 1
+	if countStr != "" {
+		if n, ok := numberWords[countStr]; ok {
+			count = n
+		} else if n, err := strconv.Atoi(countStr); err == nil && n > 0 && n <= maxRelativeYears*366 {
+			count = n
+		} else {
+			return time.Time{}, errUnrecognizedDate
+		}
+	}
+
+	var date time.Time
+	switch unit {
+	case "день", "дня", "дней", "сутки", "day", "days":
+		date = today.AddDate(0, 0, count)
+	case "неделю", "недели", "недель", "week", "weeks":
+		date = today.AddDate(0, 0, 7*count)
+	case "месяц", "месяца", "месяцев", "month", "months":
+		date = addMonths(today, count)
+	case "год", "года", "лет", "year", "years":
+		date = addMonths(today, 12*count)
+	default:
+		return time.Time{}, errUnrecognizedDate
+	}
+
+	if date.After(today.AddDate(maxRelativeYears, 0, 0)) {
+		return time.Time{}, errUnrecognizedDate
+	}
+	return date, nil
+}
+
+// addMonths adds months without overflowing into the next month: 31 January + 1 month is 28 February
+func addMonths(t time.Time, months int) time.Time {
+	lastDay := time.Date(t.Year(), t.Month()+time.Month(months)+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return time.Date(t.Year(), t.Month()+time.Month(months), day, t.Hour(), t.Minute(), t.Second(), 0, t.Location())
+}
+
+// parseWeekdayDate resolves "в пятницу", "в эту пятницу" and "в следующую пятницу"
+func parseWeekdayDate(input, phrase string, now time.Time) (time.Time, error) {
+	words := strings.Fields(phrase)
+	if len(words) > 0 && (words[0] == "в" || words[0] == "во" || words[0] == "on") {
+		words = words[1:]
+	}
+
+	next, this := false, false
+	if len(words) == 2 {
+		switch words[0] {
+		case "следующий", "следующую", "следующее", "следующей", "следующего", "next":
+			next = true
+		case "этот", "эту", "это", "этой", "этого", "this":
+			this = true
+		default:
+			return time.Time{}, errUnrecognizedDate
+		}
+		words = words[1:]
+	}
+	if len(words) != 1 {
+		return time.Time{}, errUnrecognizedDate
+	}
+
+	weekday, ok := parseWeekdayName(words[0])
+	if !ok {
+		return time.Time{}, errUnrecognizedDate
+	}
+
+	today := endOfDay(now)
+	daysAhead := (int(weekday) - int(now.Weekday()) + 7) % 7
+	upcoming := today.AddDate(0, 0, daysAhead)
+
+	switch {
+	case this:
+		if weekPosition(weekday) < weekPosition(now.Weekday()) {
+			return time.Time{}, fmt.Errorf("%s has already passed this week", input)
+		}
+		return upcoming, nil
+	case next:
+		// "Next friday" on a Friday is a week later
+		if daysAhead == 0 {
+			return today.AddDate(0, 0, 7), nil
+		}
+		// The coming day already falls into the next week
+		if weekPosition(weekday) < weekPosition(now.Weekday()) {
+			return upcoming, nil
+		}
+		// Otherwise it may mean the coming day or the same day of the next week
+		return time.Time{}, &AmbiguousDateError{Input: input, Options: []time.Time{upcoming, upcoming.AddDate(0, 0, 7)}}
+	case daysAhead == 0:
+		return time.Time{}, &AmbiguousDateError{Input: input, Options: []time.Time{today, today.AddDate(0, 0, 7)}}
+	default:
+		return upcoming, nil
+	}
+}
+
+// weekPosition returns the index of the weekday in a week starting on Monday
+func weekPosition(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+// parseWeekdayName recognizes a weekday in any case form, abbreviated or in English
+func parseWeekdayName(word string) (time.Weekday, bool) {
+	if weekday, ok := weekdayNames[word]; ok {
+		return weekday, true
+	}
+	for _, candidate := range weekdayStems {
+		if strings.HasPrefix(word, candidate.stem) {
+			return candidate.weekday, true
+		}
+	}
+	return 0, false
+}
+
+// parseMonthName recognizes a month in any case form, abbreviated or in English
+func parseMonthName(word string) (time.Month, bool) {
+	if month, ok := monthNames[word]; ok {
+		return month, true
+	}
+	for _, candidate := range monthStems {
+		if strings.HasPrefix(word, candidate.stem) {
+			return candidate.month, true
+		}
+	}
+	return 0, false
+}
+
+// monthDayDate builds the date for "15 июля"; without a year it is the nearest such date from today
+func monthDayDate(dayStr string, month time.Month, yearStr string, now time.Time) (time.Time, error) {
+	day, err := strconv.Atoi(dayStr)
+	if err != nil {
+		return time.Time{}, errUnrecognizedDate
+	}
+
+	year := now.Year()
+	if yearStr != "" {
+		if year, err = strconv.Atoi(yearStr); err != nil {
+			return time.Time{}, errUnrecognizedDate
+		}
+	}
+
+	date := time.Date(year, month, day, 23, 59, 59, 0, now.Location())
+	if date.Day() != day {
+		return time.Time{}, fmt.Errorf("invalid date: %d %s has only %d days", day, month, time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day())
+	}
+
+	if yearStr == "" && date.Before(now) {
+		date = date.AddDate(1, 0, 0)
+	}
+
+	return date, nil
+}
+
+// genitiveMonthNames are Russian month names as used after a day number ("3 апреля")
+var genitiveMonthNames = [...]string{
+	"января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря",
+}
+
+// shortWeekdayNames are Russian weekday abbreviations indexed by time.Weekday
+var shortWeekdayNames = [...]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"}
+
+// FormatDateWords formats a date in words so that it cannot be misread: "чт, 3 апреля 2025"
+// or "чт, 3 апреля 2025 в 15:30" when the time of day matters
+func FormatDateWords(date time.Time, hasTime bool) string {
+	text := fmt.Sprintf("%s, %d %s %d", shortWeekdayNames[date.Weekday()], date.Day(), genitiveMonthNames[date.Month()-1], date.Year())
+	if hasTime {
+		text += " в " + date.Format("15:04")
+	}
+	return text
+}
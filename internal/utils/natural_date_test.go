@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNaturalDate(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	require.NoError(t, err)
+
+	// Wednesday
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, moscow)
+	day := func(month time.Month, d, year int) time.Time {
+		return time.Date(year, month, d, 23, 59, 59, 0, moscow)
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		expected  time.Time
+		ambiguous []time.Time
+		hasError  bool
+	}{
+		{name: "today", input: "сегодня", expected: day(time.July, 16, 2025)},
+		{name: "tomorrow", input: "завтра", expected: day(time.July, 17, 2025)},
+		{name: "tomorrow capitalized", input: "Завтра", expected: day(time.July, 17, 2025)},
+		{name: "day after tomorrow", input: "послезавтра", expected: day(time.July, 18, 2025)},
+		{name: "until tomorrow", input: "до завтра", expected: day(time.July, 17, 2025)},
+		{name: "in 3 days", input: "через 3 дня", expected: day(time.July, 19, 2025)},
+		{name: "in two days in words", input: "через два дня", expected: day(time.July, 18, 2025)},
+		{name: "in a day", input: "через день", expected: day(time.July, 17, 2025)},
+		{name: "in a week", input: "через неделю", expected: day(time.July, 23, 2025)},
+		{name: "in 2 weeks", input: "через 2 недели", expected: day(time.July, 30, 2025)},
+		{name: "in a month", input: "через месяц", expected: day(time.August, 16, 2025)},
+		{name: "in 3 days in English", input: "in 3 days", expected: day(time.July, 19, 2025)},
+		{name: "on friday", input: "в пятницу", expected: day(time.July, 18, 2025)},
+		{name: "until friday", input: "до пятницы", expected: day(time.July, 18, 2025)},
+		{name: "by monday", input: "к понедельнику", expected: day(time.July, 21, 2025)},
+		{name: "on tuesday", input: "во вторник", expected: day(time.July, 22, 2025)},
+		{name: "abbreviated weekday", input: "пт", expected: day(time.July, 18, 2025)},
+		{name: "this friday", input: "в эту пятницу", expected: day(time.July, 18, 2025)},
+		{name: "this monday has passed", input: "в этот понедельник", hasError: true},
+		{name: "next tuesday", input: "в следующий вторник", expected: day(time.July, 22, 2025)},
+		{name: "next monday", input: "next monday", expected: day(time.July, 21, 2025)},
+		{name: "next wednesday", input: "в следующую среду", expected: day(time.July, 23, 2025)},
+		{
+			name:      "today's weekday",
+			input:     "в среду",
+			ambiguous: []time.Time{day(time.July, 16, 2025), day(time.July, 23, 2025)},
+		},
+		{
+			name:      "next friday within this week",
+			input:     "в следующую пятницу",
+			ambiguous: []time.Time{day(time.July, 18, 2025), day(time.July, 25, 2025)},
+		},
+		{name: "end of week", input: "до конца недели", expected: day(time.July, 20, 2025)},
+		{name: "end of week in English", input: "end of week", expected: day(time.July, 20, 2025)},
+		{name: "end of month", input: "до конца месяца", expected: day(time.July, 31, 2025)},
+		{name: "day and month", input: "20 июля", expected: day(time.July, 20, 2025)},
+		{name: "day and month today", input: "16 июля", expected: day(time.July, 16, 2025)},
+		{name: "day and month already passed", input: "15 июля", expected: day(time.July, 15, 2026)},
+		{name: "nominative month", input: "1 сентябрь", expected: day(time.September, 1, 2025)},
+		{name: "abbreviated month", input: "3 авг.", expected: day(time.August, 3, 2025)},
+		{name: "may", input: "9 мая", expected: day(time.May, 9, 2026)},
+		{name: "with year", input: "1 января 2027", expected: day(time.January, 1, 2027)},
+		{name: "English month first", input: "July 20", expected: day(time.July, 20, 2025)},
+		{name: "English day first", input: "20 july 2026", expected: day(time.July, 20, 2026)},
+		{name: "invalid day of month", input: "31 июня", hasError: true},
+		{name: "unknown word", input: "когда-нибудь", hasError: true},
+		{name: "unknown unit", input: "через 3 попугая", hasError: true},
+		{name: "in 100 years", input: "через 100 лет", expected: day(time.July, 16, 2125)},
+		{name: "too many days", input: "через 100000000 дней", hasError: true},
+		{name: "too many years", input: "через 10000 лет", hasError: true},
+		{name: "more than 100 years in weeks", input: "через 5300 недель", hasError: true},
+		{name: "empty string", input: "", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseNaturalDate(tc.input, now)
+
+			switch {
+			case tc.ambiguous != nil:
+				var ambiguous *AmbiguousDateError
+				require.ErrorAs(t, err, &ambiguous)
+				assert.Equal(t, tc.ambiguous, ambiguous.Options)
+				for _, option := range tc.ambiguous {
+					assert.Contains(t, err.Error(), option.Format("02.01.2006"))
+				}
+			case tc.hasError:
+				assert.Error(t, err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestParseNaturalDate_TimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	// Already Thursday in Tokyo while it is still Wednesday in UTC
+	now := time.Date(2025, 7, 16, 20, 0, 0, 0, time.UTC).In(tokyo)
+
+	result, err := ParseNaturalDate("завтра", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 7, 18, 23, 59, 59, 0, tokyo), result)
+
+	result, err = ParseNaturalDate("в пятницу", now)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 7, 18, 23, 59, 59, 0, tokyo), result)
+}
+
+func TestFormatDateWords(t *testing.T) {
+	date := time.Date(2025, 4, 3, 15, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, "чт, 3 апреля 2025", FormatDateWords(date, false))
+	assert.Equal(t, "чт, 3 апреля 2025 в 15:30", FormatDateWords(date, true))
+}
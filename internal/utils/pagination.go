@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// MaxMessageLength is the maximum length of a Telegram text message
+const MaxMessageLength = 4096
+
+// MessageLength returns the length of the text the way Telegram counts it (UTF-16 code units)
+func MessageLength(text string) int {
+	return len(utf16.Encode([]rune(text)))
+}
+
+// PaginateTaskList splits a list of tasks into pages for display.
+// Each page contains at most perPage tasks and never exceeds maxLength.
+// Task numbering continues across pages.
+func PaginateTaskList(tasks []TaskInfo, title string, perPage, maxLength int) []string {
+	if len(tasks) == 0 {
+		return []string{FormatTaskList(tasks, title)}
+	}
+
+	if perPage <= 0 {
+		perPage = len(tasks)
+	}
+
+	// Reserve room for the worst-case header so that pages never overflow
+	reserved := MessageLength(pageHeader(title, len(tasks), len(tasks))) + 2
+
+	var groups [][]string
+	var current []string
+	currentLength := reserved
+
+	for i, task := range tasks {
+		item := FormatTaskItem(task, i+1)
+		itemLength := MessageLength(item)
+		if len(current) > 0 {
+			itemLength++ // newline separator
+		}
+
+		if len(current) > 0 && (len(current) >= perPage || currentLength+itemLength > maxLength) {
+			groups = append(groups, current)
+			current = nil
+			currentLength = reserved
+			itemLength = MessageLength(item)
+		}
+
+		current = append(current, item)
+		currentLength += itemLength
+	}
+	groups = append(groups, current)
+
+	pages := make([]string, 0, len(groups))
+	for i, group := range groups {
+		header := "📋 " + title
+		if len(groups) > 1 {
+			header = pageHeader(title, i+1, len(groups))
+		}
+		pages = append(pages, header+"\n\n"+strings.Join(group, "\n"))
+	}
+
+	return pages
+}
+
+// pageHeader formats the header of a task list page
+func pageHeader(title string, page, total int) string {
+	return fmt.Sprintf("📋 %s (стр. %d/%d)", title, page, total)
+}
+
+// TruncateText shortens the text to maxLength, adding an ellipsis if it was cut
+func TruncateText(text string, maxLength int) string {
+	if MessageLength(text) <= maxLength {
+		return text
+	}
+
+	runes := []rune(text)
+	length := 0
+	for i, r := range runes {
+		size := utf16.RuneLen(r)
+		if size < 0 {
+			size = 1
+		}
+		// Leave room for the ellipsis
+		if length+size+1 > maxLength {
+			return strings.TrimSpace(string(runes[:i])) + "…"
+		}
+		length += size
+	}
+
+	return text
+}
+
+// SplitMessage packs blocks of text into as few messages as possible.
+// Blocks are separated by an empty line; a block that does not fit into a message is truncated.
+func SplitMessage(blocks []string, maxLength int) []string {
+	var messages []string
+	var current strings.Builder
+
+	for _, block := range blocks {
+		block = TruncateText(block, maxLength)
+
+		if current.Len() > 0 && MessageLength(current.String())+2+MessageLength(block) > maxLength {
+			messages = append(messages, current.String())
+			current.Reset()
+		}
+
+		if current.Len() > 0 {
+			current.WriteString("\n\n")
+		}
+		current.WriteString(block)
+	}
+
+	if current.Len() > 0 {
+		messages = append(messages, current.String())
+	}
+
+	return messages
+}
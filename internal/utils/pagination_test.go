@@ -0,0 +1,97 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTaskInfos(count int, description string) []TaskInfo {
+	tasks := make([]TaskInfo, 0, count)
+	for i := 1; i <= count; i++ {
+		tasks = append(tasks, TaskInfo{
+			ID:          i,
+			Description: fmt.Sprintf("%s %d", description, i),
+			Status:      "active",
+		})
+	}
+	return tasks
+}
+
+func TestMessageLength(t *testing.T) {
+	assert.Equal(t, 5, MessageLength("hello"))
+	assert.Equal(t, 6, MessageLength("привет"))
+	assert.Equal(t, 2, MessageLength("📝"))
+}
+
+func TestPaginateTaskList(t *testing.T) {
+	t.Run("empty list", func(t *testing.T) {
+		pages := PaginateTaskList(nil, "My Tasks", 10, MaxMessageLength)
+		assert.Len(t, pages, 1)
+		assert.Contains(t, pages[0], "❌ Задач не найдено")
+	})
+
+	t.Run("single page has no page counter", func(t *testing.T) {
+		pages := PaginateTaskList(makeTaskInfos(3, "Task"), "My Tasks", 10, MaxMessageLength)
+		assert.Len(t, pages, 1)
+		assert.True(t, strings.HasPrefix(pages[0], "📋 My Tasks\n\n"))
+		assert.NotContains(t, pages[0], "стр.")
+		assert.Contains(t, pages[0], "📝 3. Task 3 (ID: 3)")
+	})
+
+	t.Run("split by items per page", func(t *testing.T) {
+		pages := PaginateTaskList(makeTaskInfos(25, "Task"), "My Tasks", 10, MaxMessageLength)
+		assert.Len(t, pages, 3)
+		assert.Contains(t, pages[0], "📋 My Tasks (стр. 1/3)")
+		assert.Contains(t, pages[1], "📝 11. Task 11 (ID: 11)")
+		assert.Contains(t, pages[2], "📋 My Tasks (стр. 3/3)")
+		assert.Contains(t, pages[2], "📝 25. Task 25 (ID: 25)")
+	})
+
+	t.Run("split by message length", func(t *testing.T) {
+		long := strings.Repeat("задача ", 140)
+		pages := PaginateTaskList(makeTaskInfos(60, long), "My Tasks", 100, MaxMessageLength)
+		assert.Greater(t, len(pages), 1)
+
+		total := 0
+		for _, page := range pages {
+			assert.LessOrEqual(t, MessageLength(page), MaxMessageLength)
+			total += strings.Count(page, "(ID: ")
+		}
+		assert.Equal(t, 60, total)
+	})
+}
+
+func TestTruncateText(t *testing.T) {
+	assert.Equal(t, "short", TruncateText("short", 10))
+	assert.Equal(t, "Купить…", TruncateText("Купить продукты", 8))
+	assert.LessOrEqual(t, MessageLength(TruncateText(strings.Repeat("📝", 10), 7)), 7)
+}
+
+func TestSplitMessage(t *testing.T) {
+	t.Run("fits into one message", func(t *testing.T) {
+		messages := SplitMessage([]string{"first", "second"}, MaxMessageLength)
+		assert.Equal(t, []string{"first\n\nsecond"}, messages)
+	})
+
+	t.Run("split into several messages", func(t *testing.T) {
+		block := strings.Repeat("a", 1500)
+		messages := SplitMessage([]string{block, block, block}, MaxMessageLength)
+		assert.Len(t, messages, 2)
+		for _, message := range messages {
+			assert.LessOrEqual(t, MessageLength(message), MaxMessageLength)
+		}
+	})
+
+	t.Run("long block is truncated", func(t *testing.T) {
+		messages := SplitMessage([]string{strings.Repeat("a", 5000)}, MaxMessageLength)
+		assert.Len(t, messages, 1)
+		assert.Equal(t, MaxMessageLength, MessageLength(messages[0]))
+	})
+
+	t.Run("no blocks", func(t *testing.T) {
+		assert.Empty(t, SplitMessage(nil, MaxMessageLength))
+	})
+}
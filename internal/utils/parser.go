@@ -7,6 +7,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"telegram-bot-assistente/internal/models"
 )
 
 // TaskInput represents parsed input for creating a task
@@ -14,12 +16,46 @@ type TaskInput struct {
 	Description string
 	Deadline    time.Time
 	HasDeadline bool
+	HasTime     bool     // The deadline has a time of day, otherwise it is the end of the day
+	Priority    int      // models.PriorityNone if not given
+	Tags        []string // Normalized tags from "#tag" words
+	Recurrence  *models.Recurrence
+}
+
+// fieldStartRegex matches the start of a "name:" field such as "срок:" or "статус:"
+var fieldStartRegex = regexp.MustCompile(`\s+\p{L}+:`)
+
+// cutField removes the "name: value" field from text. The value may contain spaces
+// ("срок: через 3 дня") and runs up to the next field or the end of the text.
+func cutField(text, name string) (value, rest string, found bool) {
+	start := regexp.MustCompile(`\s+` + regexp.QuoteMeta(name) + `:\s*`).FindStringIndex(text)
+	if start == nil {
+		return "", text, false
+	}
+
+	end := len(text)
+	if next := fieldStartRegex.FindStringIndex(text[start[1]:]); next != nil {
+		end = start[1] + next[0]
+	}
+
+	return strings.TrimSpace(text[start[1]:end]), text[:start[0]] + text[end:], true
+}
+
+// SetField replaces the value of the "name:" field in text or appends the field if it is missing
+func SetField(text, name, value string) string {
+	_, rest, _ := cutField(text, name)
+	return strings.TrimSpace(rest) + " " + name + ": " + value
 }
 
 // ParseAddCommand parses the /add command arguments
 // Expected format: /add "Description" срок: 2025-07-15
-// Alternative formats: /add Description срок: 2025-07-15
-func ParseAddCommand(text string) (*TaskInput, error) {
+// Alternative formats: /add Description срок: 2025-07-15 15:30
+// A recurring task: /add Weekly report повтор: каждый понедельник (see ParseRecurrence)
+// A priority: /add Report !2 or /add Report приоритет: высокий (see ParsePriority)
+// Tags: /add Report #work #q3; "#tag" words are removed from the description
+// now is the current time in the user's time zone and order is the user's date order
+// (see ParseDate); the deadline is interpreted with both.
+func ParseAddCommand(text string, now time.Time, order string) (*TaskInput, error) {
 	if strings.TrimSpace(text) == "" {
 		return nil, errors.New("empty command text")
 	}
@@ -34,36 +70,61 @@ func ParseAddCommand(text string) (*TaskInput, error) {
 		return nil, errors.New("missing task description")
 	}
 
-	// Check if there's a deadline specification
-	deadlineRegex := regexp.MustCompile(`\s+срок:\s*(\S+)`)
-	matches := deadlineRegex.FindStringSubmatch(text)
-
 	input := &TaskInput{}
 
-	if len(matches) > 1 {
-		// Parse deadline
-		deadlineStr := matches[1]
-		deadline, err := ParseDate(deadlineStr)
+	// The priority mark goes first, so that it is not read as a part of the deadline
+	priority, rest, found, err := cutPriority(" " + text)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		input.Priority = priority
+		text = strings.TrimSpace(rest)
+	}
+
+	tags, rest, err := cutTags(text)
+	if err != nil {
+		return nil, err
+	}
+	input.Tags = tags
+	text = strings.TrimSpace(rest)
+
+	// Check if there's a deadline specification and remove it from the description
+	if deadlineStr, rest, found := cutField(text, "срок"); found {
+		deadline, hasTime, err := ParseDeadline(deadlineStr, now, order)
 		if err != nil {
 			return nil, err
 		}
 		input.Deadline = deadline
 		input.HasDeadline = true
+		input.HasTime = hasTime
+		text = rest
+	}
+
+	if recurrenceStr, rest, found := cutField(text, "повтор"); found {
+		recurrence, err := ParseRecurrence(recurrenceStr)
+		if err != nil {
+			return nil, err
+		}
 
-		// Remove deadline part from description
-		text = deadlineRegex.ReplaceAllString(text, "")
+		// Without a deadline the series starts with the first matching day from today
+		if !input.HasDeadline {
+			today := endOfDay(now)
+			recurrence.Anchor(today)
+			input.Deadline = recurrence.First(today)
+			input.HasDeadline = true
+		}
+		recurrence.Anchor(input.Deadline)
+
+		input.Recurrence = recurrence
+		text = rest
 	}
 
 	// Clean up description
 	description := strings.TrimSpace(text)
 
 	// Remove quotes if present
-	if (strings.HasPrefix(description, `"`) && strings.HasSuffix(description, `"`)) ||
-		(strings.HasPrefix(description, `'`) && strings.HasSuffix(description, `'`)) {
-		description = description[1 : len(description)-1]
-	}
-
-	description = strings.TrimSpace(description)
+	description = trimQuotes(description)
 	if description == "" {
 		return nil, errors.New("task description cannot be empty")
 	}
@@ -72,31 +133,352 @@ func ParseAddCommand(text string) (*TaskInput, error) {
 	return input, nil
 }
 
-// ParseDate parses date from various formats
-func ParseDate(dateStr string) (time.Time, error) {
+// EditInput represents parsed input for editing a task
+type EditInput struct {
+	TaskID        int
+	Description   string
+	Deadline      time.Time
+	HasDeadline   bool
+	HasTime       bool
+	ClearDeadline bool
+	Status        string
+	Priority      int
+	HasPriority   bool // Priority is set, models.PriorityNone clears it
+	Tags          []string
+	HasTags       bool // Tags replace the tags of the task, none clears them
+}
+
+// ParseEditCommand parses the /edit command arguments
+// Expected format: /edit 3 "New description" срок: 2025-07-15 статус: done приоритет: высокий
+// Every part except the ID is optional, but at least one change is required.
+// Use "срок: -" to clear the deadline and "приоритет: -" to clear the priority.
+// "#tag" words replace the tags of the task and "теги: -" clears them. The deadline is interpreted in the zone of now
+// and the date order as in ParseAddCommand.
+func ParseEditCommand(text string, now time.Time, order string) (*EditInput, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "/edit") {
+		text = strings.TrimSpace(text[5:])
+	}
+
+	if text == "" {
+		return nil, errors.New("missing task ID")
+	}
+
+	idStr, rest, _ := strings.Cut(text, " ")
+	id, err := ParseTaskID(idStr)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &EditInput{TaskID: id}
+
+	// Prepend a space so that "срок:" right after the ID is matched too
+	rest = " " + rest
+
+	statusRegex := regexp.MustCompile(`\s+статус:\s*(\S+)`)
+	if matches := statusRegex.FindStringSubmatch(rest); len(matches) > 1 {
+		status, err := ParseStatus(matches[1])
+		if err != nil {
+			return nil, err
+		}
+		input.Status = status
+		rest = statusRegex.ReplaceAllString(rest, "")
+	}
+
+	priority, withoutPriority, found, err := cutPriority(rest)
+	if err != nil {
+		return nil, err
+	}
+	if found {
+		input.Priority = priority
+		input.HasPriority = true
+		rest = withoutPriority
+	}
+
+	if tagsStr, withoutTags, found := cutField(rest, "теги"); found {
+		if tagsStr != "-" {
+			return nil, errors.New(`use #tag words to set tags and "теги: -" to clear them`)
+		}
+		input.HasTags = true
+		rest = withoutTags
+	}
+
+	tags, withoutTags, err := cutTags(rest)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		input.Tags = tags
+		input.HasTags = true
+		rest = withoutTags
+	}
+
+	if deadlineStr, withoutDeadline, found := cutField(rest, "срок"); found {
+		if deadlineStr == "-" {
+			input.ClearDeadline = true
+		} else {
+			deadline, hasTime, err := ParseDeadline(deadlineStr, now, order)
+			if err != nil {
+				return nil, err
+			}
+			input.Deadline = deadline
+			input.HasDeadline = true
+			input.HasTime = hasTime
+		}
+		rest = withoutDeadline
+	}
+
+	input.Description = trimQuotes(strings.TrimSpace(rest))
+
+	if input.Description == "" && !input.HasDeadline && !input.ClearDeadline && input.Status == "" && !input.HasPriority && !input.HasTags {
+		return nil, errors.New("nothing to change: specify a description, срок:, статус:, приоритет: or #tags")
+	}
+
+	return input, nil
+}
+
+// PostponeInput represents parsed input for postponing a task
+type PostponeInput struct {
+	TaskID   int
+	Until    time.Time // Zero when the task is postponed indefinitely
+	HasTime  bool      // Until has a time of day, otherwise only the day was given
+	HasUntil bool
+}
+
+// ParsePostponeCommand parses the /postpone command arguments
+// Expected format: /postpone 3 до: завтра в 10:00
+// Without "до:" the task is postponed indefinitely. The date is read like a deadline
+// (see ParseDeadline) in the zone of now and the user's date order.
+func ParsePostponeCommand(text string, now time.Time, order string) (*PostponeInput, error) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "/postpone") {
+		text = strings.TrimSpace(text[len("/postpone"):])
+	}
+
+	if text == "" {
+		return nil, errors.New("missing task ID")
+	}
+
+	idStr, rest, _ := strings.Cut(text, " ")
+	id, err := ParseTaskID(idStr)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &PostponeInput{TaskID: id}
+
+	// Prepend a space so that "до:" right after the ID is matched too
+	untilStr, rest, found := cutField(" "+rest, "до")
+	if strings.TrimSpace(rest) != "" {
+		return nil, errors.New("unexpected text after the task ID, use до: to set the date")
+	}
+	if !found {
+		return input, nil
+	}
+
+	until, hasTime, err := ParseDeadline(untilStr, now, order)
+	if err != nil {
+		return nil, err
+	}
+
+	input.Until = until
+	input.HasTime = hasTime
+	input.HasUntil = true
+	return input, nil
+}
+
+// ParseStatus parses a task status in English or Russian
+func ParseStatus(statusStr string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(statusStr)) {
+	case "active", "активна", "активная", "активно":
+		return "active", nil
+	case "done", "выполнена", "выполнено", "готово":
+		return "done", nil
+	case "postponed", "отложена", "отложено":
+		return "postponed", nil
+	default:
+		return "", errors.New("invalid status. Supported statuses: active, done, postponed")
+	}
+}
+
+// trimQuotes removes surrounding quotes from the text if present
+func trimQuotes(text string) string {
+	if len(text) >= 2 &&
+		((strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`)) ||
+			(strings.HasPrefix(text, `'`) && strings.HasSuffix(text, `'`))) {
+		text = text[1 : len(text)-1]
+	}
+	return strings.TrimSpace(text)
+}
+
+// numericDateRegex matches numeric dates: 15.07.2025, 07/15/2025, 2025-07-15 and 15.07 without a year
+var numericDateRegex = regexp.MustCompile(`^(\d{1,4})([./-])(\d{1,2})(?:[./-](\d{1,4}))?$`)
+
+// ParseDate parses a numeric date as the end of that day in the zone of now.
+// A date starting with the year is always YYYY-MM-DD. Otherwise day and month are read
+// in the given order (models.DateOrderDMY or models.DateOrderMDY); a date that is only
+// valid the other way round is read that way. Otherwise dotted dates are
+// DD.MM, and dates like 03/04/2025 that mean different days as DD/MM and MM/DD are
+// reported as an *AmbiguousDateError. A date without a year is the nearest such date.
+func ParseDate(dateStr string, now time.Time, order string) (time.Time, error) {
 	dateStr = strings.TrimSpace(dateStr)
 	if dateStr == "" {
 		return time.Time{}, errors.New("empty date string")
 	}
 
-	// List of supported date formats
-	formats := []string{
-		"2006-01-02", // YYYY-MM-DD
-		"02.01.2006", // DD.MM.YYYY
-		"02/01/2006", // DD/MM/YYYY
-		"2006/01/02", // YYYY/MM/DD
-		"02-01-2006", // DD-MM-YYYY
-		"01/02/2006", // MM/DD/YYYY (US format)
+	invalid := errors.New("invalid date format. Supported formats: YYYY-MM-DD, DD.MM.YYYY, DD/MM/YYYY, DD.MM")
+
+	matches := numericDateRegex.FindStringSubmatch(dateStr)
+	if matches == nil {
+		return time.Time{}, invalid
+	}
+	first, separator, second, last := matches[1], matches[2], matches[3], matches[4]
+
+	switch {
+	case len(first) == 4 && last != "" && len(last) <= 2:
+		// YYYY-MM-DD
+		month, _ := strconv.Atoi(second)
+		day, _ := strconv.Atoi(last)
+		if date, ok := calendarDate(first, time.Month(month), day, now); ok {
+			return date, nil
+		}
+		return time.Time{}, invalid
+	case len(first) > 2 || (last != "" && len(last) != 4):
+		return time.Time{}, invalid
+	}
+
+	a, _ := strconv.Atoi(first)
+	b, _ := strconv.Atoi(second)
+	dayFirst, dayFirstOK := calendarDate(last, time.Month(b), a, now)
+	monthFirst, monthFirstOK := calendarDate(last, time.Month(a), b, now)
+
+	switch {
+	case !dayFirstOK && !monthFirstOK:
+		return time.Time{}, invalid
+	case !monthFirstOK || dayFirst.Equal(monthFirst):
+		return dayFirst, nil
+	case !dayFirstOK:
+		return monthFirst, nil
+	}
+
+	switch {
+	case order == models.DateOrderMDY:
+		return monthFirst, nil
+	case order == models.DateOrderDMY, separator == ".":
+		return dayFirst, nil
+	default:
+		return time.Time{}, &AmbiguousDateError{Input: dateStr, Options: []time.Time{dayFirst, monthFirst}}
+	}
+}
+
+// calendarDate builds the end of the given day in the zone of now and reports whether
+// such a day exists. Without a year it is the nearest such date not earlier than today.
+func calendarDate(yearStr string, month time.Month, day int, now time.Time) (time.Time, bool) {
+	year := now.Year()
+	if yearStr != "" {
+		year, _ = strconv.Atoi(yearStr)
+	}
+
+	date := time.Date(year, month, day, 23, 59, 59, 0, now.Location())
+	if month < time.January || month > time.December || date.Day() != day {
+		return time.Time{}, false
+	}
+
+	if yearStr == "" && date.Before(now) {
+		date = date.AddDate(1, 0, 0)
+	}
+
+	return date, true
+}
+
+// dateTimeFormats are ISO datetimes accepted as a whole
+var dateTimeFormats = []string{
+	"2006-01-02T15:04:05", // ISO 8601 without a zone
+	"2006-01-02T15:04",
+}
+
+// dateWithTimeRegex splits "15.07 в 15:30" or "завтра в 9:00" into the date and the time of day
+var dateWithTimeRegex = regexp.MustCompile(`^(.+?)\s+(?:в\s+)?(\d{1,2}:\d{2})$`)
+
+// ParseDeadline parses a deadline with an optional time of day.
+// hasTime is false when only a date was given; the deadline is then the end of that day.
+// It accepts the ParseDate formats, read in the user's date order, dates in words
+// (see ParseNaturalDate), a time after the date ("2025-07-15 15:30", "завтра в 15:30")
+// and ISO datetimes. A date that may mean several days is an *AmbiguousDateError.
+// now is the current time in the user's time zone: dates and times are read in that zone.
+func ParseDeadline(text string, now time.Time, order string) (deadline time.Time, hasTime bool, err error) {
+	loc := now.Location()
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, false, errors.New("empty date string")
+	}
+
+	if parsed, err := time.Parse(time.RFC3339, text); err == nil {
+		return parsed.In(loc), true, nil
+	}
+	for _, format := range dateTimeFormats {
+		if parsed, err := time.ParseInLocation(format, text, loc); err == nil {
+			return parsed, true, nil
+		}
+	}
+
+	dateStr, clockStr := text, ""
+	if matches := dateWithTimeRegex.FindStringSubmatch(text); matches != nil {
+		dateStr, clockStr = matches[1], matches[2]
 	}
 
-	for _, format := range formats {
-		if parsed, err := time.Parse(format, dateStr); err == nil {
-			// Set time to end of day to give user full day to complete
-			return time.Date(parsed.Year(), parsed.Month(), parsed.Day(), 23, 59, 59, 0, time.Local), nil
+	date, dateErr := parseDeadlineDate(dateStr, now, order)
+	var ambiguous *AmbiguousDateError
+	if dateErr != nil && !errors.As(dateErr, &ambiguous) {
+		return time.Time{}, false, dateErr
+	}
+
+	if clockStr == "" {
+		return date, false, dateErr
+	}
+
+	clock, err := time.Parse("15:04", clockStr)
+	if err != nil {
+		return time.Time{}, false, errors.New("invalid time format. Use HH:MM, e.g. 15:30")
+	}
+
+	atClock := func(date time.Time) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	}
+
+	if ambiguous != nil {
+		// Offer the options with the given time so that the choice is a complete deadline
+		options := make([]time.Time, len(ambiguous.Options))
+		for i, option := range ambiguous.Options {
+			options[i] = atClock(option)
 		}
+		return time.Time{}, false, &AmbiguousDateError{Input: text, Options: options, HasTime: true}
 	}
 
-	return time.Time{}, errors.New("invalid date format. Supported formats: YYYY-MM-DD, DD.MM.YYYY, DD/MM/YYYY")
+	return atClock(date), true, nil
+}
+
+// parseDeadlineDate parses the date part of a deadline: numeric formats first, then words
+func parseDeadlineDate(dateStr string, now time.Time, order string) (time.Time, error) {
+	if numericDateRegex.MatchString(dateStr) {
+		return ParseDate(dateStr, now, order)
+	}
+
+	date, err := ParseNaturalDate(dateStr, now)
+	if errors.Is(err, errUnrecognizedDate) {
+		return time.Time{}, errors.New("invalid date format. Supported formats: YYYY-MM-DD, DD.MM.YYYY, DD.MM, " +
+			"завтра, через 3 дня, в пятницу, 15 июля, optionally with a time: 15.07 в 15:30")
+	}
+	return date, err
+}
+
+// FormatDeadline formats a deadline for display, with the time only for timed deadlines.
+// The deadline is shown in the zone it carries; convert it to the user's zone first.
+func FormatDeadline(deadline time.Time, hasTime bool) string {
+	if hasTime {
+		return deadline.Format("02.01.2006 15:04")
+	}
+	return deadline.Format("02.01.2006")
 }
 
 // ParseTaskID parses task ID from string
@@ -118,6 +500,94 @@ func ParseTaskID(idStr string) (int, error) {
 	return id, nil
 }
 
+// MaxTaskIDRange limits how many IDs a single range like "3-9" may expand to
+const MaxTaskIDRange = 100
+
+// MaxTaskIDs limits how many distinct IDs a single command may list, ranges included
+const MaxTaskIDs = 100
+
+// ParseTaskIDs parses a list of task IDs and ranges (e.g. "3", "5", "7-9").
+// Duplicates are removed while preserving the original order.
+func ParseTaskIDs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no task IDs given")
+	}
+
+	ids := make([]int, 0, len(args))
+	seen := make(map[int]bool)
+	add := func(id int) error {
+		if seen[id] {
+			return nil
+		}
+		if len(ids) == MaxTaskIDs {
+			return fmt.Errorf("too many task IDs (maximum %d per command)", MaxTaskIDs)
+		}
+		seen[id] = true
+		ids = append(ids, id)
+		return nil
+	}
+
+	for _, arg := range args {
+		from, to, isRange := strings.Cut(arg, "-")
+		if !isRange || from == "" {
+			id, err := ParseTaskID(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", arg, err)
+			}
+			if err := add(id); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		start, err := ParseTaskID(from)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+		end, err := ParseTaskID(to)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", arg, err)
+		}
+
+		if start > end {
+			return nil, fmt.Errorf("%s: range start is greater than end", arg)
+		}
+		if end-start+1 > MaxTaskIDRange {
+			return nil, fmt.Errorf("%s: range is too large (maximum %d tasks)", arg, MaxTaskIDRange)
+		}
+
+		for id := start; id <= end; id++ {
+			if err := add(id); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// taskLineRegex matches the task line of bot messages about a task: "📝 ID: 3" in
+// confirmations and "📝 Description (ID: 3)" in reminders
+var taskLineRegex = regexp.MustCompile(`(?m)^📝 (?:ID: (\d+)|.*\(ID: (\d+)\))$`)
+
+// ExtractTaskID finds the task of a bot message about a task by its task line.
+// It succeeds only if the text is about exactly one task.
+func ExtractTaskID(text string) (int, bool) {
+	found := 0
+	for _, match := range taskLineRegex.FindAllStringSubmatch(text, -1) {
+		id, err := strconv.Atoi(match[1] + match[2])
+		if err != nil || id <= 0 {
+			continue
+		}
+		if found != 0 && found != id {
+			return 0, false
+		}
+		found = id
+	}
+
+	return found, found != 0
+}
+
 // ValidateDescription validates task description
 func ValidateDescription(description string) error {
 	description = strings.TrimSpace(description)
@@ -158,8 +628,14 @@ type TaskInfo struct {
 	Description string
 	Deadline    time.Time
 	HasDeadline bool
+	HasTime     bool
 	Status      string
+	Priority    int
+	Tags        []string
 	IsOverdue   bool
+	Recurrence  string // RRULE of a recurring task
+	// PostponedUntil is when a postponed task becomes active again, zero if it is postponed indefinitely
+	PostponedUntil time.Time
 }
 
 // FormatTaskItem formats a single task for display
@@ -179,11 +655,11 @@ func FormatTaskItem(task TaskInfo, number int) string {
 		}
 	}
 
-	builder.WriteString(fmt.Sprintf("%s %d. %s (ID: %d)", statusEmoji, number, task.Description, task.ID))
+	builder.WriteString(fmt.Sprintf("%s %d. %s%s (ID: %d)", statusEmoji, number, priorityMarker(task.Priority), task.Description, task.ID))
 
 	// Add deadline info
 	if task.HasDeadline {
-		deadlineStr := task.Deadline.Format("02.01.2006")
+		deadlineStr := FormatDeadline(task.Deadline, task.HasTime)
 		if task.IsOverdue && task.Status == "active" {
 			builder.WriteString(fmt.Sprintf("\n   ⏰ Срок: %s ❗ ПРОСРОЧЕНО", deadlineStr))
 		} else {
@@ -191,6 +667,18 @@ func FormatTaskItem(task TaskInfo, number int) string {
 		}
 	}
 
+	if task.Status == "postponed" && !task.PostponedUntil.IsZero() {
+		builder.WriteString(fmt.Sprintf("\n   ⏸️ Отложено до: %s", FormatDeadline(task.PostponedUntil, true)))
+	}
+
+	if len(task.Tags) > 0 {
+		builder.WriteString(fmt.Sprintf("\n   🏷 %s", FormatTags(task.Tags)))
+	}
+
+	if task.Recurrence != "" {
+		builder.WriteString(fmt.Sprintf("\n   🔁 Повтор: %s", FormatRecurrence(task.Recurrence)))
+	}
+
 	return builder.String()
 }
 
@@ -1,30 +1,33 @@
 package utils
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
+	"telegram-bot-assistente/internal/models"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestParseAddCommand(t *testing.T) {
 	t.Run("simple description without deadline", func(t *testing.T) {
-		input, err := ParseAddCommand("/add Buy groceries")
+		input, err := ParseAddCommand("/add Buy groceries", time.Now(), "")
 		require.NoError(t, err)
 		assert.Equal(t, "Buy groceries", input.Description)
 		assert.False(t, input.HasDeadline)
 	})
 
 	t.Run("quoted description without deadline", func(t *testing.T) {
-		input, err := ParseAddCommand(`/add "Buy groceries and cook dinner"`)
+		input, err := ParseAddCommand(`/add "Buy groceries and cook dinner"`, time.Now(), "")
 		require.NoError(t, err)
 		assert.Equal(t, "Buy groceries and cook dinner", input.Description)
 		assert.False(t, input.HasDeadline)
 	})
 
 	t.Run("description with deadline", func(t *testing.T) {
-		input, err := ParseAddCommand("/add Buy groceries срок: 2025-07-15")
+		input, err := ParseAddCommand("/add Buy groceries срок: 2025-07-15", time.Now(), "")
 		require.NoError(t, err)
 		assert.Equal(t, "Buy groceries", input.Description)
 		assert.True(t, input.HasDeadline)
@@ -33,79 +36,369 @@ func TestParseAddCommand(t *testing.T) {
 		assert.Equal(t, 15, input.Deadline.Day())
 	})
 
+	t.Run("description with deadline and time", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Team call срок: 2025-07-15 15:30", time.Now(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "Team call", input.Description)
+		assert.True(t, input.HasDeadline)
+		assert.True(t, input.HasTime)
+		assert.Equal(t, time.Date(2025, 7, 15, 15, 30, 0, 0, time.Local), input.Deadline)
+	})
+
+	t.Run("deadline in words", func(t *testing.T) {
+		now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.Local)
+		input, err := ParseAddCommand(`/add "Weekly report" срок: до конца недели`, now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Weekly report", input.Description)
+		assert.Equal(t, time.Date(2025, 7, 20, 23, 59, 59, 0, time.Local), input.Deadline)
+	})
+
+	t.Run("time with в", func(t *testing.T) {
+		input, err := ParseAddCommand(`/add "Team call" срок: 15.07.2025 в 9:05`, time.Now(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "Team call", input.Description)
+		assert.True(t, input.HasTime)
+		assert.Equal(t, time.Date(2025, 7, 15, 9, 5, 0, 0, time.Local), input.Deadline)
+	})
+
 	t.Run("quoted description with deadline", func(t *testing.T) {
-		input, err := ParseAddCommand(`/add "Buy groceries and cook dinner" срок: 2025-07-15`)
+		input, err := ParseAddCommand(`/add "Buy groceries and cook dinner" срок: 2025-07-15`, time.Now(), "")
 		require.NoError(t, err)
 		assert.Equal(t, "Buy groceries and cook dinner", input.Description)
 		assert.True(t, input.HasDeadline)
 	})
 
 	t.Run("without /add prefix", func(t *testing.T) {
-		input, err := ParseAddCommand(`"Complete project" срок: 2025-08-01`)
+		input, err := ParseAddCommand(`"Complete project" срок: 2025-08-01`, time.Now(), "")
 		require.NoError(t, err)
 		assert.Equal(t, "Complete project", input.Description)
 		assert.True(t, input.HasDeadline)
 	})
 
 	t.Run("empty command", func(t *testing.T) {
-		_, err := ParseAddCommand("")
+		_, err := ParseAddCommand("", time.Now(), "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "empty command text")
 	})
 
 	t.Run("only /add command", func(t *testing.T) {
-		_, err := ParseAddCommand("/add")
+		_, err := ParseAddCommand("/add", time.Now(), "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "missing task description")
 	})
 
 	t.Run("empty description", func(t *testing.T) {
-		_, err := ParseAddCommand(`/add "" срок: 2025-07-15`)
+		_, err := ParseAddCommand(`/add "" срок: 2025-07-15`, time.Now(), "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "task description cannot be empty")
 	})
 
 	t.Run("invalid deadline format", func(t *testing.T) {
-		_, err := ParseAddCommand("/add Buy groceries срок: invalid-date")
+		_, err := ParseAddCommand("/add Buy groceries срок: invalid-date", time.Now(), "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid date format")
+	})
+}
+
+func TestParseEditCommand(t *testing.T) {
+	t.Run("description only", func(t *testing.T) {
+		input, err := ParseEditCommand(`/edit 2 "Buy groceries and cook dinner"`, time.Now(), "")
+		require.NoError(t, err)
+		assert.Equal(t, 2, input.TaskID)
+		assert.Equal(t, "Buy groceries and cook dinner", input.Description)
+		assert.False(t, input.HasDeadline)
+		assert.False(t, input.ClearDeadline)
+		assert.Empty(t, input.Status)
+	})
+
+	t.Run("deadline only", func(t *testing.T) {
+		input, err := ParseEditCommand("/edit 2 срок: 2025-07-21", time.Now(), "")
+		require.NoError(t, err)
+		assert.Empty(t, input.Description)
+		assert.True(t, input.HasDeadline)
+		assert.Equal(t, 21, input.Deadline.Day())
+	})
+
+	t.Run("description and deadline", func(t *testing.T) {
+		input, err := ParseEditCommand("/edit 2 Buy groceries срок: 21.07.2025", time.Now(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "Buy groceries", input.Description)
+		assert.True(t, input.HasDeadline)
+	})
+
+	t.Run("deadline with time and status", func(t *testing.T) {
+		input, err := ParseEditCommand("/edit 2 срок: 21.07.2025 в 18:00 статус: active", time.Now(), "")
+		require.NoError(t, err)
+		assert.Empty(t, input.Description)
+		assert.True(t, input.HasTime)
+		assert.Equal(t, 18, input.Deadline.Hour())
+		assert.Equal(t, "active", input.Status)
+	})
+
+	t.Run("clear deadline", func(t *testing.T) {
+		input, err := ParseEditCommand("/edit 2 срок: -", time.Now(), "")
+		require.NoError(t, err)
+		assert.True(t, input.ClearDeadline)
+		assert.False(t, input.HasDeadline)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		input, err := ParseEditCommand("/edit 2 статус: отложена", time.Now(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "postponed", input.Status)
+		assert.Empty(t, input.Description)
+	})
+
+	t.Run("all fields", func(t *testing.T) {
+		input, err := ParseEditCommand("/edit 2 New text срок: 2025-07-21 статус: active", time.Now(), "")
+		require.NoError(t, err)
+		assert.Equal(t, "New text", input.Description)
+		assert.True(t, input.HasDeadline)
+		assert.Equal(t, "active", input.Status)
+	})
+
+	t.Run("missing ID", func(t *testing.T) {
+		_, err := ParseEditCommand("/edit", time.Now(), "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "missing task ID")
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		_, err := ParseEditCommand("/edit abc New text", time.Now(), "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid task ID format")
+	})
+
+	t.Run("nothing to change", func(t *testing.T) {
+		_, err := ParseEditCommand("/edit 2", time.Now(), "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "nothing to change")
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := ParseEditCommand("/edit 2 статус: unknown", time.Now(), "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid status")
+	})
+
+	t.Run("invalid deadline", func(t *testing.T) {
+		_, err := ParseEditCommand("/edit 2 срок: someday", time.Now(), "")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid date format")
 	})
 }
 
+func TestParsePostponeCommand(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.Local)
+
+	t.Run("indefinitely", func(t *testing.T) {
+		input, err := ParsePostponeCommand("/postpone 3", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, 3, input.TaskID)
+		assert.False(t, input.HasUntil)
+		assert.True(t, input.Until.IsZero())
+	})
+
+	t.Run("until a day", func(t *testing.T) {
+		input, err := ParsePostponeCommand("/postpone 3 до: завтра", now, "")
+		require.NoError(t, err)
+		assert.True(t, input.HasUntil)
+		assert.False(t, input.HasTime)
+		assert.Equal(t, 16, input.Until.Day())
+	})
+
+	t.Run("until a time", func(t *testing.T) {
+		input, err := ParsePostponeCommand("/postpone 3 до: 20.07 в 10:00", now, "")
+		require.NoError(t, err)
+		assert.True(t, input.HasTime)
+		assert.Equal(t, time.Date(2025, 7, 20, 10, 0, 0, 0, time.Local), input.Until)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, text := range []string{"/postpone", "/postpone abc", "/postpone 3 завтра", "/postpone 3 до: someday"} {
+			_, err := ParsePostponeCommand(text, now, "")
+			assert.Error(t, err, text)
+		}
+	})
+
+	t.Run("ambiguous date", func(t *testing.T) {
+		_, err := ParsePostponeCommand("/postpone 3 до: 03/04/2026", now, "")
+		var ambiguous *AmbiguousDateError
+		assert.ErrorAs(t, err, &ambiguous)
+	})
+}
+
+func TestParseStatus(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"active", "active"},
+		{"Активна", "active"},
+		{"done", "done"},
+		{"выполнена", "done"},
+		{"postponed", "postponed"},
+		{"отложена", "postponed"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			status, err := ParseStatus(tc.input)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, status)
+		})
+	}
+
+	_, err := ParseStatus("unknown")
+	assert.Error(t, err)
+}
+
 func TestParseDate(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+	day := func(month time.Month, d, year int) time.Time {
+		return time.Date(year, month, d, 23, 59, 59, 0, time.Local)
+	}
+
+	testCases := []struct {
+		name      string
+		input     string
+		order     string
+		expected  time.Time
+		ambiguous []time.Time
+		hasError  bool
+	}{
+		{name: "YYYY-MM-DD format", input: "2025-07-15", expected: day(time.July, 15, 2025)},
+		{name: "YYYY/MM/DD format", input: "2025/07/15", expected: day(time.July, 15, 2025)},
+		{name: "YYYY-MM-DD ignores the order", input: "2025-04-03", order: models.DateOrderMDY, expected: day(time.April, 3, 2025)},
+		{name: "DD.MM.YYYY format", input: "15.07.2025", expected: day(time.July, 15, 2025)},
+		{name: "DD/MM/YYYY format", input: "15/07/2025", expected: day(time.July, 15, 2025)},
+		{name: "MM/DD/YYYY format", input: "07/15/2025", expected: day(time.July, 15, 2025)},
+		{name: "same day both ways", input: "05/05/2025", expected: day(time.May, 5, 2025)},
+		{name: "dots are day first", input: "03.04.2025", expected: day(time.April, 3, 2025)},
+		{
+			name:      "slashes are ambiguous",
+			input:     "03/04/2025",
+			ambiguous: []time.Time{day(time.April, 3, 2025), day(time.March, 4, 2025)},
+		},
+		{
+			name:      "dashes are ambiguous",
+			input:     "03-04-2025",
+			ambiguous: []time.Time{day(time.April, 3, 2025), day(time.March, 4, 2025)},
+		},
+		{name: "day first order", input: "03/04/2025", order: models.DateOrderDMY, expected: day(time.April, 3, 2025)},
+		{name: "month first order", input: "03/04/2025", order: models.DateOrderMDY, expected: day(time.March, 4, 2025)},
+		{name: "month first order with dots", input: "03.04.2025", order: models.DateOrderMDY, expected: day(time.March, 4, 2025)},
+		{name: "only valid day first", input: "15/07/2025", order: models.DateOrderMDY, expected: day(time.July, 15, 2025)},
+		{name: "only valid month first", input: "07/15/2025", order: models.DateOrderDMY, expected: day(time.July, 15, 2025)},
+		{name: "year first order reads D/M/Y like no order", input: "03.04.2025", order: models.DateOrderYMD, expected: day(time.April, 3, 2025)},
+		{
+			name:      "year first order keeps slashes ambiguous",
+			input:     "03/04/2025",
+			order:     models.DateOrderYMD,
+			ambiguous: []time.Time{day(time.April, 3, 2025), day(time.March, 4, 2025)},
+		},
+		{name: "without a year", input: "15.07", expected: day(time.July, 15, 2025)},
+		{
+			name:      "without a year ambiguous",
+			input:     "02/03",
+			ambiguous: []time.Time{day(time.March, 2, 2025), day(time.February, 3, 2026)},
+		},
+		{name: "invalid both ways", input: "31/31/2025", hasError: true},
+		{name: "invalid day", input: "2025-02-30", hasError: true},
+		{name: "two-digit year", input: "03.04.25", hasError: true},
+		{name: "invalid format", input: "invalid-date", hasError: true},
+		{name: "empty string", input: "", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseDate(tc.input, now, tc.order)
+
+			switch {
+			case tc.ambiguous != nil:
+				var ambiguous *AmbiguousDateError
+				require.ErrorAs(t, err, &ambiguous)
+				assert.Equal(t, tc.ambiguous, ambiguous.Options)
+			case tc.hasError:
+				assert.Error(t, err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, result)
+			}
+		})
+	}
+
+	t.Run("without a year is the nearest date", func(t *testing.T) {
+		now := time.Date(2025, 7, 20, 12, 0, 0, 0, time.Local)
+
+		date, err := ParseDate("15.08", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 8, 15, 23, 59, 59, 0, time.Local), date)
+
+		date, err = ParseDate("20.07", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 7, 20, 23, 59, 59, 0, time.Local), date)
+
+		date, err = ParseDate("15.07", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 7, 15, 23, 59, 59, 0, time.Local), date)
+
+		_, err = ParseDate("32.07", now, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseDeadline(t *testing.T) {
 	testCases := []struct {
 		name     string
 		input    string
 		expected time.Time
+		hasTime  bool
 		hasError bool
 	}{
 		{
-			name:     "YYYY-MM-DD format",
+			name:     "date only is the end of the day",
 			input:    "2025-07-15",
 			expected: time.Date(2025, 7, 15, 23, 59, 59, 0, time.Local),
-			hasError: false,
 		},
 		{
-			name:     "DD.MM.YYYY format",
-			input:    "15.07.2025",
-			expected: time.Date(2025, 7, 15, 23, 59, 59, 0, time.Local),
-			hasError: false,
+			name:     "date and time",
+			input:    "2025-07-15 15:30",
+			expected: time.Date(2025, 7, 15, 15, 30, 0, 0, time.Local),
+			hasTime:  true,
 		},
 		{
-			name:     "DD/MM/YYYY format",
-			input:    "15/07/2025",
-			expected: time.Date(2025, 7, 15, 23, 59, 59, 0, time.Local),
-			hasError: false,
+			name:     "DD.MM.YYYY в HH:MM",
+			input:    "15.07.2025 в 15:30",
+			expected: time.Date(2025, 7, 15, 15, 30, 0, 0, time.Local),
+			hasTime:  true,
 		},
 		{
-			name:     "YYYY/MM/DD format",
-			input:    "2025/07/15",
-			expected: time.Date(2025, 7, 15, 23, 59, 59, 0, time.Local),
-			hasError: false,
+			name:     "ISO datetime",
+			input:    "2025-07-15T15:30",
+			expected: time.Date(2025, 7, 15, 15, 30, 0, 0, time.Local),
+			hasTime:  true,
+		},
+		{
+			name:     "ISO datetime with seconds",
+			input:    "2025-07-15T15:30:45",
+			expected: time.Date(2025, 7, 15, 15, 30, 45, 0, time.Local),
+			hasTime:  true,
+		},
+		{
+			name:     "RFC3339 with zone",
+			input:    "2025-07-15T12:30:00Z",
+			expected: time.Date(2025, 7, 15, 12, 30, 0, 0, time.UTC),
+			hasTime:  true,
 		},
 		{
-			name:     "invalid format",
-			input:    "invalid-date",
+			name:     "invalid time",
+			input:    "2025-07-15 25:00",
+			hasError: true,
+		},
+		{
+			name:     "invalid date",
+			input:    "someday 15:30",
 			hasError: true,
 		},
 		{
@@ -117,20 +410,90 @@ func TestParseDate(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result, err := ParseDate(tc.input)
+			deadline, hasTime, err := ParseDeadline(tc.input, time.Now(), "")
 			if tc.hasError {
 				assert.Error(t, err)
-			} else {
-				require.NoError(t, err)
-				assert.Equal(t, tc.expected.Year(), result.Year())
-				assert.Equal(t, tc.expected.Month(), result.Month())
-				assert.Equal(t, tc.expected.Day(), result.Day())
-				assert.Equal(t, tc.expected.Hour(), result.Hour())
-				assert.Equal(t, tc.expected.Minute(), result.Minute())
-				assert.Equal(t, tc.expected.Second(), result.Second())
+				return
 			}
+			require.NoError(t, err)
+			assert.True(t, tc.expected.Equal(deadline), "expected %v, got %v", tc.expected, deadline)
+			assert.Equal(t, tc.hasTime, hasTime)
 		})
 	}
+
+	t.Run("day and month without a year", func(t *testing.T) {
+		deadline, hasTime, err := ParseDeadline("15.07 в 15:30", time.Now(), "")
+		require.NoError(t, err)
+		assert.True(t, hasTime)
+		assert.Equal(t, time.July, deadline.Month())
+		assert.Equal(t, 15, deadline.Day())
+		assert.Equal(t, 15, deadline.Hour())
+		assert.Equal(t, 30, deadline.Minute())
+	})
+
+	t.Run("date in words with time", func(t *testing.T) {
+		now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.Local)
+
+		deadline, hasTime, err := ParseDeadline("завтра в 9:30", now, "")
+		require.NoError(t, err)
+		assert.True(t, hasTime)
+		assert.Equal(t, time.Date(2025, 7, 17, 9, 30, 0, 0, time.Local), deadline)
+
+		deadline, hasTime, err = ParseDeadline("через 3 дня", now, "")
+		require.NoError(t, err)
+		assert.False(t, hasTime)
+		assert.Equal(t, time.Date(2025, 7, 19, 23, 59, 59, 0, time.Local), deadline)
+
+		var ambiguous *AmbiguousDateError
+		_, _, err = ParseDeadline("в среду в 18:00", now, "")
+		assert.ErrorAs(t, err, &ambiguous)
+	})
+
+	t.Run("ambiguous date with time", func(t *testing.T) {
+		now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.Local)
+
+		var ambiguous *AmbiguousDateError
+		_, _, err := ParseDeadline("03/04/2025 15:30", now, "")
+		require.ErrorAs(t, err, &ambiguous)
+		assert.True(t, ambiguous.HasTime)
+		assert.Equal(t, []time.Time{
+			time.Date(2025, 4, 3, 15, 30, 0, 0, time.Local),
+			time.Date(2025, 3, 4, 15, 30, 0, 0, time.Local),
+		}, ambiguous.Options)
+		assert.Contains(t, err.Error(), "03.04.2025 15:30")
+
+		deadline, hasTime, err := ParseDeadline("03/04/2025 15:30", now, models.DateOrderMDY)
+		require.NoError(t, err)
+		assert.True(t, hasTime)
+		assert.Equal(t, time.Date(2025, 3, 4, 15, 30, 0, 0, time.Local), deadline)
+	})
+
+	t.Run("in the user's time zone", func(t *testing.T) {
+		tokyo, err := time.LoadLocation("Asia/Tokyo")
+		require.NoError(t, err)
+		now := time.Date(2025, 7, 20, 12, 0, 0, 0, tokyo)
+
+		deadline, _, err := ParseDeadline("2025-07-15", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2025, 7, 15, 14, 59, 59, 0, time.UTC), deadline.UTC())
+
+		deadline, _, err = ParseDeadline("15.07 в 9:00", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 7, 15, 0, 0, 0, 0, time.UTC), deadline.UTC())
+
+		// Explicit zones are kept and shown in the user's zone
+		deadline, _, err = ParseDeadline("2025-07-15T12:00:00Z", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, 21, deadline.Hour())
+		assert.Equal(t, tokyo, deadline.Location())
+	})
+}
+
+func TestSetField(t *testing.T) {
+	assert.Equal(t, "/add Report срок: 2025-04-03", SetField("/add Report срок: 03/04/2025", "срок", "2025-04-03"))
+	assert.Equal(t, "/edit 2 New text статус: done срок: 2025-03-04T15:30",
+		SetField("/edit 2 New text срок: 03/04 в 15:30 статус: done", "срок", "2025-03-04T15:30"))
+	assert.Equal(t, "/add Report срок: 2025-04-03", SetField("/add Report", "срок", "2025-04-03"))
 }
 
 func TestParseTaskID(t *testing.T) {
@@ -171,6 +534,169 @@ func TestParseTaskID(t *testing.T) {
 	})
 }
 
+func TestParseTaskIDs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []string
+		expected []int
+		errMsg   string
+	}{
+		{
+			name:     "single ID",
+			input:    []string{"3"},
+			expected: []int{3},
+		},
+		{
+			name:     "multiple IDs",
+			input:    []string{"3", "5", "7"},
+			expected: []int{3, 5, 7},
+		},
+		{
+			name:     "range",
+			input:    []string{"3-6"},
+			expected: []int{3, 4, 5, 6},
+		},
+		{
+			name:     "IDs and ranges with duplicates",
+			input:    []string{"5", "3-6", "1"},
+			expected: []int{5, 3, 4, 6, 1},
+		},
+		{
+			name:   "no IDs",
+			input:  []string{},
+			errMsg: "no task IDs",
+		},
+		{
+			name:   "invalid ID",
+			input:  []string{"3", "abc"},
+			errMsg: "invalid task ID format",
+		},
+		{
+			name:   "negative ID",
+			input:  []string{"-1"},
+			errMsg: "must be positive",
+		},
+		{
+			name:   "reversed range",
+			input:  []string{"9-3"},
+			errMsg: "greater than end",
+		},
+		{
+			name:   "too large range",
+			input:  []string{"1-1000"},
+			errMsg: "too large",
+		},
+		{
+			name:     "ranges up to the total limit",
+			input:    []string{"1-100", "50-60"},
+			expected: sequence(1, 100),
+		},
+		{
+			name:   "too many IDs in ranges",
+			input:  []string{"1-100", "201-300"},
+			errMsg: "too many task IDs (maximum 100 per command)",
+		},
+		{
+			name:   "too many single IDs",
+			input:  append(stringSequence(1, 100), "101"),
+			errMsg: "too many task IDs",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ParseTaskIDs(tc.input)
+			if tc.errMsg != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errMsg)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
+
+// sequence returns the numbers from start to end
+func sequence(start, end int) []int {
+	var numbers []int
+	for n := start; n <= end; n++ {
+		numbers = append(numbers, n)
+	}
+	return numbers
+}
+
+// stringSequence returns the numbers from start to end as strings
+func stringSequence(start, end int) []string {
+	var numbers []string
+	for _, n := range sequence(start, end) {
+		numbers = append(numbers, strconv.Itoa(n))
+	}
+	return numbers
+}
+
+func TestExtractTaskID(t *testing.T) {
+	testCases := []struct {
+		name     string
+		text     string
+		expected int
+		found    bool
+	}{
+		{
+			name:     "add confirmation",
+			text:     "✅ Задача добавлена!\n\n📝 ID: 12\n📄 Описание: Buy groceries",
+			expected: 12,
+			found:    true,
+		},
+		{
+			name:     "reminder",
+			text:     "🔔 Напоминание: до срока осталось 1 ч\n\n📝 Buy groceries (ID: 5)\n⏰ Срок: 15.07.2025",
+			expected: 5,
+			found:    true,
+		},
+		{
+			name:     "description mentioning another ID",
+			text:     "⚠️ Срок задачи истек\n\n📝 Reply about (ID: 7) (ID: 5)\n⏰ Срок: 15.07.2025",
+			expected: 5,
+			found:    true,
+		},
+		{
+			name:     "same task mentioned twice",
+			text:     "📝 ID: 5\n📝 Buy groceries (ID: 5)",
+			expected: 5,
+			found:    true,
+		},
+		{
+			name:  "several tasks",
+			text:  "📝 First (ID: 1)\n📝 Second (ID: 2)",
+			found: false,
+		},
+		{
+			name:  "list item is not a task message",
+			text:  "📋 Активные задачи: 1\n\n⬜ 1. First (ID: 1)",
+			found: false,
+		},
+		{
+			name:  "ID outside a task line",
+			text:  "🗑 Задача 3 перемещена в корзину\nID: 3",
+			found: false,
+		},
+		{
+			name:  "no task reference",
+			text:  "Используйте /help",
+			found: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, found := ExtractTaskID(tc.text)
+			assert.Equal(t, tc.found, found)
+			assert.Equal(t, tc.expected, id)
+		})
+	}
+}
+
 func TestValidateDescription(t *testing.T) {
 	t.Run("valid description", func(t *testing.T) {
 		err := ValidateDescription("Buy groceries")
@@ -239,6 +765,20 @@ func TestFormatTaskItem(t *testing.T) {
 		result := FormatTaskItem(task, 2)
 		assert.Contains(t, result, "📝 2. Complete project (ID: 2)")
 		assert.Contains(t, result, "⏰ Срок: 15.07.2025")
+		assert.NotContains(t, result, "23:59")
+	})
+
+	t.Run("active task with deadline time", func(t *testing.T) {
+		task := TaskInfo{
+			ID:          6,
+			Description: "Team call",
+			Status:      "active",
+			Deadline:    time.Date(2025, 7, 15, 15, 30, 0, 0, time.Local),
+			HasDeadline: true,
+			HasTime:     true,
+		}
+		result := FormatTaskItem(task, 6)
+		assert.Contains(t, result, "⏰ Срок: 15.07.2025 15:30")
 	})
 
 	t.Run("overdue task", func(t *testing.T) {
@@ -276,6 +816,11 @@ func TestFormatTaskItem(t *testing.T) {
 		}
 		result := FormatTaskItem(task, 5)
 		assert.Contains(t, result, "⏸️ 5. Postponed task (ID: 5)")
+		assert.NotContains(t, result, "Отложено до")
+
+		task.PostponedUntil = time.Date(2025, 7, 16, 9, 0, 0, 0, time.Local)
+		result = FormatTaskItem(task, 5)
+		assert.Contains(t, result, "⏸️ Отложено до: 16.07.2025 09:00")
 	})
 }
 
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// priorityMarkRegex matches a "!1".."!4" priority mark between words
+var priorityMarkRegex = regexp.MustCompile(`(?:^|\s)!(\d+)(?:\s|$)`)
+
+// priorityWords maps priority names to priorities
+var priorityWords = map[string]int{
+	"срочный": models.PriorityUrgent, "срочно": models.PriorityUrgent, "критический": models.PriorityUrgent, "urgent": models.PriorityUrgent,
+	"высокий": models.PriorityHigh, "важный": models.PriorityHigh, "важно": models.PriorityHigh, "high": models.PriorityHigh,
+	"средний": models.PriorityMedium, "обычный": models.PriorityMedium, "medium": models.PriorityMedium,
+	"низкий": models.PriorityLow, "неважный": models.PriorityLow, "low": models.PriorityLow,
+	"-": models.PriorityNone, "нет": models.PriorityNone, "none": models.PriorityNone,
+}
+
+// priorityNames holds priority names for display, indexed by priority
+var priorityNames = [...]string{"", "срочный", "высокий", "средний", "низкий"}
+
+// priorityMarkers holds the markers shown before task descriptions, indexed by priority
+var priorityMarkers = [...]string{"", "🟥", "🟧", "🟨", "🟦"}
+
+// errInvalidPriority lists the accepted priority forms
+var errInvalidPriority = errors.New("invalid priority. Use !1 (срочный), !2 (высокий), !3 (средний) or !4 (низкий)")
+
+// ParsePriority parses a priority: "1".."4", "!1".."!4" or a name such as "высокий".
+// "-", "нет" and "none" mean no priority.
+func ParsePriority(text string) (int, error) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	if priority, ok := priorityWords[text]; ok {
+		return priority, nil
+	}
+
+	priority, err := strconv.Atoi(strings.TrimPrefix(text, "!"))
+	if err != nil || priority == models.PriorityNone || !models.IsValidPriority(priority) {
+		return 0, errInvalidPriority
+	}
+	return priority, nil
+}
+
+// cutPriority removes the priority from text: the "приоритет:" field or a "!1".."!4" mark
+func cutPriority(text string) (priority int, rest string, found bool, err error) {
+	if value, rest, found := cutField(text, "приоритет"); found {
+		priority, err := ParsePriority(value)
+		return priority, rest, true, err
+	}
+
+	matches := priorityMarkRegex.FindStringSubmatchIndex(text)
+	if matches == nil {
+		return 0, text, false, nil
+	}
+
+	priority, err = ParsePriority(text[matches[2]:matches[3]])
+	return priority, text[:matches[0]] + " " + text[matches[1]:], true, err
+}
+
+// FormatPriority describes a priority for display: "🟧 высокий (!2)"
+func FormatPriority(priority int) string {
+	if priority == models.PriorityNone || !models.IsValidPriority(priority) {
+		return "нет"
+	}
+	return fmt.Sprintf("%s %s (!%d)", priorityMarkers[priority], priorityNames[priority], priority)
+}
+
+// priorityMarker returns the marker shown before the description of a task with the priority
+func priorityMarker(priority int) string {
+	if priority == models.PriorityNone || !models.IsValidPriority(priority) {
+		return ""
+	}
+	return priorityMarkers[priority] + " "
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePriority(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int
+		hasError bool
+	}{
+		{input: "1", expected: models.PriorityUrgent},
+		{input: "!2", expected: models.PriorityHigh},
+		{input: "Высокий", expected: models.PriorityHigh},
+		{input: "средний", expected: models.PriorityMedium},
+		{input: "low", expected: models.PriorityLow},
+		{input: "срочно", expected: models.PriorityUrgent},
+		{input: "-", expected: models.PriorityNone},
+		{input: "нет", expected: models.PriorityNone},
+		{input: "0", hasError: true},
+		{input: "!5", hasError: true},
+		{input: "очень", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			priority, err := ParsePriority(tc.input)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, priority)
+		})
+	}
+}
+
+func TestParseAddCommandPriority(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.Local)
+
+	t.Run("mark", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Report !1 срок: завтра", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Report", input.Description)
+		assert.Equal(t, models.PriorityUrgent, input.Priority)
+		assert.True(t, input.HasDeadline)
+	})
+
+	t.Run("mark after the deadline", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Report срок: завтра !3", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, models.PriorityMedium, input.Priority)
+		assert.Equal(t, 16, input.Deadline.Day())
+	})
+
+	t.Run("field", func(t *testing.T) {
+		input, err := ParseAddCommand(`/add "Report" приоритет: высокий срок: 20.07`, now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Report", input.Description)
+		assert.Equal(t, models.PriorityHigh, input.Priority)
+		assert.True(t, input.HasDeadline)
+	})
+
+	t.Run("no priority", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Say hi!2 times", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Say hi!2 times", input.Description)
+		assert.Equal(t, models.PriorityNone, input.Priority)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseAddCommand("/add Report !7", now, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestParseEditCommandPriority(t *testing.T) {
+	input, err := ParseEditCommand("/edit 2 !4", time.Now(), "")
+	require.NoError(t, err)
+	assert.True(t, input.HasPriority)
+	assert.Equal(t, models.PriorityLow, input.Priority)
+	assert.Empty(t, input.Description)
+
+	input, err = ParseEditCommand("/edit 2 приоритет: -", time.Now(), "")
+	require.NoError(t, err)
+	assert.True(t, input.HasPriority)
+	assert.Equal(t, models.PriorityNone, input.Priority)
+
+	input, err = ParseEditCommand("/edit 2 New text", time.Now(), "")
+	require.NoError(t, err)
+	assert.False(t, input.HasPriority)
+}
+
+func TestFormatPriority(t *testing.T) {
+	assert.Equal(t, "🟧 высокий (!2)", FormatPriority(models.PriorityHigh))
+	assert.Equal(t, "нет", FormatPriority(models.PriorityNone))
+
+	item := FormatTaskItem(TaskInfo{ID: 7, Description: "Report", Status: "active", Priority: models.PriorityUrgent}, 1)
+	assert.Contains(t, item, "📝 1. 🟥 Report (ID: 7)")
+}
@@ -0,0 +1,243 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+var (
+	// everyRegex matches "каждые 3 дня", "каждые 2 недели" and "every 2 weeks"
+	everyRegex = regexp.MustCompile(`^(?:каждые|каждый|каждую|каждое|every)\s+(\S+)\s+(\S+)$`)
+	// everyWeeksOnRegex matches "каждые 2 недели по понедельникам"
+	everyWeeksOnRegex = regexp.MustCompile(`^(?:каждые|every)\s+(\S+)\s+(?:недели|недель|weeks)\s+(?:по|во|в|on)\s+(.+)$`)
+	// weekdaysRegex matches "каждый понедельник", "по пн и пт" and "every monday"
+	weekdaysRegex = regexp.MustCompile(`^(?:каждый|каждую|каждое|по|во|в|every|on)\s+(.+)$`)
+	// monthDayPhraseRegex matches the day of the month: "15 числа", "1-го числа", "последний день месяца"
+	monthDayPhraseRegex = regexp.MustCompile(`(?:^|\s)(?:в\s+)?(?:(\d{1,2})(?:-?(?:е|го|ое))?\s+числ[оа]|(последн\S*)\s+(?:день|число)(?:\s+месяца)?)`)
+	// everyMonthsRegex matches "каждые 3 месяца"
+	everyMonthsRegex = regexp.MustCompile(`^(?:каждые|every)\s+(\S+)\s+(?:месяца|месяцев|months)$`)
+)
+
+// ParseRecurrence parses how a task repeats, in Russian or as an RFC 5545 RRULE:
+// "каждый день", "через день", "каждые 3 дня", "каждый понедельник", "по пн и пт",
+// "по будням", "каждые 2 недели по пятницам", "каждый месяц", "1 числа каждого месяца",
+// "в последний день месяца", "FREQ=WEEKLY;BYDAY=MO,FR".
+// Rules without a weekday or a day of the month repeat on those of the first occurrence.
+func ParseRecurrence(text string) (*models.Recurrence, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, errors.New("empty recurrence")
+	}
+
+	upper := strings.ToUpper(text)
+	if strings.HasPrefix(upper, "FREQ=") || strings.HasPrefix(upper, "RRULE:") {
+		return models.ParseRRule(text)
+	}
+
+	rule, ok := parseRecurrencePhrase(normalizeDatePhrase(text))
+	if !ok {
+		return nil, errors.New("invalid recurrence. Examples: каждый день, каждые 3 дня, каждый понедельник, " +
+			"по будням, 1 числа каждого месяца, FREQ=WEEKLY;BYDAY=MO")
+	}
+
+	if err := rule.Validate(); err != nil {
+		return nil, err
+	}
+
+	// Round-trip through the RRULE to get the canonical form
+	return models.ParseRRule(rule.String())
+}
+
+// parseRecurrencePhrase recognizes a normalized recurrence phrase
+func parseRecurrencePhrase(phrase string) (*models.Recurrence, bool) {
+	switch phrase {
+	case "каждый день", "ежедневно", "every day", "daily":
+		return &models.Recurrence{Freq: models.FreqDaily}, true
+	case "через день", "every other day":
+		return &models.Recurrence{Freq: models.FreqDaily, Interval: 2}, true
+	case "каждую неделю", "еженедельно", "every week", "weekly":
+		return &models.Recurrence{Freq: models.FreqWeekly}, true
+	case "каждый месяц", "ежемесячно", "every month", "monthly":
+		return &models.Recurrence{Freq: models.FreqMonthly}, true
+	}
+
+	if matches := monthDayPhraseRegex.FindStringSubmatchIndex(phrase); matches != nil {
+		return parseMonthlyPhrase(phrase, matches)
+	}
+
+	if matches := everyWeeksOnRegex.FindStringSubmatch(phrase); matches != nil {
+		interval, ok := parseRecurrenceCount(matches[1])
+		weekdays, weekdaysOK := parseWeekdayList(matches[2])
+		if !ok || !weekdaysOK {
+			return nil, false
+		}
+		return &models.Recurrence{Freq: models.FreqWeekly, Interval: interval, Weekdays: weekdays}, true
+	}
+
+	if matches := everyRegex.FindStringSubmatch(phrase); matches != nil {
+		if interval, ok := parseRecurrenceCount(matches[1]); ok {
+			switch matches[2] {
+			case "день", "дня", "дней", "days":
+				return &models.Recurrence{Freq: models.FreqDaily, Interval: interval}, true
+			case "неделю", "недели", "недель", "weeks":
+				return &models.Recurrence{Freq: models.FreqWeekly, Interval: interval}, true
+			case "месяц", "месяца", "месяцев", "months":
+				return &models.Recurrence{Freq: models.FreqMonthly, Interval: interval}, true
+			}
+		}
+	}
+
+	if matches := weekdaysRegex.FindStringSubmatch(phrase); matches != nil {
+		if weekdays, ok := parseWeekdayList(matches[1]); ok {
+			return &models.Recurrence{Freq: models.FreqWeekly, Weekdays: weekdays}, true
+		}
+	}
+
+	return nil, false
+}
+
+// parseMonthlyPhrase parses a phrase with the day of the month, such as "15 числа каждого месяца"
+// or "каждые 3 месяца 1 числа". matches are the submatch indexes of monthDayPhraseRegex.
+func parseMonthlyPhrase(phrase string, matches []int) (*models.Recurrence, bool) {
+	rule := &models.Recurrence{Freq: models.FreqMonthly, MonthDay: models.LastDayOfMonth}
+	if matches[2] >= 0 {
+		rule.MonthDay, _ = strconv.Atoi(phrase[matches[2]:matches[3]])
+	}
+
+	rest := strings.TrimSpace(phrase[:matches[0]] + " " + phrase[matches[1]:])
+	switch rest {
+	case "", "каждое", "каждого месяца", "каждый месяц", "ежемесячно":
+		return rule, true
+	}
+
+	if intervalMatches := everyMonthsRegex.FindStringSubmatch(rest); intervalMatches != nil {
+		interval, ok := parseRecurrenceCount(intervalMatches[1])
+		rule.Interval = interval
+		return rule, ok
+	}
+
+	return nil, false
+}
+
+// parseRecurrenceCount parses the number of days, weeks or months, as digits or a word
+func parseRecurrenceCount(text string) (int, bool) {
+	if n, ok := numberWords[text]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(text)
+	return n, err == nil && n > 0
+}
+
+// parseWeekdayList parses "понедельникам и пятницам", "пн ср пт", "будням" or "выходным"
+func parseWeekdayList(text string) ([]time.Weekday, bool) {
+	var weekdays []time.Weekday
+	for _, word := range strings.Fields(text) {
+		switch word {
+		case "и", "and":
+			continue
+		case "будни", "будням", "weekdays":
+			weekdays = append(weekdays, time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday)
+		case "выходные", "выходным", "weekends":
+			weekdays = append(weekdays, time.Saturday, time.Sunday)
+		default:
+			weekday, ok := parseWeekdayName(word)
+			if !ok {
+				return nil, false
+			}
+			weekdays = append(weekdays, weekday)
+		}
+	}
+	return weekdays, len(weekdays) > 0
+}
+
+// weekdayAccusative holds weekday names as used after "каждый", indexed by time.Weekday
+var weekdayAccusative = [...]string{
+	"каждое воскресенье", "каждый понедельник", "каждый вторник", "каждую среду",
+	"каждый четверг", "каждую пятницу", "каждую субботу",
+}
+
+// FormatRecurrence describes a recurrence in Russian: "каждый понедельник", "каждые 3 дня",
+// "по пн, ср, пт", "каждый месяц 1-го числа". Unparseable rules are shown as is.
+func FormatRecurrence(rule string) string {
+	recurrence, err := models.ParseRRule(rule)
+	if err != nil {
+		return rule
+	}
+
+	interval := recurrence.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	var text string
+	switch recurrence.Freq {
+	case models.FreqDaily:
+		text = "каждый день"
+		if interval > 1 {
+			text = fmt.Sprintf("каждые %d %s", interval, russianPlural(interval, "день", "дня", "дней"))
+		}
+	case models.FreqWeekly:
+		text = formatWeeklyRecurrence(recurrence.Weekdays, interval)
+	case models.FreqMonthly:
+		text = "каждый месяц"
+		if interval > 1 {
+			text = fmt.Sprintf("каждые %d %s", interval, russianPlural(interval, "месяц", "месяца", "месяцев"))
+		}
+		switch {
+		case recurrence.MonthDay == models.LastDayOfMonth:
+			text += " в последний день"
+		case recurrence.MonthDay > 0:
+			text += fmt.Sprintf(" %d-го числа", recurrence.MonthDay)
+		}
+	}
+
+	if !recurrence.Until.IsZero() {
+		text += " до " + recurrence.Until.Format("02.01.2006")
+	}
+
+	return text
+}
+
+// formatWeeklyRecurrence describes a weekly recurrence
+func formatWeeklyRecurrence(weekdays []time.Weekday, interval int) string {
+	days := make([]string, len(weekdays))
+	for i, weekday := range weekdays {
+		days[i] = shortWeekdayNames[weekday]
+	}
+	list := strings.Join(days, ", ")
+
+	switch {
+	case interval > 1 && len(weekdays) > 0:
+		return fmt.Sprintf("каждые %d %s по %s", interval, russianPlural(interval, "неделю", "недели", "недель"), list)
+	case interval > 1:
+		return fmt.Sprintf("каждые %d %s", interval, russianPlural(interval, "неделю", "недели", "недель"))
+	case len(weekdays) == 0:
+		return "каждую неделю"
+	case len(weekdays) == 1:
+		return weekdayAccusative[weekdays[0]]
+	case list == "пн, вт, ср, чт, пт":
+		return "по будням"
+	case list == "сб, вс":
+		return "по выходным"
+	default:
+		return "по " + list
+	}
+}
+
+// russianPlural picks the form of a word for the number: 1 день, 2 дня, 5 дней
+func russianPlural(n int, one, few, many string) string {
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		return one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		return few
+	default:
+		return many
+	}
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+		hasError bool
+	}{
+		{input: "каждый день", expected: "FREQ=DAILY"},
+		{input: "Ежедневно", expected: "FREQ=DAILY"},
+		{input: "через день", expected: "FREQ=DAILY;INTERVAL=2"},
+		{input: "каждые 3 дня", expected: "FREQ=DAILY;INTERVAL=3"},
+		{input: "каждые два дня", expected: "FREQ=DAILY;INTERVAL=2"},
+		{input: "every 10 days", expected: "FREQ=DAILY;INTERVAL=10"},
+		{input: "каждую неделю", expected: "FREQ=WEEKLY"},
+		{input: "каждый понедельник", expected: "FREQ=WEEKLY;BYDAY=MO"},
+		{input: "каждую пятницу", expected: "FREQ=WEEKLY;BYDAY=FR"},
+		{input: "по понедельникам и пятницам", expected: "FREQ=WEEKLY;BYDAY=MO,FR"},
+		{input: "по пт, пн, ср", expected: "FREQ=WEEKLY;BYDAY=MO,WE,FR"},
+		{input: "по будням", expected: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"},
+		{input: "по выходным", expected: "FREQ=WEEKLY;BYDAY=SA,SU"},
+		{input: "каждые 2 недели", expected: "FREQ=WEEKLY;INTERVAL=2"},
+		{input: "каждые 2 недели по средам", expected: "FREQ=WEEKLY;INTERVAL=2;BYDAY=WE"},
+		{input: "every monday", expected: "FREQ=WEEKLY;BYDAY=MO"},
+		{input: "каждый месяц", expected: "FREQ=MONTHLY"},
+		{input: "1 числа каждого месяца", expected: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{input: "каждое 15-е число", expected: "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{input: "каждый месяц 5-го числа", expected: "FREQ=MONTHLY;BYMONTHDAY=5"},
+		{input: "в последний день месяца", expected: "FREQ=MONTHLY;BYMONTHDAY=-1"},
+		{input: "каждые 3 месяца", expected: "FREQ=MONTHLY;INTERVAL=3"},
+		{input: "каждые 3 месяца 10 числа", expected: "FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=10"},
+		{input: "FREQ=WEEKLY;BYDAY=TU,TH", expected: "FREQ=WEEKLY;BYDAY=TU,TH"},
+		{input: "RRULE:FREQ=MONTHLY;BYMONTHDAY=1", expected: "FREQ=MONTHLY;BYMONTHDAY=1"},
+		{input: "32 числа", hasError: true},
+		{input: "FREQ=YEARLY", hasError: true},
+		{input: "каждый попугай", hasError: true},
+		{input: "иногда", hasError: true},
+		{input: "", hasError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			rule, err := ParseRecurrence(tc.input)
+			if tc.hasError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, rule.String())
+		})
+	}
+}
+
+func TestFormatRecurrence(t *testing.T) {
+	testCases := map[string]string{
+		"FREQ=DAILY":                           "каждый день",
+		"FREQ=DAILY;INTERVAL=2":                "каждые 2 дня",
+		"FREQ=DAILY;INTERVAL=5":                "каждые 5 дней",
+		"FREQ=WEEKLY;BYDAY=MO":                 "каждый понедельник",
+		"FREQ=WEEKLY;BYDAY=WE":                 "каждую среду",
+		"FREQ=WEEKLY;BYDAY=MO,WE,FR":           "по пн, ср, пт",
+		"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR":     "по будням",
+		"FREQ=WEEKLY;INTERVAL=2;BYDAY=FR":      "каждые 2 недели по пт",
+		"FREQ=MONTHLY;BYMONTHDAY=1":            "каждый месяц 1-го числа",
+		"FREQ=MONTHLY;BYMONTHDAY=-1":           "каждый месяц в последний день",
+		"FREQ=MONTHLY;INTERVAL=3;BYMONTHDAY=1": "каждые 3 месяца 1-го числа",
+		"FREQ=DAILY;UNTIL=20251231":            "каждый день до 31.12.2025",
+		"FREQ=HOURLY":                          "FREQ=HOURLY",
+	}
+
+	for rule, expected := range testCases {
+		assert.Equal(t, expected, FormatRecurrence(rule), rule)
+	}
+}
+
+func TestParseAddCommand_Recurrence(t *testing.T) {
+	// Wednesday
+	now := time.Date(2025, 7, 16, 10, 0, 0, 0, time.Local)
+
+	t.Run("starts on the first matching day", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Weekly report повтор: каждый понедельник", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Weekly report", input.Description)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO", input.Recurrence.String())
+		assert.True(t, input.HasDeadline)
+		assert.Equal(t, time.Date(2025, 7, 21, 23, 59, 59, 0, time.Local), input.Deadline)
+	})
+
+	t.Run("open weekday is taken from the deadline", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Call mom повтор: каждую неделю срок: в пятницу в 19:00", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Call mom", input.Description)
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=FR", input.Recurrence.String())
+		assert.Equal(t, time.Date(2025, 7, 18, 19, 0, 0, 0, time.Local), input.Deadline)
+		assert.True(t, input.HasTime)
+	})
+
+	t.Run("monthly from today", func(t *testing.T) {
+		input, err := ParseAddCommand("/add Pay rent повтор: каждый месяц", now, "")
+		require.NoError(t, err)
+		assert.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=16", input.Recurrence.String())
+		assert.Equal(t, time.Date(2025, 7, 16, 23, 59, 59, 0, time.Local), input.Deadline)
+	})
+
+	t.Run("invalid recurrence", func(t *testing.T) {
+		_, err := ParseAddCommand("/add Pay rent повтор: иногда", now, "")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+
+	"telegram-bot-assistente/internal/models"
+)
+
+// tagRegex matches a "#tag" word; tags start with a letter, so "#123" stays in the text
+var tagRegex = regexp.MustCompile(`(?:^|\s)#(\p{L}[\p{L}\p{N}_-]*)`)
+
+// cutTags removes "#tag" words from text and returns them normalized (see models.NormalizeTags)
+func cutTags(text string) (tags []string, rest string, err error) {
+	matches := tagRegex.FindAllStringSubmatchIndex(text, -1)
+	if matches == nil {
+		return nil, text, nil
+	}
+
+	var builder strings.Builder
+	last := 0
+	for _, match := range matches {
+		tags = append(tags, text[match[2]:match[3]])
+		builder.WriteString(text[last:match[0]])
+		last = match[1]
+	}
+	builder.WriteString(text[last:])
+
+	tags, err = models.NormalizeTags(tags)
+	if err != nil {
+		return nil, text, err
+	}
+
+	return tags, builder.String(), nil
+}
+
+// FormatTags formats tags for display: "#work #home"
+func FormatTags(tags []string) string {
+	formatted := make([]string, len(tags))
+	for i, tag := range tags {
+		formatted[i] = "#" + tag
+	}
+	return strings.Join(formatted, " ")
+}
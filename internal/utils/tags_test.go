@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAddCommandTags(t *testing.T) {
+	now := time.Date(2025, 7, 15, 12, 0, 0, 0, time.Local)
+
+	input, err := ParseAddCommand("/add Отчет #Работа по проекту #q3 !2 срок: завтра", now, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Отчет по проекту", input.Description)
+	assert.Equal(t, []string{"q3", "работа"}, input.Tags)
+	assert.True(t, input.HasDeadline)
+
+	// "#" before a number is not a tag
+	input, err = ParseAddCommand("/add Закрыть тикет #123", now, "")
+	require.NoError(t, err)
+	assert.Equal(t, "Закрыть тикет #123", input.Description)
+	assert.Empty(t, input.Tags)
+
+	_, err = ParseAddCommand("/add #работа", now, "")
+	assert.Error(t, err)
+}
+
+func TestParseEditCommandTags(t *testing.T) {
+	input, err := ParseEditCommand("/edit 2 #дом #сад", time.Now(), "")
+	require.NoError(t, err)
+	assert.True(t, input.HasTags)
+	assert.Equal(t, []string{"дом", "сад"}, input.Tags)
+	assert.Empty(t, input.Description)
+
+	input, err = ParseEditCommand("/edit 2 теги: -", time.Now(), "")
+	require.NoError(t, err)
+	assert.True(t, input.HasTags)
+	assert.Empty(t, input.Tags)
+
+	input, err = ParseEditCommand("/edit 2 Новый текст", time.Now(), "")
+	require.NoError(t, err)
+	assert.False(t, input.HasTags)
+}
+
+func TestFormatTags(t *testing.T) {
+	assert.Equal(t, "#дом #работа", FormatTags([]string{"дом", "работа"}))
+
+	item := FormatTaskItem(TaskInfo{ID: 7, Description: "Report", Status: "active", Tags: []string{"work"}}, 1)
+	assert.Contains(t, item, "🏷 #work")
+}